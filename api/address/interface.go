@@ -1,8 +1,14 @@
 package address
 
-import "github.com/numbatx/numbat-proxy/data"
+import (
+	"context"
+
+	"github.com/numbatx/numbat-proxy/data"
+)
 
 // FacadeHandler interface defines methods that can be used from `numbatProxyFacade` context variable
 type FacadeHandler interface {
-	GetAccount(address string) (*data.Account, error)
+	GetAccount(ctx context.Context, address string) (*data.Account, error)
+	SubscribeToAccounts(addresses []string) (<-chan *data.AccountChange, func(), error)
+	ReserveNonce(ctx context.Context, address string) (*data.NonceReservation, error)
 }
@@ -1,18 +1,34 @@
 package address
 
 import (
+	"io"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/numbatx/numbat-proxy/api/errors"
+	"github.com/numbatx/numbat-proxy/api/middleware"
 	"github.com/numbatx/numbat-proxy/data"
 )
 
+// streamKeepAliveInterval is the interval at which an empty event is sent on an idle account changes stream
+// so the connection is not closed by the intermediary proxies
+const streamKeepAliveInterval = 15 * time.Second
+
+// cacheStatusHeader is the response header telling whether the account was served from the proxy's cache
+const cacheStatusHeader = "X-Proxy-Cache"
+
+const cacheHit = "HIT"
+const cacheMiss = "MISS"
+
 // Routes defines address related routes
 func Routes(router *gin.RouterGroup) {
 	router.GET("/:address", GetAccount)
 	router.GET("/:address/balance", GetBalance)
 	router.GET("/:address/nonce", GetNonce)
+	router.GET("/:address/next-nonce", ReserveNonce)
+	router.GET("/:address/subscribe", SubscribeToAccounts)
 }
 
 func getAccount(c *gin.Context) (*data.Account, int, error) {
@@ -22,10 +38,16 @@ func getAccount(c *gin.Context) (*data.Account, int, error) {
 	}
 
 	addr := c.Param("address")
-	acc, err := epf.GetAccount(addr)
+	acc, err := epf.GetAccount(c.Request.Context(), addr)
 	if err != nil {
-		return nil, http.StatusInternalServerError, err
+		return nil, errors.ResponseStatusCode(err), err
+	}
+
+	cacheStatus := cacheMiss
+	if acc.FromCache {
+		cacheStatus = cacheHit
 	}
+	c.Header(cacheStatusHeader, cacheStatus)
 
 	return acc, http.StatusOK, nil
 }
@@ -63,3 +85,70 @@ func GetNonce(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"nonce": account.Nonce})
 }
+
+// ReserveNonce returns the next nonce the address should use for a transaction sent through the proxy.
+// The nonce is reserved, so concurrent requests for the same address get different nonces. Only the API
+// keys allowed to send transactions from the address can reserve its nonces
+func ReserveNonce(c *gin.Context) {
+	epf, ok := c.MustGet("numbatProxyFacade").(FacadeHandler)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errors.ErrInvalidAppContext.Error()})
+		return
+	}
+
+	addr := c.Param("address")
+	policy, isAuthenticated := middleware.GetApiKeyPolicy(c)
+	if !isAuthenticated {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": middleware.ErrMissingApiKey.Error()})
+		return
+	}
+	if !policy.IsSenderAllowed(addr) {
+		c.JSON(http.StatusForbidden, gin.H{"error": middleware.ErrSenderNotAllowed.Error()})
+		return
+	}
+
+	reservation, err := epf.ReserveNonce(c.Request.Context(), addr)
+	if err != nil {
+		c.JSON(errors.ResponseStatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"reservation": reservation})
+}
+
+// SubscribeToAccounts streams, as server-sent events, the nonce and balance changes of the comma separated
+// addresses provided in the path. The first event of each address holds its current state
+func SubscribeToAccounts(c *gin.Context) {
+	epf, ok := c.MustGet("numbatProxyFacade").(FacadeHandler)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errors.ErrInvalidAppContext.Error()})
+		return
+	}
+
+	addresses := strings.Split(c.Param("address"), ",")
+	changes, unsubscribe, err := epf.SubscribeToAccounts(addresses)
+	if err != nil {
+		c.JSON(errors.ResponseStatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+	defer unsubscribe()
+
+	keepAlive := time.NewTicker(streamKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case change, isOpen := <-changes:
+			if !isOpen {
+				return false
+			}
+			c.SSEvent("account", change)
+			return true
+		case <-keepAlive.C:
+			c.SSEvent("keep-alive", "")
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
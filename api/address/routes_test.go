@@ -1,6 +1,7 @@
 package address_test
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,14 +10,18 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/numbatx/numbat-proxy/api"
 	"github.com/numbatx/numbat-proxy/api/address"
 	apiErrors "github.com/numbatx/numbat-proxy/api/errors"
+	"github.com/numbatx/numbat-proxy/api/middleware"
 	"github.com/numbatx/numbat-proxy/api/mock"
+	"github.com/numbatx/numbat-proxy/config"
 	"github.com/numbatx/numbat-proxy/data"
+	"github.com/numbatx/numbat-proxy/process"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -122,7 +127,7 @@ func TestGetAccount_FailWhenFacadeGetAccountFails(t *testing.T) {
 
 	returnedError := "i am an error"
 	facade := mock.Facade{
-		GetAccountHandler: func(address string) (*data.Account, error) {
+		GetAccountHandler: func(ctx context.Context, address string) (*data.Account, error) {
 			return nil, errors.New(returnedError)
 		},
 	}
@@ -140,11 +145,52 @@ func TestGetAccount_FailWhenFacadeGetAccountFails(t *testing.T) {
 	assert.Equal(t, returnedError, accountResponse.Error)
 }
 
+func TestGetAccount_FacadeErrorsShouldMapToStatusCodes(t *testing.T) {
+	t.Parallel()
+
+	observerErr := func(statusCode int) error {
+		return &process.ObserverError{Address: "address", StatusCode: statusCode, Message: "message"}
+	}
+	testCases := []struct {
+		err                error
+		expectedStatusCode int
+	}{
+		{fmt.Errorf("%w: odd length", process.ErrInvalidAddress), http.StatusBadRequest},
+		{observerErr(http.StatusBadRequest), http.StatusBadRequest},
+		{observerErr(http.StatusNotFound), http.StatusNotFound},
+		{fmt.Errorf("%w: %w", process.ErrSendingRequest, observerErr(http.StatusInternalServerError)), http.StatusBadGateway},
+		{fmt.Errorf("%w: %w", process.ErrSendingRequest, errors.New("connection refused")), http.StatusBadGateway},
+		{process.ErrMissingObserver, http.StatusServiceUnavailable},
+		{fmt.Errorf("%w: %w", process.ErrNotSupportedByObservers, observerErr(http.StatusNotFound)), http.StatusNotImplemented},
+		{fmt.Errorf("%w: %w", process.ErrSendingRequest, context.DeadlineExceeded), http.StatusGatewayTimeout},
+	}
+
+	for _, tc := range testCases {
+		errToReturn := tc.err
+		facade := mock.Facade{
+			GetAccountHandler: func(ctx context.Context, address string) (*data.Account, error) {
+				return nil, errToReturn
+			},
+		}
+		ws := startNodeServer(&facade)
+
+		req, _ := http.NewRequest("GET", "/address/test", nil)
+		resp := httptest.NewRecorder()
+		ws.ServeHTTP(resp, req)
+
+		accountResponse := accountResponse{}
+		loadResponse(resp.Body, &accountResponse)
+
+		assert.Equal(t, tc.expectedStatusCode, resp.Code)
+		assert.Equal(t, tc.err.Error(), accountResponse.Error)
+	}
+}
+
 func TestGetAccount_ReturnsSuccessfully(t *testing.T) {
 	t.Parallel()
 
 	facade := mock.Facade{
-		GetAccountHandler: func(address string) (*data.Account, error) {
+		GetAccountHandler: func(ctx context.Context, address string) (*data.Account, error) {
 			return &data.Account{
 				Address: address,
 				Nonce:   1,
@@ -190,7 +236,7 @@ func TestGetBalance_ReturnsSuccessfully(t *testing.T) {
 	t.Parallel()
 
 	facade := mock.Facade{
-		GetAccountHandler: func(address string) (*data.Account, error) {
+		GetAccountHandler: func(ctx context.Context, address string) (*data.Account, error) {
 			return &data.Account{
 				Address: address,
 				Nonce:   1,
@@ -233,7 +279,7 @@ func TestGetNonce_ReturnsSuccessfully(t *testing.T) {
 	t.Parallel()
 
 	facade := mock.Facade{
-		GetAccountHandler: func(address string) (*data.Account, error) {
+		GetAccountHandler: func(ctx context.Context, address string) (*data.Account, error) {
 			return &data.Account{
 				Address: address,
 				Nonce:   1,
@@ -255,3 +301,196 @@ func TestGetNonce_ReturnsSuccessfully(t *testing.T) {
 	assert.Equal(t, uint64(1), nonceResponse.Nonce)
 	assert.Empty(t, nonceResponse.Error)
 }
+
+//------- SubscribeToAccounts
+
+func TestSubscribeToAccounts_TooManyAddressesShouldReturn400(t *testing.T) {
+	t.Parallel()
+
+	facade := mock.Facade{
+		SubscribeToAccountsHandler: func(addresses []string) (<-chan *data.AccountChange, func(), error) {
+			return nil, nil, process.ErrTooManyAddresses
+		},
+	}
+	ws := startNodeServer(&facade)
+
+	req, _ := http.NewRequest("GET", "/address/aa,bb/subscribe", nil)
+	resp := httptest.NewRecorder()
+	ws.ServeHTTP(resp, req)
+
+	response := GeneralResponse{}
+	loadResponse(resp.Body, &response)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, process.ErrTooManyAddresses.Error(), response.Error)
+}
+
+func TestSubscribeToAccounts_ShouldStreamChanges(t *testing.T) {
+	t.Parallel()
+
+	var receivedAddresses []string
+	isUnsubscribed := make(chan struct{})
+	facade := mock.Facade{
+		SubscribeToAccountsHandler: func(addresses []string) (<-chan *data.AccountChange, func(), error) {
+			receivedAddresses = addresses
+			changes := make(chan *data.AccountChange, 1)
+			changes <- &data.AccountChange{Address: "aa", Nonce: 2, Balance: "10", PreviousNonce: 1, PreviousBalance: "5"}
+			close(changes)
+
+			return changes, func() { close(isUnsubscribed) }, nil
+		},
+	}
+	server := httptest.NewServer(startNodeServer(&facade))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/address/aa,bb/subscribe")
+	assert.Nil(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	<-isUnsubscribed
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"aa", "bb"}, receivedAddresses)
+	assert.Contains(t, string(body), "event:account")
+	assert.Contains(t, string(body), `"previousBalance":"5"`)
+}
+
+func TestGetAccount_ShouldSetCacheHeader(t *testing.T) {
+	t.Parallel()
+
+	facade := mock.Facade{
+		GetAccountHandler: func(ctx context.Context, address string) (*data.Account, error) {
+			return &data.Account{Address: address, FromCache: address == "cached"}, nil
+		},
+	}
+	ws := startNodeServer(&facade)
+
+	req, _ := http.NewRequest("GET", "/address/cached", nil)
+	resp := httptest.NewRecorder()
+	ws.ServeHTTP(resp, req)
+	assert.Equal(t, "HIT", resp.Header().Get("X-Proxy-Cache"))
+
+	req, _ = http.NewRequest("GET", "/address/other/balance", nil)
+	resp = httptest.NewRecorder()
+	ws.ServeHTTP(resp, req)
+	assert.Equal(t, "MISS", resp.Header().Get("X-Proxy-Cache"))
+}
+
+//------- ReserveNonce
+
+func startAuthenticatedNodeServer(facade *mock.Facade) *gin.Engine {
+	codec, _ := process.NewAddressCodec(config.AddressesConfig{})
+	authenticator, _ := middleware.NewAuthenticator(config.AuthenticationConfig{
+		Header: "X-Api-Key",
+		Keys: []*config.ApiKeyConfig{
+			{Name: "team", Key: "key", AllowedSenders: []string{"aa"}},
+		},
+	}, codec)
+
+	ws := gin.New()
+	addressRoutes := ws.Group("/address")
+	addressRoutes.Use(authenticator.Handler(), api.WithNumbatProxyFacade(facade))
+	address.Routes(addressRoutes)
+
+	return ws
+}
+
+func reserveNonce(ws *gin.Engine, address string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/address/%s/next-nonce", address), nil)
+	req.Header.Set("X-Api-Key", "key")
+	resp := httptest.NewRecorder()
+	ws.ServeHTTP(resp, req)
+
+	return resp
+}
+
+func TestReserveNonce_ReturnsSuccessfully(t *testing.T) {
+	t.Parallel()
+
+	expiresAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	facade := mock.Facade{
+		ReserveNonceHandler: func(ctx context.Context, address string) (*data.NonceReservation, error) {
+			return &data.NonceReservation{Address: address, Nonce: 7, AccountNonce: 5, NumPending: 1, ExpiresAt: expiresAt}, nil
+		},
+	}
+	ws := startAuthenticatedNodeServer(&facade)
+
+	resp := reserveNonce(ws, "aa")
+
+	response := struct {
+		GeneralResponse
+		Reservation data.NonceReservation `json:"reservation"`
+	}{}
+	loadResponse(resp.Body, &response)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, data.NonceReservation{Address: "aa", Nonce: 7, AccountNonce: 5, NumPending: 1, ExpiresAt: expiresAt},
+		response.Reservation)
+}
+
+func TestReserveNonce_FacadeErrorsShouldMapToStatusCodes(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		err        error
+		statusCode int
+	}{
+		{err: process.ErrNonceAllocationDisabled, statusCode: http.StatusNotFound},
+		{err: process.ErrTooManyReservedNonces, statusCode: http.StatusTooManyRequests},
+		{err: process.ErrTooManyTrackedSenders, statusCode: http.StatusServiceUnavailable},
+		{err: process.ErrInvalidAddress, statusCode: http.StatusBadRequest},
+	}
+	for _, tc := range testCases {
+		errFacade := tc.err
+		facade := mock.Facade{
+			ReserveNonceHandler: func(ctx context.Context, address string) (*data.NonceReservation, error) {
+				return nil, errFacade
+			},
+		}
+		ws := startAuthenticatedNodeServer(&facade)
+
+		resp := reserveNonce(ws, "aa")
+
+		response := GeneralResponse{}
+		loadResponse(resp.Body, &response)
+
+		assert.Equal(t, tc.statusCode, resp.Code, tc.err.Error())
+		assert.Equal(t, tc.err.Error(), response.Error)
+	}
+}
+
+func TestReserveNonce_NotAuthenticatedShouldReturn401(t *testing.T) {
+	t.Parallel()
+
+	facade := mock.Facade{
+		ReserveNonceHandler: func(ctx context.Context, address string) (*data.NonceReservation, error) {
+			assert.Fail(t, "the nonce should not be reserved")
+			return nil, nil
+		},
+	}
+	ws := startNodeServer(&facade)
+
+	req, _ := http.NewRequest("GET", "/address/aa/next-nonce", nil)
+	resp := httptest.NewRecorder()
+	ws.ServeHTTP(resp, req)
+
+	response := GeneralResponse{}
+	loadResponse(resp.Body, &response)
+
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.Equal(t, middleware.ErrMissingApiKey.Error(), response.Error)
+}
+
+func TestReserveNonce_SenderNotAllowedForApiKeyShouldReturn403(t *testing.T) {
+	t.Parallel()
+
+	facade := mock.Facade{
+		ReserveNonceHandler: func(ctx context.Context, address string) (*data.NonceReservation, error) {
+			return &data.NonceReservation{Address: address}, nil
+		},
+	}
+	ws := startAuthenticatedNodeServer(&facade)
+
+	assert.Equal(t, http.StatusOK, reserveNonce(ws, "aa").Code)
+	assert.Equal(t, http.StatusForbidden, reserveNonce(ws, "cc").Code)
+}
@@ -1,44 +1,142 @@
 package api
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
 	"reflect"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
+	"github.com/numbatx/gn-numbat/core/logger"
 	"github.com/numbatx/numbat-proxy/api/address"
+	"github.com/numbatx/numbat-proxy/api/health"
+	"github.com/numbatx/numbat-proxy/api/middleware"
+	"github.com/numbatx/numbat-proxy/api/proxy"
 	"github.com/numbatx/numbat-proxy/api/transaction"
+	"github.com/numbatx/numbat-proxy/config"
+	"github.com/numbatx/numbat-proxy/metrics"
 	"gopkg.in/go-playground/validator.v8"
 )
 
+var log = logger.DefaultLogger()
+
+// readHeaderTimeout bounds the time a client has to send the request headers, so slow clients can not hold
+// connections open indefinitely
+const readHeaderTimeout = 10 * time.Second
+
 type validatorInput struct {
 	Name      string
 	Validator validator.Func
 }
 
-// Start will boot up the api and appropriate routes, handlers and validators
-func Start(numbatProxyFacade NumbatProxyHandler, port int) error {
-	ws := gin.Default()
+// Start will boot up the api and appropriate routes, handlers and validators. The provided middlewares
+// are applied, in order, to the address, transaction and proxy status routes and to the metrics registry,
+// exposed on /metrics, as the metrics name the observers. The health probes are not subject to them. The requests are served over TLS when it
+// is enabled. Each request gets an id and, if an access log writer is provided, a JSON access log line, which
+// then replaces gin's text log. Start returns once the port is bound, while the requests are served in the
+// background until the returned server is shut down
+func Start(
+	numbatProxyFacade NumbatProxyHandler,
+	port int,
+	tlsCfg config.ServerTlsConfig,
+	registry *metrics.Registry,
+	accessLogWriter io.Writer,
+	middlewares ...gin.HandlerFunc,
+) (*http.Server, error) {
+
+	tlsConfig, err := newServerTlsConfig(tlsCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	requestMetrics, err := middleware.NewRequestMetrics(registry)
+	if err != nil {
+		return nil, err
+	}
+
+	accessLogger := middleware.NewAccessLogger(accessLogWriter)
+
+	ws := gin.New()
+	if accessLogWriter == nil {
+		ws.Use(gin.Logger())
+	}
+	ws.Use(gin.Recovery())
+	ws.Use(requestMetrics.Handler())
+	ws.Use(accessLogger.Handler())
 	ws.Use(cors.Default())
 
-	err := registerValidators()
+	err = registerValidators()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	registerRoutes(ws, numbatProxyFacade)
+	registerRoutes(ws, numbatProxyFacade, middlewares)
+	metricsRoutes := ws.Group("/metrics")
+	metricsRoutes.Use(middlewares...)
+	metricsRoutes.GET("", MetricsHandler(registry))
+	requestMetrics.SetRoutes(ws.Routes())
+	accessLogger.SetRoutes(ws.Routes())
 
-	return ws.Run(fmt.Sprintf(":%d", port))
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return nil, err
+	}
+
+	server := &http.Server{
+		Handler:           ws,
+		TLSConfig:         tlsConfig,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+	go func() {
+		var errServe error
+		if tlsConfig != nil {
+			// the certificates are already loaded in the server's TLS config
+			errServe = server.ServeTLS(listener, "", "")
+		} else {
+			errServe = server.Serve(listener)
+		}
+		if !errors.Is(errServe, http.ErrServerClosed) {
+			log.Error(fmt.Sprintf("web server stopped: %s", errServe.Error()))
+		}
+	}()
+
+	return server, nil
 }
 
-func registerRoutes(ws *gin.Engine, numbatProxyFacade NumbatProxyHandler) {
+func registerRoutes(ws *gin.Engine, numbatProxyFacade NumbatProxyHandler, middlewares []gin.HandlerFunc) {
 	addressRoutes := ws.Group("/address")
+	addressRoutes.Use(middlewares...)
 	addressRoutes.Use(WithNumbatProxyFacade(numbatProxyFacade))
 	address.Routes(addressRoutes)
 
 	txRoutes := ws.Group("/transaction")
+	txRoutes.Use(middlewares...)
 	txRoutes.Use(WithNumbatProxyFacade(numbatProxyFacade))
 	transaction.Routes(txRoutes)
+
+	proxyRoutes := ws.Group("/proxy")
+	proxyRoutes.Use(middlewares...)
+	proxyRoutes.Use(WithNumbatProxyFacade(numbatProxyFacade))
+	proxy.Routes(proxyRoutes)
+
+	healthRoutes := ws.Group("/health")
+	healthRoutes.Use(WithNumbatProxyFacade(numbatProxyFacade))
+	health.Routes(healthRoutes)
+}
+
+// MetricsHandler renders the metrics of the registry in the Prometheus text exposition format
+func MetricsHandler(registry *metrics.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		c.Status(http.StatusOK)
+
+		// the status is already sent, so a failed write can not be reported to the client
+		_ = registry.Write(c.Writer)
+	}
 }
 
 func registerValidators() error {
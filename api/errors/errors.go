@@ -13,3 +13,12 @@ var ErrInvalidSignatureHex = errors.New("invalid signature, could not decode hex
 
 // ErrTxGenerationFailed signals an error generating a transaction
 var ErrTxGenerationFailed = errors.New("transaction generation failed")
+
+// ErrEmptyTxList signals that an empty list of transactions was provided
+var ErrEmptyTxList = errors.New("empty list of transactions")
+
+// ErrInvalidTimeout signals that an invalid timeout value was provided
+var ErrInvalidTimeout = errors.New("invalid timeout")
+
+// ErrInvalidTlsConfig signals that the TLS settings of the listener are incomplete or their files can not be loaded
+var ErrInvalidTlsConfig = errors.New("invalid TLS configuration")
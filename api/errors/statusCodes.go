@@ -0,0 +1,97 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/numbatx/numbat-proxy/data"
+	"github.com/numbatx/numbat-proxy/process"
+)
+
+// ResponseStatusCode returns the http status code the api should respond with for an error
+// produced while resolving a request
+func ResponseStatusCode(err error) int {
+	var observerErr *process.ObserverError
+
+	switch {
+	case errors.Is(err, process.ErrInvalidAddress),
+		errors.Is(err, process.ErrInvalidTransaction),
+		errors.Is(err, process.ErrInvalidNonce),
+		errors.Is(err, process.ErrInvalidTransactionHash),
+		errors.Is(err, process.ErrMissingSenderOrReceiver),
+		errors.Is(err, process.ErrEmptyAddressesList),
+		errors.Is(err, process.ErrTooManyAddresses):
+		return http.StatusBadRequest
+	case errors.Is(err, process.ErrTransactionNotFound), errors.Is(err, process.ErrNonceAllocationDisabled):
+		return http.StatusNotFound
+	case errors.Is(err, process.ErrTooManyReservedNonces):
+		return http.StatusTooManyRequests
+	case errors.Is(err, process.ErrMissingObserver),
+		errors.Is(err, process.ErrAccountsNotifierClosed),
+		errors.Is(err, process.ErrTooManySubscriptions),
+		errors.Is(err, process.ErrTooManyPolledAddresses),
+		errors.Is(err, process.ErrTooManyTrackedSenders):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, process.ErrNotSupportedByObservers), errors.Is(err, process.ErrSimulationNotSupported):
+		return http.StatusNotImplemented
+	case isTimeout(err):
+		return http.StatusGatewayTimeout
+	case errors.As(err, &observerErr):
+		return statusCodeForObserverError(observerErr)
+	case errors.Is(err, process.ErrSendingRequest):
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func statusCodeForObserverError(observerErr *process.ObserverError) int {
+	switch observerErr.StatusCode {
+	case http.StatusNotFound:
+		return http.StatusNotFound
+	case http.StatusRequestTimeout:
+		return http.StatusGatewayTimeout
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return http.StatusServiceUnavailable
+	}
+
+	if observerErr.IsRetryable() {
+		return http.StatusBadGateway
+	}
+
+	return http.StatusBadRequest
+}
+
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// InvalidTransactionField returns the name of the transaction field that did not pass the validation, if
+// that is the error's cause
+func InvalidTransactionField(err error) (string, bool) {
+	var fieldErr *process.TransactionFieldError
+	if !errors.As(err, &fieldErr) {
+		return "", false
+	}
+
+	return fieldErr.Field, true
+}
+
+// RejectedNonceCheck returns the outcome of the nonce check that rejected the transaction, if that is the
+// error's cause
+func RejectedNonceCheck(err error) (*data.NonceCheck, bool) {
+	var nonceErr *process.NonceError
+	if !errors.As(err, &nonceErr) {
+		return nil, false
+	}
+
+	return nonceErr.Check, true
+}
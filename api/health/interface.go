@@ -0,0 +1,8 @@
+package health
+
+import "context"
+
+// FacadeHandler interface defines methods that can be used from `numbatProxyFacade` context variable
+type FacadeHandler interface {
+	CheckReadiness(ctx context.Context) error
+}
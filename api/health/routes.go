@@ -0,0 +1,45 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/numbatx/numbat-proxy/api/errors"
+)
+
+// readinessTimeout bounds the time spent probing the observers while checking the readiness
+const readinessTimeout = 5 * time.Second
+
+// Routes defines the routes probing the proxy's own health
+func Routes(router *gin.RouterGroup) {
+	router.GET("/live", GetLiveness)
+	router.GET("/ready", GetReadiness)
+}
+
+// GetLiveness responds as long as the proxy is able to serve requests
+func GetLiveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "alive"})
+}
+
+// GetReadiness responds with 200 if the config was applied and each shard has a reachable observer,
+// and with 503 otherwise
+func GetReadiness(c *gin.Context) {
+	epf, ok := c.MustGet("numbatProxyFacade").(FacadeHandler)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errors.ErrInvalidAppContext.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+	defer cancel()
+
+	err := epf.CheckReadiness(ctx)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not ready", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ready"})
+}
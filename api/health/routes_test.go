@@ -0,0 +1,123 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	"github.com/numbatx/numbat-proxy/api"
+	apiErrors "github.com/numbatx/numbat-proxy/api/errors"
+	"github.com/numbatx/numbat-proxy/api/health"
+	"github.com/numbatx/numbat-proxy/api/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+type healthResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+}
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func startNodeServerWrongFacade() *gin.Engine {
+	ws := gin.New()
+	ws.Use(cors.Default())
+	ws.Use(func(c *gin.Context) {
+		c.Set("numbatProxyFacade", mock.WrongFacade{})
+	})
+	healthRoutes := ws.Group("/health")
+	health.Routes(healthRoutes)
+	return ws
+}
+
+func startNodeServer(handler health.FacadeHandler) *gin.Engine {
+	ws := gin.New()
+	ws.Use(cors.Default())
+	healthRoutes := ws.Group("/health")
+	if handler != nil {
+		healthRoutes.Use(api.WithNumbatProxyFacade(handler))
+	}
+	health.Routes(healthRoutes)
+	return ws
+}
+
+func loadResponse(rsp io.Reader, destination interface{}) {
+	jsonParser := json.NewDecoder(rsp)
+	err := jsonParser.Decode(destination)
+	if err != nil {
+		fmt.Println(err)
+	}
+}
+
+func doGet(ws *gin.Engine, path string) (*httptest.ResponseRecorder, *healthResponse) {
+	req, _ := http.NewRequest("GET", path, nil)
+	resp := httptest.NewRecorder()
+	ws.ServeHTTP(resp, req)
+
+	response := &healthResponse{}
+	loadResponse(resp.Body, response)
+
+	return resp, response
+}
+
+func TestGetLiveness_ShouldRespondAlive(t *testing.T) {
+	t.Parallel()
+
+	ws := startNodeServer(&mock.Facade{})
+	resp, response := doGet(ws, "/health/live")
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "alive", response.Status)
+}
+
+func TestGetReadiness_FailsWithWrongFacadeTypeConversion(t *testing.T) {
+	t.Parallel()
+
+	ws := startNodeServerWrongFacade()
+	resp, response := doGet(ws, "/health/ready")
+
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.Equal(t, apiErrors.ErrInvalidAppContext.Error(), response.Error)
+}
+
+func TestGetReadiness_NotReadyShouldRespondServiceUnavailable(t *testing.T) {
+	t.Parallel()
+
+	facade := &mock.Facade{
+		CheckReadinessHandler: func(ctx context.Context) error {
+			return errors.New("no reachable observer on shard 1")
+		},
+	}
+	ws := startNodeServer(facade)
+	resp, response := doGet(ws, "/health/ready")
+
+	assert.Equal(t, http.StatusServiceUnavailable, resp.Code)
+	assert.Equal(t, "not ready", response.Status)
+	assert.Equal(t, "no reachable observer on shard 1", response.Error)
+}
+
+func TestGetReadiness_ReadyShouldRespondOk(t *testing.T) {
+	t.Parallel()
+
+	facade := &mock.Facade{
+		CheckReadinessHandler: func(ctx context.Context) error {
+			_, hasDeadline := ctx.Deadline()
+			assert.True(t, hasDeadline)
+			return nil
+		},
+	}
+	ws := startNodeServer(facade)
+	resp, response := doGet(ws, "/health/ready")
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "ready", response.Status)
+}
@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/numbatx/numbat-proxy/process"
+)
+
+// RequestIdContextKey is the gin context key holding the id of the request
+const RequestIdContextKey = "requestId"
+
+const maxRequestIdLength = 128
+const generatedRequestIdBytes = 16
+
+type accessLogEntry struct {
+	Time      string   `json:"time"`
+	RequestId string   `json:"requestId"`
+	Method    string   `json:"method"`
+	Route     string   `json:"route"`
+	Path      string   `json:"path"`
+	Status    int      `json:"status"`
+	LatencyMs float64  `json:"latencyMs"`
+	Client    string   `json:"client"`
+	ApiKey    string   `json:"apiKey,omitempty"`
+	Observers []string `json:"observers"`
+}
+
+// AccessLogger gives each request an id and writes, for each served request, one JSON access log line. The
+// id is taken from the X-Request-ID header when the client provides a valid one and is echoed in the response
+type AccessLogger struct {
+	*routeResolver
+
+	mutWriter sync.Mutex
+	writer    io.Writer
+}
+
+// NewAccessLogger creates a new instance of AccessLogger. With a nil writer the requests still get their
+// ids, but no access log is written
+func NewAccessLogger(writer io.Writer) *AccessLogger {
+	return &AccessLogger{
+		routeResolver: newRouteResolver(),
+		writer:        writer,
+	}
+}
+
+// Handler returns the gin middleware assigning the request ids and writing the access log. The id is stored
+// in the request's context, so the processors forward it to the observers they call
+func (al *AccessLogger) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestId := c.GetHeader(process.RequestIdHeader)
+		if !isValidRequestId(requestId) {
+			requestId = generateRequestId()
+		}
+		c.Set(RequestIdContextKey, requestId)
+		c.Header(process.RequestIdHeader, requestId)
+		c.Request = c.Request.WithContext(process.NewRequestContext(c.Request.Context(), requestId))
+
+		c.Next()
+
+		if al.writer == nil {
+			return
+		}
+
+		observers := process.GetContactedObservers(c.Request.Context())
+		if observers == nil {
+			observers = make([]string, 0)
+		}
+
+		al.write(&accessLogEntry{
+			Time:      start.UTC().Format(time.RFC3339Nano),
+			RequestId: requestId,
+			Method:    c.Request.Method,
+			Route:     al.resolveRoute(c),
+			Path:      c.Request.URL.Path,
+			Status:    c.Writer.Status(),
+			LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+			Client:    c.ClientIP(),
+			ApiKey:    c.GetString(ApiKeyNameContextKey),
+			Observers: observers,
+		})
+	}
+}
+
+func (al *AccessLogger) write(entry *accessLogEntry) {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		log.LogIfError(err)
+		return
+	}
+	line = append(line, '\n')
+
+	al.mutWriter.Lock()
+	defer al.mutWriter.Unlock()
+
+	_, err = al.writer.Write(line)
+	log.LogIfError(err)
+}
+
+// isValidRequestId accepts the client provided ids that can be safely logged and forwarded as a header
+func isValidRequestId(requestId string) bool {
+	if len(requestId) == 0 || len(requestId) > maxRequestIdLength {
+		return false
+	}
+
+	for _, char := range requestId {
+		isAlphanumeric := (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || (char >= '0' && char <= '9')
+		isSeparator := char == '-' || char == '_' || char == '.' || char == ':'
+		if !isAlphanumeric && !isSeparator {
+			return false
+		}
+	}
+
+	return true
+}
+
+func generateRequestId() string {
+	buff := make([]byte, generatedRequestIdBytes)
+	_, _ = rand.Read(buff)
+
+	return hex.EncodeToString(buff)
+}
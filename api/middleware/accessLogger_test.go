@@ -0,0 +1,139 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/numbatx/numbat-proxy/api/middleware"
+	"github.com/numbatx/numbat-proxy/metrics"
+	"github.com/numbatx/numbat-proxy/process"
+	"github.com/numbatx/numbat-proxy/process/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+type accessLogLine struct {
+	RequestId string   `json:"requestId"`
+	Method    string   `json:"method"`
+	Route     string   `json:"route"`
+	Path      string   `json:"path"`
+	Status    int      `json:"status"`
+	Client    string   `json:"client"`
+	ApiKey    string   `json:"apiKey"`
+	Observers []string `json:"observers"`
+}
+
+var requestIdsSeenByHandler = make(chan string, 10)
+
+func getAccountFromObserversHandler(c *gin.Context) {
+	requestIdsSeenByHandler <- process.GetRequestId(c.Request.Context())
+	c.Set(middleware.ApiKeyNameContextKey, "wallet")
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+func startAccessLoggedServer(buff *bytes.Buffer) *gin.Engine {
+	al := middleware.NewAccessLogger(buff)
+	ws := gin.New()
+	ws.Use(al.Handler())
+	ws.GET("/address/:address", getAccountFromObserversHandler)
+	al.SetRoutes(ws.Routes())
+
+	return ws
+}
+
+func doRequestWithId(ws *gin.Engine, path string, requestId string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", path, nil)
+	if requestId != "" {
+		req.Header.Set(process.RequestIdHeader, requestId)
+	}
+	resp := httptest.NewRecorder()
+	ws.ServeHTTP(resp, req)
+
+	return resp
+}
+
+func TestAccessLogger_ShouldWriteOneJsonLinePerRequest(t *testing.T) {
+	buff := &bytes.Buffer{}
+	ws := startAccessLoggedServer(buff)
+
+	resp := doRequestWithId(ws, "/address/aa", "client-id-1")
+	assert.Equal(t, "client-id-1", resp.Header().Get(process.RequestIdHeader))
+	assert.Equal(t, "client-id-1", <-requestIdsSeenByHandler)
+
+	_ = doRequestWithId(ws, "/missing", "")
+
+	lines := strings.Split(strings.TrimSpace(buff.String()), "\n")
+	assert.Equal(t, 2, len(lines))
+
+	first := accessLogLine{}
+	_ = json.Unmarshal([]byte(lines[0]), &first)
+	assert.Equal(t, "client-id-1", first.RequestId)
+	assert.Equal(t, "GET", first.Method)
+	assert.Equal(t, "/address/:address", first.Route)
+	assert.Equal(t, "/address/aa", first.Path)
+	assert.Equal(t, http.StatusOK, first.Status)
+	assert.Equal(t, "wallet", first.ApiKey)
+	assert.Equal(t, []string{}, first.Observers)
+
+	second := accessLogLine{}
+	_ = json.Unmarshal([]byte(lines[1]), &second)
+	assert.Equal(t, middleware.UnmatchedRoute, second.Route)
+	assert.Equal(t, http.StatusNotFound, second.Status)
+	assert.Equal(t, 32, len(second.RequestId))
+}
+
+func TestAccessLogger_InvalidRequestIdShouldBeReplaced(t *testing.T) {
+	buff := &bytes.Buffer{}
+	ws := startAccessLoggedServer(buff)
+
+	resp := doRequestWithId(ws, "/address/aa", "bad id\twith spaces")
+	requestId := resp.Header().Get(process.RequestIdHeader)
+
+	assert.Equal(t, requestId, <-requestIdsSeenByHandler)
+	assert.Equal(t, 32, len(requestId))
+}
+
+func TestAccessLogger_ObserverCredentialsShouldBeRedacted(t *testing.T) {
+	t.Parallel()
+
+	observer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write([]byte("{}"))
+	}))
+	defer observer.Close()
+	observerAddress := strings.Replace(observer.URL, "http://", "http://user:secret@", 1)
+
+	buff := &bytes.Buffer{}
+	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{}, metrics.NewRegistry())
+	al := middleware.NewAccessLogger(buff)
+	ws := gin.New()
+	ws.Use(al.Handler())
+	ws.GET("/address/:address", func(c *gin.Context) {
+		err := bp.CallGetRestEndPoint(c.Request.Context(), observerAddress, "/address/aa", &struct{}{})
+		assert.Nil(t, err)
+		c.JSON(http.StatusOK, gin.H{})
+	})
+
+	_ = doRequestWithId(ws, "/address/aa", "")
+
+	line := accessLogLine{}
+	_ = json.Unmarshal(buff.Bytes(), &line)
+	assert.Equal(t, []string{strings.Replace(observerAddress, "secret", "redacted", 1)}, line.Observers)
+	assert.NotContains(t, buff.String(), "secret")
+}
+
+func TestAccessLogger_NilWriterShouldOnlyAssignIds(t *testing.T) {
+	t.Parallel()
+
+	al := middleware.NewAccessLogger(nil)
+	ws := gin.New()
+	ws.Use(al.Handler())
+
+	resp := doRequestWithId(ws, "/missing", "")
+
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.NotEmpty(t, resp.Header().Get(process.RequestIdHeader))
+}
@@ -0,0 +1,224 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/numbatx/numbat-proxy/config"
+	"github.com/numbatx/numbat-proxy/process"
+)
+
+// ApiKeyNameContextKey is the gin context key holding the name of the authenticated API key
+const ApiKeyNameContextKey = "apiKeyName"
+
+const apiKeyPolicyContextKey = "apiKeyPolicy"
+
+// ApiKeyPolicy holds what the client authenticated with an API key is allowed to do
+type ApiKeyPolicy struct {
+	Name               string
+	ReadRateLimit      config.RateLimitRuleConfig
+	WriteRateLimit     config.RateLimitRuleConfig
+	allowedRouteGroups map[string]struct{}
+	allowedSenders     map[string]struct{}
+	codec              process.AddressCodec
+}
+
+func newApiKeyPolicy(keyCfg *config.ApiKeyConfig, codec process.AddressCodec) (*ApiKeyPolicy, error) {
+	// the senders are kept as decoded bytes so they match whatever format the transactions use
+	allowedSenders := make([]string, 0, len(keyCfg.AllowedSenders))
+	for _, sender := range keyCfg.AllowedSenders {
+		senderBuff, err := codec.DecodeAddress(sender)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidAuthenticationConfig, keyCfg.Name, err)
+		}
+		allowedSenders = append(allowedSenders, string(senderBuff))
+	}
+
+	return &ApiKeyPolicy{
+		Name:               keyCfg.Name,
+		ReadRateLimit:      keyCfg.ReadRateLimit,
+		WriteRateLimit:     keyCfg.WriteRateLimit,
+		allowedRouteGroups: toSet(keyCfg.AllowedRouteGroups),
+		allowedSenders:     toSet(allowedSenders),
+		codec:              codec,
+	}, nil
+}
+
+// IsRouteGroupAllowed returns true if the client can access the route group
+func (akp *ApiKeyPolicy) IsRouteGroupAllowed(routeGroup string) bool {
+	return isInSetOrSetEmpty(akp.allowedRouteGroups, routeGroup)
+}
+
+// IsSenderAllowed returns true if the client can send transactions from the sender's address. The sender
+// can be in any of the accepted address formats
+func (akp *ApiKeyPolicy) IsSenderAllowed(sender string) bool {
+	if len(akp.allowedSenders) == 0 {
+		return true
+	}
+
+	senderBuff, err := akp.codec.DecodeAddress(sender)
+	if err != nil {
+		return false
+	}
+
+	_, ok := akp.allowedSenders[string(senderBuff)]
+
+	return ok
+}
+
+// GetApiKeyPolicy returns the policy of the API key the request was authenticated with. The second
+// return value is false if the request was not authenticated
+func GetApiKeyPolicy(c *gin.Context) (*ApiKeyPolicy, bool) {
+	value, ok := c.Get(apiKeyPolicyContextKey)
+	if !ok {
+		return nil, false
+	}
+
+	policy, ok := value.(*ApiKeyPolicy)
+
+	return policy, ok
+}
+
+// Authenticator rejects the requests that do not carry a known API key or that target a route group
+// not allowed for their key
+type Authenticator struct {
+	header string
+	codec  process.AddressCodec
+
+	mutPolicies sync.RWMutex
+	policies    map[string]*ApiKeyPolicy
+}
+
+// NewAuthenticator creates a new instance of Authenticator. The codec decodes the allowed senders, which
+// can be in any of the accepted address formats
+func NewAuthenticator(cfg config.AuthenticationConfig, codec process.AddressCodec) (*Authenticator, error) {
+	if cfg.Header == "" {
+		return nil, ErrInvalidAuthenticationConfig
+	}
+	if codec == nil {
+		return nil, ErrNilAddressCodec
+	}
+
+	policies, err := createPolicies(cfg.Keys, codec)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Authenticator{
+		header:   cfg.Header,
+		codec:    codec,
+		policies: policies,
+	}, nil
+}
+
+// ValidateConfig returns the error ApplyConfig would return for the config, without applying it
+func (a *Authenticator) ValidateConfig(cfg *config.Config) error {
+	if cfg == nil {
+		return ErrInvalidAuthenticationConfig
+	}
+
+	_, err := createPolicies(cfg.Authentication.Keys, a.codec)
+
+	return err
+}
+
+// ApplyConfig replaces the accepted API keys with the ones in the config. If the keys are not
+// valid, the previous ones remain active
+func (a *Authenticator) ApplyConfig(cfg *config.Config) error {
+	if cfg == nil {
+		return ErrInvalidAuthenticationConfig
+	}
+
+	policies, err := createPolicies(cfg.Authentication.Keys, a.codec)
+	if err != nil {
+		return err
+	}
+
+	a.mutPolicies.Lock()
+	a.policies = policies
+	a.mutPolicies.Unlock()
+
+	return nil
+}
+
+func createPolicies(keys []*config.ApiKeyConfig, codec process.AddressCodec) (map[string]*ApiKeyPolicy, error) {
+	policies := make(map[string]*ApiKeyPolicy, len(keys))
+	names := make(map[string]struct{}, len(keys))
+	for _, keyCfg := range keys {
+		if keyCfg.Key == "" || keyCfg.Name == "" {
+			return nil, ErrInvalidAuthenticationConfig
+		}
+
+		_, isKeyDuplicated := policies[keyCfg.Key]
+		_, isNameDuplicated := names[keyCfg.Name]
+		if isKeyDuplicated || isNameDuplicated {
+			return nil, ErrInvalidAuthenticationConfig
+		}
+
+		policy, err := newApiKeyPolicy(keyCfg, codec)
+		if err != nil {
+			return nil, err
+		}
+
+		policies[keyCfg.Key] = policy
+		names[keyCfg.Name] = struct{}{}
+	}
+
+	return policies, nil
+}
+
+// Handler returns the gin middleware authenticating the requests. The authenticated key's name and
+// policy are stored in the gin context
+func (a *Authenticator) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(a.header)
+		if key == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": ErrMissingApiKey.Error()})
+			return
+		}
+
+		a.mutPolicies.RLock()
+		policy, ok := a.policies[key]
+		a.mutPolicies.RUnlock()
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": ErrUnknownApiKey.Error()})
+			return
+		}
+
+		if !policy.IsRouteGroupAllowed(routeGroup(c.Request.URL.Path)) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": ErrRouteGroupNotAllowed.Error()})
+			return
+		}
+
+		c.Set(ApiKeyNameContextKey, policy.Name)
+		c.Set(apiKeyPolicyContextKey, policy)
+		c.Next()
+	}
+}
+
+// routeGroup returns the first segment of the path, for example "address" for "/address/:address/nonce"
+func routeGroup(path string) string {
+	return strings.SplitN(strings.TrimPrefix(path, "/"), "/", 2)[0]
+}
+
+func toSet(values []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		set[value] = struct{}{}
+	}
+
+	return set
+}
+
+func isInSetOrSetEmpty(set map[string]struct{}, value string) bool {
+	if len(set) == 0 {
+		return true
+	}
+
+	_, ok := set[value]
+
+	return ok
+}
@@ -0,0 +1,153 @@
+package middleware_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/numbatx/numbat-proxy/api/middleware"
+	"github.com/numbatx/numbat-proxy/config"
+	"github.com/numbatx/numbat-proxy/process"
+	"github.com/stretchr/testify/assert"
+)
+
+func createAuthenticationConfig() config.AuthenticationConfig {
+	return config.AuthenticationConfig{
+		Enabled: true,
+		Header:  "X-Api-Key",
+		Keys: []*config.ApiKeyConfig{
+			{Name: "all", Key: "key-all"},
+			{Name: "readers", Key: "key-readers", AllowedRouteGroups: []string{"address"}},
+		},
+	}
+}
+
+func createHexAddressCodec() process.AddressCodec {
+	codec, _ := process.NewAddressCodec(config.AddressesConfig{})
+
+	return codec
+}
+
+func startAuthenticatedServer(middlewares ...gin.HandlerFunc) *gin.Engine {
+	ws := gin.New()
+	ws.Use(middlewares...)
+	ws.GET("/address/:address", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"name": c.GetString(middleware.ApiKeyNameContextKey)})
+	})
+	ws.POST("/transaction/send", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{})
+	})
+
+	return ws
+}
+
+func TestNewAuthenticator_DuplicatedKeyShouldErr(t *testing.T) {
+	t.Parallel()
+
+	cfg := createAuthenticationConfig()
+	cfg.Keys[1].Key = cfg.Keys[0].Key
+	a, err := middleware.NewAuthenticator(cfg, createHexAddressCodec())
+
+	assert.Nil(t, a)
+	assert.Equal(t, middleware.ErrInvalidAuthenticationConfig, err)
+}
+
+func TestAuthenticator_MissingOrUnknownKeyShouldRespondUnauthorized(t *testing.T) {
+	t.Parallel()
+
+	a, _ := middleware.NewAuthenticator(createAuthenticationConfig(), createHexAddressCodec())
+	ws := startAuthenticatedServer(a.Handler())
+
+	assert.Equal(t, http.StatusUnauthorized, doRequest(ws, "GET", "/address/aa", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, doRequest(ws, "GET", "/address/aa", "unknown").Code)
+	assert.Equal(t, http.StatusOK, doRequest(ws, "GET", "/address/aa", "key-all").Code)
+}
+
+func TestAuthenticator_RouteGroupNotAllowedShouldRespondForbidden(t *testing.T) {
+	t.Parallel()
+
+	a, _ := middleware.NewAuthenticator(createAuthenticationConfig(), createHexAddressCodec())
+	ws := startAuthenticatedServer(a.Handler())
+
+	resp := doRequest(ws, "GET", "/address/aa", "key-readers")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"name":"readers"`)
+
+	assert.Equal(t, http.StatusForbidden, doRequest(ws, "POST", "/transaction/send", "key-readers").Code)
+	assert.Equal(t, http.StatusOK, doRequest(ws, "POST", "/transaction/send", "key-all").Code)
+}
+
+func TestAuthenticator_ApplyConfigShouldReplaceKeys(t *testing.T) {
+	t.Parallel()
+
+	a, _ := middleware.NewAuthenticator(createAuthenticationConfig(), createHexAddressCodec())
+	ws := startAuthenticatedServer(a.Handler())
+
+	newCfg := &config.Config{
+		Authentication: config.AuthenticationConfig{
+			Keys: []*config.ApiKeyConfig{{Name: "new", Key: "key-new"}},
+		},
+	}
+	assert.Nil(t, a.ApplyConfig(newCfg))
+	assert.Equal(t, http.StatusUnauthorized, doRequest(ws, "GET", "/address/aa", "key-all").Code)
+	assert.Equal(t, http.StatusOK, doRequest(ws, "GET", "/address/aa", "key-new").Code)
+
+	invalidCfg := &config.Config{
+		Authentication: config.AuthenticationConfig{
+			Keys: []*config.ApiKeyConfig{{Name: "", Key: "key-invalid"}},
+		},
+	}
+	assert.Equal(t, middleware.ErrInvalidAuthenticationConfig, a.ValidateConfig(invalidCfg))
+	assert.Equal(t, middleware.ErrInvalidAuthenticationConfig, a.ApplyConfig(invalidCfg))
+	assert.Equal(t, http.StatusOK, doRequest(ws, "GET", "/address/aa", "key-new").Code)
+}
+
+func TestAuthenticator_KeyRateLimitShouldOverrideGeneralOne(t *testing.T) {
+	t.Parallel()
+
+	authCfg := createAuthenticationConfig()
+	authCfg.Keys[0].WriteRateLimit = config.RateLimitRuleConfig{RequestsPerSecond: 0.1, Burst: 3}
+	a, _ := middleware.NewAuthenticator(authCfg, createHexAddressCodec())
+	rl, _ := middleware.NewRateLimiter(createRateLimitConfig())
+	ws := startAuthenticatedServer(a.Handler(), rl.Handler())
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, doRequest(ws, "POST", "/transaction/send", "key-all").Code)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, doRequest(ws, "POST", "/transaction/send", "key-all").Code)
+}
+
+func TestApiKeyPolicy_IsSenderAllowedShouldMatchAnyAddressFormat(t *testing.T) {
+	t.Parallel()
+
+	codec, _ := process.NewAddressCodec(config.AddressesConfig{OutputFormat: process.Bech32AddressFormat})
+	bech32Sender := codec.EncodeAddress([]byte{0xaa, 0xbb})
+	authCfg := createAuthenticationConfig()
+	authCfg.Keys[0].AllowedSenders = []string{bech32Sender}
+	a, _ := middleware.NewAuthenticator(authCfg, codec)
+
+	var policy *middleware.ApiKeyPolicy
+	ws := gin.New()
+	ws.Use(a.Handler())
+	ws.GET("/address/:address", func(c *gin.Context) {
+		policy, _ = middleware.GetApiKeyPolicy(c)
+	})
+	doRequest(ws, "GET", "/address/aa", "key-all")
+
+	assert.True(t, policy.IsSenderAllowed("aabb"))
+	assert.True(t, policy.IsSenderAllowed(bech32Sender))
+	assert.False(t, policy.IsSenderAllowed("aacc"))
+	assert.False(t, policy.IsSenderAllowed("not an address"))
+}
+
+func TestNewAuthenticator_InvalidAllowedSenderShouldErr(t *testing.T) {
+	t.Parallel()
+
+	authCfg := createAuthenticationConfig()
+	authCfg.Keys[0].AllowedSenders = []string{"not an address"}
+	a, err := middleware.NewAuthenticator(authCfg, createHexAddressCodec())
+
+	assert.Nil(t, a)
+	assert.True(t, errors.Is(err, middleware.ErrInvalidAuthenticationConfig))
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import "errors"
+
+// ErrInvalidRateLimitConfig signals that an invalid rate limit configuration has been provided
+var ErrInvalidRateLimitConfig = errors.New("invalid rate limit configuration")
+
+// ErrTooManyRequests signals that a client exceeded its rate limit
+var ErrTooManyRequests = errors.New("too many requests")
+
+// ErrBatchExceedsBurst signals that a request holds more transactions than the client's write burst allows
+var ErrBatchExceedsBurst = errors.New("the request holds more transactions than the write rate limit burst")
+
+// ErrInvalidAuthenticationConfig signals that an invalid authentication configuration has been provided
+var ErrInvalidAuthenticationConfig = errors.New("invalid authentication configuration")
+
+// ErrMissingApiKey signals that a request did not provide an API key
+var ErrMissingApiKey = errors.New("missing API key")
+
+// ErrUnknownApiKey signals that a request provided an API key the proxy does not know
+var ErrUnknownApiKey = errors.New("unknown API key")
+
+// ErrRouteGroupNotAllowed signals that the request's API key is not allowed to access the route group
+var ErrRouteGroupNotAllowed = errors.New("route group not allowed for this API key")
+
+// ErrSenderNotAllowed signals that the request's API key is not allowed to send transactions from the sender
+var ErrSenderNotAllowed = errors.New("sender not allowed for this API key")
+
+// ErrNilMetricsRegistry signals that a nil metrics registry has been provided
+var ErrNilMetricsRegistry = errors.New("nil metrics registry")
+
+// ErrNilAddressCodec signals that a nil address codec has been provided
+var ErrNilAddressCodec = errors.New("nil address codec")
@@ -0,0 +1,430 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/numbatx/gn-numbat/core/logger"
+	"github.com/numbatx/numbat-proxy/config"
+)
+
+var log = logger.DefaultLogger()
+
+// KeyByIP identifies the rate limited clients by their IP
+const KeyByIP = "ip"
+
+// KeyByApiKey identifies the rate limited clients by the API key they were authenticated with, falling
+// back to their IP
+const KeyByApiKey = "api-key"
+
+const readBucketPrefix = "read:"
+const writeBucketPrefix = "write:"
+const overflowClient = "overflow"
+const bucketsSweepInterval = time.Minute
+const fullSweepInterval = time.Second
+const forwardedForHeader = "X-Forwarded-For"
+const writeTokensContextKey = "rateLimitWriteTokens"
+const simulateTransactionPath = "/transaction/simulate"
+
+type tokenBucket struct {
+	Tokens     float64                    `json:"tokens"`
+	LastRefill time.Time                  `json:"lastRefill"`
+	Rule       config.RateLimitRuleConfig `json:"rule"`
+}
+
+// refill adds the tokens accumulated since the last refill and returns true if the bucket is full
+func (tb *tokenBucket) refill(now time.Time) bool {
+	elapsed := now.Sub(tb.LastRefill).Seconds()
+	if elapsed > 0 {
+		tb.Tokens = math.Min(float64(tb.Rule.Burst), tb.Tokens+elapsed*tb.Rule.RequestsPerSecond)
+		tb.LastRefill = now
+	}
+
+	return tb.Tokens >= float64(tb.Rule.Burst)
+}
+
+// RateLimiter limits, with one token bucket per client, the number of requests each client can make.
+// Write requests and read requests are limited separately. Once the maximum number of clients is reached,
+// the new clients share a single bucket
+type RateLimiter struct {
+	keyBy            string
+	trustedProxies   []*net.IPNet
+	maxClients       int
+	readRule         config.RateLimitRuleConfig
+	writeRule        config.RateLimitRuleConfig
+	snapshotFile     string
+	snapshotInterval time.Duration
+
+	mutBuckets    sync.Mutex
+	buckets       map[string]*tokenBucket
+	lastFullSweep time.Time
+	chanClose     chan struct{}
+	closeOnce     sync.Once
+}
+
+// NewRateLimiter creates a new instance of RateLimiter. If a snapshot file is configured and can be read,
+// the buckets are restored from it
+func NewRateLimiter(cfg config.RateLimitConfig) (*RateLimiter, error) {
+	if cfg.KeyBy != KeyByIP && cfg.KeyBy != KeyByApiKey {
+		return nil, ErrInvalidRateLimitConfig
+	}
+	if cfg.MaxClients <= 0 {
+		return nil, ErrInvalidRateLimitConfig
+	}
+	if !isRuleValid(cfg.Read) || !isRuleValid(cfg.Write) {
+		return nil, ErrInvalidRateLimitConfig
+	}
+	if cfg.SnapshotFile != "" && cfg.SnapshotIntervalInSec <= 0 {
+		return nil, ErrInvalidRateLimitConfig
+	}
+	trustedProxies, err := parseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+
+	rl := &RateLimiter{
+		keyBy:            cfg.KeyBy,
+		trustedProxies:   trustedProxies,
+		maxClients:       cfg.MaxClients,
+		readRule:         cfg.Read,
+		writeRule:        cfg.Write,
+		snapshotFile:     cfg.SnapshotFile,
+		snapshotInterval: time.Duration(cfg.SnapshotIntervalInSec) * time.Second,
+		buckets:          make(map[string]*tokenBucket),
+		chanClose:        make(chan struct{}),
+	}
+
+	err = rl.loadSnapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	return rl, nil
+}
+
+func isRuleValid(rule config.RateLimitRuleConfig) bool {
+	return rule.RequestsPerSecond > 0 && rule.Burst > 0
+}
+
+// parseTrustedProxies accepts both single IPs and CIDRs
+func parseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	trustedProxies := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("%w: invalid trusted proxy %s", ErrInvalidRateLimitConfig, proxy)
+			}
+			trustedProxies = append(trustedProxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid trusted proxy %s", ErrInvalidRateLimitConfig, proxy)
+		}
+		trustedProxies = append(trustedProxies, ipNet)
+	}
+
+	return trustedProxies, nil
+}
+
+// Start launches the go routine that forgets the idle clients and saves the snapshots
+func (rl *RateLimiter) Start() {
+	go rl.run()
+}
+
+func (rl *RateLimiter) run() {
+	sweepTicker := time.NewTicker(bucketsSweepInterval)
+	defer sweepTicker.Stop()
+
+	var chanSnapshot <-chan time.Time
+	if rl.snapshotFile != "" {
+		snapshotTicker := time.NewTicker(rl.snapshotInterval)
+		defer snapshotTicker.Stop()
+		chanSnapshot = snapshotTicker.C
+	}
+
+	for {
+		select {
+		case <-rl.chanClose:
+			return
+		case <-sweepTicker.C:
+			rl.sweepFullBuckets()
+		case <-chanSnapshot:
+			log.LogIfError(rl.SaveSnapshot())
+		}
+	}
+}
+
+// Handler returns the gin middleware rejecting, with 429 and a Retry-After header, the requests of the
+// clients that exhausted their bucket
+func (rl *RateLimiter) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bucketKey, rule := rl.classify(c)
+		isAllowed, retryAfter := rl.take(bucketKey, rule, 1, time.Now())
+		if !isAllowed {
+			abortWithTooManyRequests(c, retryAfter)
+			return
+		}
+
+		if strings.HasPrefix(bucketKey, writeBucketPrefix) {
+			c.Set(writeTokensContextKey, func(tokens int) (bool, time.Duration) {
+				// the request already consumed a token, so a full bucket never holds more than burst - 1
+				if tokens >= rule.Burst {
+					return false, -1
+				}
+				return rl.take(bucketKey, rule, tokens, time.Now())
+			})
+		}
+
+		c.Next()
+	}
+}
+
+func abortWithTooManyRequests(c *gin.Context, retryAfter time.Duration) {
+	retryAfterInSec := int(math.Ceil(retryAfter.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(retryAfterInSec))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": ErrTooManyRequests.Error()})
+}
+
+// TakeWriteTokens consumes, from the write bucket of the request's client, the tokens of a request that
+// sends more than one transaction, as the middleware only consumed one. If the bucket does not hold enough
+// tokens, the request is aborted with 429 and false is returned. A request holding more transactions than
+// the bucket's burst can never be accepted, so it is aborted with 413. Requests that are not rate limited
+// are always allowed
+func TakeWriteTokens(c *gin.Context, tokens int) bool {
+	value, ok := c.Get(writeTokensContextKey)
+	if !ok || tokens <= 0 {
+		return true
+	}
+	takeTokens, ok := value.(func(tokens int) (bool, time.Duration))
+	if !ok {
+		return true
+	}
+
+	isAllowed, retryAfter := takeTokens(tokens)
+	if isAllowed {
+		return true
+	}
+	if retryAfter < 0 {
+		c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": ErrBatchExceedsBurst.Error()})
+		return false
+	}
+
+	abortWithTooManyRequests(c, retryAfter)
+
+	return false
+}
+
+// classify returns the bucket of the request's client and the rule that applies. Transactions sent
+// through POST are write requests, all the other requests, simulations included, are reads. A request authenticated with an
+// API key is limited by the key's rules, if provided, and, when keying by API key, by the key's name.
+// The API key header alone is not trusted, as only the authenticator knows whether the key exists
+func (rl *RateLimiter) classify(c *gin.Context) (string, config.RateLimitRuleConfig) {
+	client := "ip:" + rl.clientIP(c.Request)
+
+	readRule, writeRule := rl.readRule, rl.writeRule
+	policy, isAuthenticated := GetApiKeyPolicy(c)
+	if isAuthenticated {
+		if rl.keyBy == KeyByApiKey {
+			client = "name:" + policy.Name
+		}
+		if isRuleValid(policy.ReadRateLimit) {
+			readRule = policy.ReadRateLimit
+		}
+		if isRuleValid(policy.WriteRateLimit) {
+			writeRule = policy.WriteRateLimit
+		}
+	}
+
+	if c.Request.Method == http.MethodPost && c.Request.URL.Path != simulateTransactionPath {
+		return writeBucketPrefix + client, writeRule
+	}
+
+	return readBucketPrefix + client, readRule
+}
+
+// clientIP returns the address of the connection's peer. If the peer is a trusted proxy, the
+// X-Forwarded-For header is read from right to left, as only the entries added by the trusted proxies
+// can be relied upon, and the first address that is not a trusted proxy is returned
+func (rl *RateLimiter) clientIP(req *http.Request) string {
+	ip, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		ip = req.RemoteAddr
+	}
+	if !rl.isTrustedProxy(ip) {
+		return ip
+	}
+
+	forwardedFor := strings.Split(req.Header.Get(forwardedForHeader), ",")
+	for i := len(forwardedFor) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(forwardedFor[i])
+		if hop == "" {
+			continue
+		}
+		if !rl.isTrustedProxy(hop) {
+			return hop
+		}
+		ip = hop
+	}
+
+	return ip
+}
+
+func (rl *RateLimiter) isTrustedProxy(address string) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+
+	for _, trustedProxy := range rl.trustedProxies {
+		if trustedProxy.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// take consumes the given number of tokens from the bucket. If the bucket does not hold enough tokens, it
+// returns false and the time after which they will be available
+func (rl *RateLimiter) take(
+	bucketKey string,
+	rule config.RateLimitRuleConfig,
+	tokens int,
+	now time.Time,
+) (bool, time.Duration) {
+
+	rl.mutBuckets.Lock()
+	defer rl.mutBuckets.Unlock()
+
+	bucket, ok := rl.buckets[bucketKey]
+	if !ok {
+		bucketKey = rl.bucketKeyForNewClient(bucketKey, now)
+		bucket, ok = rl.buckets[bucketKey]
+	}
+	if !ok {
+		bucket = &tokenBucket{
+			Tokens:     float64(rule.Burst),
+			LastRefill: now,
+		}
+		rl.buckets[bucketKey] = bucket
+	}
+
+	// the rule is refreshed on each request as the rules of an API key can change on config reload
+	bucket.Rule = rule
+	bucket.refill(now)
+	if bucket.Tokens >= float64(tokens) {
+		bucket.Tokens -= float64(tokens)
+		return true, 0
+	}
+
+	missingTokens := float64(tokens) - bucket.Tokens
+	retryAfter := time.Duration(missingTokens / rule.RequestsPerSecond * float64(time.Second))
+
+	return false, retryAfter
+}
+
+// bucketKeyForNewClient returns the key of the bucket shared by the new clients if the maximum number of
+// clients is reached, even after forgetting the idle ones. The idle clients are looked for at most once per
+// second, so a flood of new clients does not scan the buckets on each request
+func (rl *RateLimiter) bucketKeyForNewClient(bucketKey string, now time.Time) string {
+	if len(rl.buckets) >= rl.maxClients && now.Sub(rl.lastFullSweep) >= fullSweepInterval {
+		rl.removeFullBuckets(now)
+		rl.lastFullSweep = now
+	}
+	if len(rl.buckets) < rl.maxClients {
+		return bucketKey
+	}
+
+	if strings.HasPrefix(bucketKey, writeBucketPrefix) {
+		return writeBucketPrefix + overflowClient
+	}
+
+	return readBucketPrefix + overflowClient
+}
+
+// sweepFullBuckets forgets the clients whose bucket refilled completely, as a new bucket is the same
+func (rl *RateLimiter) sweepFullBuckets() {
+	rl.mutBuckets.Lock()
+	defer rl.mutBuckets.Unlock()
+
+	rl.removeFullBuckets(time.Now())
+}
+
+func (rl *RateLimiter) removeFullBuckets(now time.Time) {
+	for bucketKey, bucket := range rl.buckets {
+		if bucket.refill(now) {
+			delete(rl.buckets, bucketKey)
+		}
+	}
+}
+
+// SaveSnapshot writes the buckets in the snapshot file, if one is configured. The file is replaced
+// atomically so a crash while saving does not corrupt the previous snapshot
+func (rl *RateLimiter) SaveSnapshot() error {
+	if rl.snapshotFile == "" {
+		return nil
+	}
+
+	rl.mutBuckets.Lock()
+	buff, err := json.Marshal(rl.buckets)
+	rl.mutBuckets.Unlock()
+	if err != nil {
+		return err
+	}
+
+	tmpFile := rl.snapshotFile + ".tmp"
+	err = os.WriteFile(tmpFile, buff, 0644)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile, rl.snapshotFile)
+}
+
+func (rl *RateLimiter) loadSnapshot() error {
+	if rl.snapshotFile == "" {
+		return nil
+	}
+
+	buff, err := os.ReadFile(rl.snapshotFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// a corrupted snapshot only means the clients start with full buckets, so it does not prevent the start
+	buckets := make(map[string]*tokenBucket)
+	err = json.Unmarshal(buff, &buckets)
+	if err != nil {
+		log.Warn(fmt.Sprintf("ignoring rate limit snapshot %s: %s", rl.snapshotFile, err.Error()))
+		return nil
+	}
+	rl.buckets = buckets
+
+	return nil
+}
+
+// Close stops the go routine and saves a last snapshot
+func (rl *RateLimiter) Close() error {
+	var err error
+	rl.closeOnce.Do(func() {
+		close(rl.chanClose)
+		err = rl.SaveSnapshot()
+	})
+
+	return err
+}
@@ -0,0 +1,287 @@
+package middleware_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/numbatx/numbat-proxy/api/middleware"
+	"github.com/numbatx/numbat-proxy/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func createRateLimitConfig() config.RateLimitConfig {
+	return config.RateLimitConfig{
+		Enabled:    true,
+		KeyBy:      middleware.KeyByIP,
+		MaxClients: 100,
+		Read: config.RateLimitRuleConfig{
+			RequestsPerSecond: 0.1,
+			Burst:             2,
+		},
+		Write: config.RateLimitRuleConfig{
+			RequestsPerSecond: 0.1,
+			Burst:             1,
+		},
+	}
+}
+
+func startRateLimitedServer(rl *middleware.RateLimiter, middlewares ...gin.HandlerFunc) *gin.Engine {
+	ws := gin.New()
+	ws.Use(middlewares...)
+	ws.Use(rl.Handler())
+	ws.GET("/address/:address", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{})
+	})
+	ws.POST("/transaction/send", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{})
+	})
+	ws.POST("/transaction/simulate", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{})
+	})
+	ws.POST("/transaction/send-multiple", func(c *gin.Context) {
+		numTxs, _ := strconv.Atoi(c.Query("txs"))
+		if !middleware.TakeWriteTokens(c, numTxs-1) {
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{})
+	})
+
+	return ws
+}
+
+func doRequest(ws *gin.Engine, method string, path string, apiKey string) *httptest.ResponseRecorder {
+	return doRequestWithHeader(ws, method, path, "", "X-Api-Key", apiKey)
+}
+
+func doRequestFrom(ws *gin.Engine, method string, path string, remoteAddr string) *httptest.ResponseRecorder {
+	return doRequestWithHeader(ws, method, path, remoteAddr, "", "")
+}
+
+func doRequestWithHeader(
+	ws *gin.Engine,
+	method string,
+	path string,
+	remoteAddr string,
+	header string,
+	value string,
+) *httptest.ResponseRecorder {
+
+	req, _ := http.NewRequest(method, path, nil)
+	req.RemoteAddr = remoteAddr
+	if value != "" {
+		req.Header.Set(header, value)
+	}
+	resp := httptest.NewRecorder()
+	ws.ServeHTTP(resp, req)
+
+	return resp
+}
+
+func TestNewRateLimiter_InvalidConfigShouldErr(t *testing.T) {
+	t.Parallel()
+
+	cfg := createRateLimitConfig()
+	cfg.KeyBy = "unknown"
+	rl, err := middleware.NewRateLimiter(cfg)
+	assert.Nil(t, rl)
+	assert.Equal(t, middleware.ErrInvalidRateLimitConfig, err)
+
+	cfg = createRateLimitConfig()
+	cfg.Write.Burst = 0
+	rl, err = middleware.NewRateLimiter(cfg)
+	assert.Nil(t, rl)
+	assert.Equal(t, middleware.ErrInvalidRateLimitConfig, err)
+
+	cfg = createRateLimitConfig()
+	cfg.MaxClients = 0
+	rl, err = middleware.NewRateLimiter(cfg)
+	assert.Nil(t, rl)
+	assert.Equal(t, middleware.ErrInvalidRateLimitConfig, err)
+
+	cfg = createRateLimitConfig()
+	cfg.TrustedProxies = []string{"not an address"}
+	rl, err = middleware.NewRateLimiter(cfg)
+	assert.Nil(t, rl)
+	assert.True(t, errors.Is(err, middleware.ErrInvalidRateLimitConfig))
+}
+
+func TestRateLimiter_ExhaustedBucketShouldRespondTooManyRequests(t *testing.T) {
+	t.Parallel()
+
+	rl, _ := middleware.NewRateLimiter(createRateLimitConfig())
+	ws := startRateLimitedServer(rl)
+
+	assert.Equal(t, http.StatusOK, doRequestFrom(ws, "GET", "/address/aa", "1.1.1.1:1000").Code)
+	assert.Equal(t, http.StatusOK, doRequestFrom(ws, "GET", "/address/aa", "1.1.1.1:1000").Code)
+
+	resp := doRequestFrom(ws, "GET", "/address/aa", "1.1.1.1:1000")
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+	assert.Equal(t, "10", resp.Header().Get("Retry-After"))
+}
+
+func TestRateLimiter_ReadsAndWritesShouldHaveSeparateBuckets(t *testing.T) {
+	t.Parallel()
+
+	rl, _ := middleware.NewRateLimiter(createRateLimitConfig())
+	ws := startRateLimitedServer(rl)
+
+	assert.Equal(t, http.StatusOK, doRequestFrom(ws, "POST", "/transaction/send", "1.1.1.1:1000").Code)
+	assert.Equal(t, http.StatusTooManyRequests, doRequestFrom(ws, "POST", "/transaction/send", "1.1.1.1:1000").Code)
+	assert.Equal(t, http.StatusOK, doRequestFrom(ws, "GET", "/address/aa", "1.1.1.1:1000").Code)
+}
+
+func TestRateLimiter_SimulateShouldBeARead(t *testing.T) {
+	t.Parallel()
+
+	rl, _ := middleware.NewRateLimiter(createRateLimitConfig())
+	ws := startRateLimitedServer(rl)
+
+	assert.Equal(t, http.StatusOK, doRequestFrom(ws, "POST", "/transaction/send", "1.1.1.1:1000").Code)
+	assert.Equal(t, http.StatusOK, doRequestFrom(ws, "POST", "/transaction/simulate", "1.1.1.1:1000").Code)
+	assert.Equal(t, http.StatusOK, doRequestFrom(ws, "POST", "/transaction/simulate", "1.1.1.1:1000").Code)
+	assert.Equal(t, http.StatusTooManyRequests, doRequestFrom(ws, "POST", "/transaction/simulate", "1.1.1.1:1000").Code)
+}
+
+func TestRateLimiter_SendMultipleShouldCostOneTokenPerTransaction(t *testing.T) {
+	t.Parallel()
+
+	cfg := createRateLimitConfig()
+	cfg.Write.Burst = 3
+	rl, _ := middleware.NewRateLimiter(cfg)
+	ws := startRateLimitedServer(rl)
+
+	assert.Equal(t, http.StatusOK, doRequestFrom(ws, "POST", "/transaction/send-multiple?txs=2", "1.1.1.1:1000").Code)
+
+	resp := doRequestFrom(ws, "POST", "/transaction/send-multiple?txs=2", "1.1.1.1:1000")
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+	assert.NotEmpty(t, resp.Header().Get("Retry-After"))
+}
+
+func TestRateLimiter_SendMultipleOverTheBurstShouldRespondRequestEntityTooLarge(t *testing.T) {
+	t.Parallel()
+
+	cfg := createRateLimitConfig()
+	cfg.Write.Burst = 3
+	rl, _ := middleware.NewRateLimiter(cfg)
+	ws := startRateLimitedServer(rl)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, doRequestFrom(ws, "POST", "/transaction/send-multiple?txs=4", "1.1.1.1:1000").Code)
+	assert.Equal(t, http.StatusOK, doRequestFrom(ws, "POST", "/transaction/send-multiple?txs=3", "2.2.2.2:1000").Code)
+}
+
+func TestRateLimiter_ClientsShouldHaveSeparateBuckets(t *testing.T) {
+	t.Parallel()
+
+	rl, _ := middleware.NewRateLimiter(createRateLimitConfig())
+	ws := startRateLimitedServer(rl)
+
+	assert.Equal(t, http.StatusOK, doRequestFrom(ws, "POST", "/transaction/send", "1.1.1.1:1000").Code)
+	assert.Equal(t, http.StatusOK, doRequestFrom(ws, "POST", "/transaction/send", "2.2.2.2:1000").Code)
+	assert.Equal(t, http.StatusTooManyRequests, doRequestFrom(ws, "POST", "/transaction/send", "1.1.1.1:2000").Code)
+}
+
+func TestRateLimiter_ForwardedForFromUntrustedPeerShouldBeIgnored(t *testing.T) {
+	t.Parallel()
+
+	rl, _ := middleware.NewRateLimiter(createRateLimitConfig())
+	ws := startRateLimitedServer(rl)
+
+	resp := doRequestWithHeader(ws, "POST", "/transaction/send", "1.1.1.1:1000", "X-Forwarded-For", "3.3.3.3")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	resp = doRequestWithHeader(ws, "POST", "/transaction/send", "1.1.1.1:1000", "X-Forwarded-For", "4.4.4.4")
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+}
+
+func TestRateLimiter_ForwardedForFromTrustedProxyShouldBeUsed(t *testing.T) {
+	t.Parallel()
+
+	cfg := createRateLimitConfig()
+	cfg.TrustedProxies = []string{"10.0.0.0/8", "192.168.1.1"}
+	rl, _ := middleware.NewRateLimiter(cfg)
+	ws := startRateLimitedServer(rl)
+
+	resp := doRequestWithHeader(ws, "POST", "/transaction/send", "10.0.0.1:1000", "X-Forwarded-For", "3.3.3.3")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	resp = doRequestWithHeader(ws, "POST", "/transaction/send", "10.0.0.1:1000", "X-Forwarded-For", "4.4.4.4")
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	// the entries added by the client are not trusted, only the ones added by the trusted proxies
+	resp = doRequestWithHeader(ws, "POST", "/transaction/send", "10.0.0.2:1000", "X-Forwarded-For",
+		"5.5.5.5, 3.3.3.3, 192.168.1.1")
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+}
+
+func TestRateLimiter_UnauthenticatedApiKeyShouldNotSelectTheBucket(t *testing.T) {
+	t.Parallel()
+
+	cfg := createRateLimitConfig()
+	cfg.KeyBy = middleware.KeyByApiKey
+	rl, _ := middleware.NewRateLimiter(cfg)
+	ws := startRateLimitedServer(rl)
+
+	resp := doRequestWithHeader(ws, "POST", "/transaction/send", "1.1.1.1:1000", "X-Api-Key", "key1")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	resp = doRequestWithHeader(ws, "POST", "/transaction/send", "1.1.1.1:1000", "X-Api-Key", "key2")
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+}
+
+func TestRateLimiter_AuthenticatedApiKeyShouldSelectTheBucket(t *testing.T) {
+	t.Parallel()
+
+	cfg := createRateLimitConfig()
+	cfg.KeyBy = middleware.KeyByApiKey
+	rl, _ := middleware.NewRateLimiter(cfg)
+	a, _ := middleware.NewAuthenticator(createAuthenticationConfig(), createHexAddressCodec())
+	ws := startRateLimitedServer(rl, a.Handler())
+
+	resp := doRequestWithHeader(ws, "GET", "/address/aa", "1.1.1.1:1000", "X-Api-Key", "key-all")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	resp = doRequestWithHeader(ws, "GET", "/address/aa", "2.2.2.2:1000", "X-Api-Key", "key-all")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	resp = doRequestWithHeader(ws, "GET", "/address/aa", "3.3.3.3:1000", "X-Api-Key", "key-all")
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+	resp = doRequestWithHeader(ws, "GET", "/address/aa", "1.1.1.1:1000", "X-Api-Key", "key-readers")
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestRateLimiter_NewClientsOverMaxClientsShouldShareABucket(t *testing.T) {
+	t.Parallel()
+
+	cfg := createRateLimitConfig()
+	cfg.MaxClients = 1
+	rl, _ := middleware.NewRateLimiter(cfg)
+	ws := startRateLimitedServer(rl)
+
+	assert.Equal(t, http.StatusOK, doRequestFrom(ws, "POST", "/transaction/send", "1.1.1.1:1000").Code)
+	assert.Equal(t, http.StatusOK, doRequestFrom(ws, "POST", "/transaction/send", "2.2.2.2:1000").Code)
+	assert.Equal(t, http.StatusTooManyRequests, doRequestFrom(ws, "POST", "/transaction/send", "3.3.3.3:1000").Code)
+}
+
+func TestRateLimiter_SnapshotShouldRestoreBuckets(t *testing.T) {
+	t.Parallel()
+
+	cfg := createRateLimitConfig()
+	cfg.SnapshotFile = filepath.Join(t.TempDir(), "rateLimit.json")
+	cfg.SnapshotIntervalInSec = 60
+
+	rl, _ := middleware.NewRateLimiter(cfg)
+	ws := startRateLimitedServer(rl)
+	assert.Equal(t, http.StatusOK, doRequestFrom(ws, "POST", "/transaction/send", "1.1.1.1:1000").Code)
+	assert.Nil(t, rl.Close())
+	_, err := os.Stat(cfg.SnapshotFile)
+	assert.Nil(t, err)
+
+	rl, _ = middleware.NewRateLimiter(cfg)
+	ws = startRateLimitedServer(rl)
+	assert.Equal(t, http.StatusTooManyRequests, doRequestFrom(ws, "POST", "/transaction/send", "1.1.1.1:1000").Code)
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/numbatx/numbat-proxy/metrics"
+)
+
+// RequestMetrics records, for each route, the number, status codes and durations of the served requests
+type RequestMetrics struct {
+	*routeResolver
+	requests  *metrics.CounterVec
+	durations *metrics.HistogramVec
+}
+
+// NewRequestMetrics creates a new instance of RequestMetrics, registering its metrics in the provided registry
+func NewRequestMetrics(registry *metrics.Registry) (*RequestMetrics, error) {
+	if registry == nil {
+		return nil, ErrNilMetricsRegistry
+	}
+
+	requests, err := registry.NewCounterVec(
+		"numbat_proxy_requests_total",
+		"Number of requests served by the proxy, by route, method and status code",
+		"route", "method", "status",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	durations, err := registry.NewHistogramVec(
+		"numbat_proxy_request_duration_seconds",
+		"Duration of the requests served by the proxy, by route and method",
+		metrics.DefaultLatencyBuckets,
+		"route", "method",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RequestMetrics{
+		routeResolver: newRouteResolver(),
+		requests:      requests,
+		durations:     durations,
+	}, nil
+}
+
+// Handler returns the gin middleware recording the served requests. It should be the first middleware so
+// that the requests rejected by the other middlewares are recorded as well
+func (rm *RequestMetrics) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		method := c.Request.Method
+		route := rm.resolveRoute(c)
+		rm.requests.Inc(route, method, strconv.Itoa(c.Writer.Status()))
+		rm.durations.Observe(time.Since(start).Seconds(), route, method)
+	}
+}
@@ -0,0 +1,56 @@
+package middleware_test
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/numbatx/numbat-proxy/api/middleware"
+	"github.com/numbatx/numbat-proxy/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func getAccountHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+func sendTransactionHandler(c *gin.Context) {
+	c.JSON(http.StatusBadRequest, gin.H{})
+}
+
+func TestNewRequestMetrics_NilRegistryShouldErr(t *testing.T) {
+	t.Parallel()
+
+	rm, err := middleware.NewRequestMetrics(nil)
+
+	assert.Nil(t, rm)
+	assert.Equal(t, middleware.ErrNilMetricsRegistry, err)
+}
+
+func TestRequestMetrics_ShouldRecordRequestsByRoute(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.NewRegistry()
+	rm, _ := middleware.NewRequestMetrics(registry)
+	ws := gin.New()
+	ws.Use(rm.Handler())
+	ws.GET("/address/:address", getAccountHandler)
+	ws.POST("/transaction/send", sendTransactionHandler)
+	rm.SetRoutes(ws.Routes())
+
+	_ = doRequest(ws, "GET", "/address/aa", "")
+	_ = doRequest(ws, "GET", "/address/bb", "")
+	_ = doRequest(ws, "POST", "/transaction/send", "")
+	_ = doRequest(ws, "GET", "/missing", "")
+
+	buff := &bytes.Buffer{}
+	err := registry.Write(buff)
+	assert.Nil(t, err)
+
+	output := buff.String()
+	assert.Contains(t, output, `numbat_proxy_requests_total{route="/address/:address",method="GET",status="200"} 2`+"\n")
+	assert.Contains(t, output, `numbat_proxy_requests_total{route="/transaction/send",method="POST",status="400"} 1`+"\n")
+	assert.Contains(t, output, `numbat_proxy_requests_total{route="unmatched",method="GET",status="404"} 1`+"\n")
+	assert.Contains(t, output, `numbat_proxy_request_duration_seconds_count{route="/address/:address",method="GET"} 2`+"\n")
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UnmatchedRoute is the route label of the requests that did not match any registered route
+const UnmatchedRoute = "unmatched"
+
+// routeResolver finds the route pattern, such as "/address/:address", a request was served by. gin does not
+// expose it, so it is looked up by the method and the name of the route's handler
+type routeResolver struct {
+	mutRoutes sync.RWMutex
+	routes    map[string]string
+}
+
+func newRouteResolver() *routeResolver {
+	return &routeResolver{
+		routes: make(map[string]string),
+	}
+}
+
+// SetRoutes provides the registered routes, used to label the requests with their route pattern instead of
+// their path, so that the number of distinct labels stays bounded
+func (rr *routeResolver) SetRoutes(routes gin.RoutesInfo) {
+	newRoutes := make(map[string]string, len(routes))
+	for _, route := range routes {
+		newRoutes[route.Method+route.Handler] = route.Path
+	}
+
+	rr.mutRoutes.Lock()
+	rr.routes = newRoutes
+	rr.mutRoutes.Unlock()
+}
+
+func (rr *routeResolver) resolveRoute(c *gin.Context) string {
+	rr.mutRoutes.RLock()
+	defer rr.mutRoutes.RUnlock()
+
+	route, ok := rr.routes[c.Request.Method+c.HandlerName()]
+	if !ok {
+		return UnmatchedRoute
+	}
+
+	return route
+}
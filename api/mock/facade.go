@@ -1,25 +1,87 @@
 package mock
 
 import (
-	"math/big"
+	"context"
+	"time"
 
 	"github.com/numbatx/numbat-proxy/data"
+	"github.com/numbatx/numbat-proxy/process"
 )
 
 // Facade is the mock implementation of a node router handler
 type Facade struct {
-	GetAccountHandler      func(address string) (*data.Account, error)
-	SendTransactionHandler func(nonce uint64, sender string, receiver string, value *big.Int, code string, signature []byte) (string, error)
+	GetAccountHandler                     func(ctx context.Context, address string) (*data.Account, error)
+	SubscribeToAccountsHandler            func(addresses []string) (<-chan *data.AccountChange, func(), error)
+	ReserveNonceHandler                   func(ctx context.Context, address string) (*data.NonceReservation, error)
+	SendTransactionHandler                func(ctx context.Context, tx *data.Transaction) (*data.TransactionSendResult, error)
+	SendMultipleTransactionsHandler       func(ctx context.Context, txs []*data.Transaction) ([]*data.TransactionSendResult, error)
+	SimulateTransactionHandler            func(ctx context.Context, tx *data.Transaction) (*data.TransactionSimulationResult, error)
+	GetTransactionHandler                 func(ctx context.Context, txHash string, sender string, receiver string) (*data.TransactionDetails, error)
+	GetTransactionStatusHandler           func(ctx context.Context, txHash string, sender string, receiver string) (string, error)
+	GetCrossShardTransactionStatusHandler func(ctx context.Context, txHash string, sender string, receiver string) (*data.CrossShardTransactionStatus, error)
+	WaitForTransactionFinalityHandler     func(ctx context.Context, txHash string, sender string, receiver string, maxWait time.Duration) (*data.CrossShardTransactionStatus, error)
+	CheckReadinessHandler                 func(ctx context.Context) error
+	GetProxyStatusHandler                 func() *process.ProxyStatus
 }
 
 // GetAccount is the mock implementation of a handler's GetAccount method
-func (f *Facade) GetAccount(address string) (*data.Account, error) {
-	return f.GetAccountHandler(address)
+func (f *Facade) GetAccount(ctx context.Context, address string) (*data.Account, error) {
+	return f.GetAccountHandler(ctx, address)
+}
+
+// SubscribeToAccounts is the mock implementation of a handler's SubscribeToAccounts method
+func (f *Facade) SubscribeToAccounts(addresses []string) (<-chan *data.AccountChange, func(), error) {
+	return f.SubscribeToAccountsHandler(addresses)
+}
+
+// ReserveNonce is the mock implementation of a handler's ReserveNonce method
+func (f *Facade) ReserveNonce(ctx context.Context, address string) (*data.NonceReservation, error) {
+	return f.ReserveNonceHandler(ctx, address)
 }
 
 // SendTransaction is the mock implementation of a handler's SendTransaction method
-func (f *Facade) SendTransaction(nonce uint64, sender string, receiver string, value *big.Int, code string, signature []byte) (string, error) {
-	return f.SendTransactionHandler(nonce, sender, receiver, value, code, signature)
+func (f *Facade) SendTransaction(ctx context.Context, tx *data.Transaction) (*data.TransactionSendResult, error) {
+	return f.SendTransactionHandler(ctx, tx)
+}
+
+// SendMultipleTransactions is the mock implementation of a handler's SendMultipleTransactions method
+func (f *Facade) SendMultipleTransactions(ctx context.Context, txs []*data.Transaction) ([]*data.TransactionSendResult, error) {
+	return f.SendMultipleTransactionsHandler(ctx, txs)
+}
+
+// SimulateTransaction is the mock implementation of a handler's SimulateTransaction method
+func (f *Facade) SimulateTransaction(ctx context.Context, tx *data.Transaction) (*data.TransactionSimulationResult, error) {
+	return f.SimulateTransactionHandler(ctx, tx)
+}
+
+// GetTransaction is the mock implementation of a handler's GetTransaction method
+func (f *Facade) GetTransaction(ctx context.Context, txHash string, sender string, receiver string) (*data.TransactionDetails, error) {
+	return f.GetTransactionHandler(ctx, txHash, sender, receiver)
+}
+
+// GetTransactionStatus is the mock implementation of a handler's GetTransactionStatus method
+func (f *Facade) GetTransactionStatus(ctx context.Context, txHash string, sender string, receiver string) (string, error) {
+	return f.GetTransactionStatusHandler(ctx, txHash, sender, receiver)
+}
+
+// GetCrossShardTransactionStatus is the mock implementation of a handler's GetCrossShardTransactionStatus method
+func (f *Facade) GetCrossShardTransactionStatus(ctx context.Context, txHash string, sender string, receiver string) (*data.CrossShardTransactionStatus, error) {
+	return f.GetCrossShardTransactionStatusHandler(ctx, txHash, sender, receiver)
+}
+
+// WaitForTransactionFinality is the mock implementation of a handler's WaitForTransactionFinality method
+func (f *Facade) WaitForTransactionFinality(ctx context.Context, txHash string, sender string, receiver string, maxWait time.Duration) (*data.CrossShardTransactionStatus, error) {
+	return f.WaitForTransactionFinalityHandler(ctx, txHash, sender, receiver, maxWait)
+}
+
+// CheckReadiness is the mock implementation of a handler's CheckReadiness method
+func (f *Facade) CheckReadiness(ctx context.Context) error {
+	return f.CheckReadinessHandler(ctx)
+}
+
+// GetProxyStatus is the mock implementation of a handler's GetProxyStatus method
+func (f *Facade) GetProxyStatus() *process.ProxyStatus {
+	return f.GetProxyStatusHandler()
 }
 
 // WrongFacade is a struct that can be used as a wrong implementation of the node router handler
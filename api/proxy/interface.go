@@ -0,0 +1,8 @@
+package proxy
+
+import "github.com/numbatx/numbat-proxy/process"
+
+// FacadeHandler interface defines methods that can be used from `numbatProxyFacade` context variable
+type FacadeHandler interface {
+	GetProxyStatus() *process.ProxyStatus
+}
@@ -0,0 +1,25 @@
+package proxy
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/numbatx/numbat-proxy/api/errors"
+)
+
+// Routes defines the routes reporting the proxy's own state
+func Routes(router *gin.RouterGroup) {
+	router.GET("/status", GetProxyStatus)
+}
+
+// GetProxyStatus returns the loaded config, with the secrets redacted, the observers of each shard, the
+// number of shards, the version and the uptime of the proxy
+func GetProxyStatus(c *gin.Context) {
+	epf, ok := c.MustGet("numbatProxyFacade").(FacadeHandler)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errors.ErrInvalidAppContext.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": epf.GetProxyStatus()})
+}
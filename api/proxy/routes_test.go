@@ -0,0 +1,104 @@
+package proxy_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	"github.com/numbatx/numbat-proxy/api"
+	apiErrors "github.com/numbatx/numbat-proxy/api/errors"
+	"github.com/numbatx/numbat-proxy/api/mock"
+	"github.com/numbatx/numbat-proxy/api/proxy"
+	"github.com/numbatx/numbat-proxy/data"
+	"github.com/numbatx/numbat-proxy/process"
+	"github.com/stretchr/testify/assert"
+)
+
+type statusResponse struct {
+	Status *process.ProxyStatus `json:"status"`
+	Error  string               `json:"error"`
+}
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func startNodeServerWrongFacade() *gin.Engine {
+	ws := gin.New()
+	ws.Use(cors.Default())
+	ws.Use(func(c *gin.Context) {
+		c.Set("numbatProxyFacade", mock.WrongFacade{})
+	})
+	proxyRoutes := ws.Group("/proxy")
+	proxy.Routes(proxyRoutes)
+	return ws
+}
+
+func startNodeServer(handler proxy.FacadeHandler) *gin.Engine {
+	ws := gin.New()
+	ws.Use(cors.Default())
+	proxyRoutes := ws.Group("/proxy")
+	if handler != nil {
+		proxyRoutes.Use(api.WithNumbatProxyFacade(handler))
+	}
+	proxy.Routes(proxyRoutes)
+	return ws
+}
+
+func loadResponse(rsp io.Reader, destination interface{}) {
+	jsonParser := json.NewDecoder(rsp)
+	err := jsonParser.Decode(destination)
+	if err != nil {
+		fmt.Println(err)
+	}
+}
+
+func TestGetProxyStatus_FailsWithWrongFacadeTypeConversion(t *testing.T) {
+	t.Parallel()
+
+	ws := startNodeServerWrongFacade()
+	req, _ := http.NewRequest("GET", "/proxy/status", nil)
+	resp := httptest.NewRecorder()
+	ws.ServeHTTP(resp, req)
+
+	response := statusResponse{}
+	loadResponse(resp.Body, &response)
+
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.Equal(t, apiErrors.ErrInvalidAppContext.Error(), response.Error)
+}
+
+func TestGetProxyStatus_ShouldReturnStatus(t *testing.T) {
+	t.Parallel()
+
+	facade := &mock.Facade{
+		GetProxyStatusHandler: func() *process.ProxyStatus {
+			return &process.ProxyStatus{
+				Version:     "v1.0.0",
+				UptimeInSec: 42,
+				NumShards:   2,
+				Observers: map[uint32][]*data.Observer{
+					1: {{Address: "http://observer:8080", ShardId: 1}},
+				},
+			}
+		},
+	}
+	ws := startNodeServer(facade)
+	req, _ := http.NewRequest("GET", "/proxy/status", nil)
+	resp := httptest.NewRecorder()
+	ws.ServeHTTP(resp, req)
+
+	response := statusResponse{}
+	loadResponse(resp.Body, &response)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "v1.0.0", response.Status.Version)
+	assert.Equal(t, int64(42), response.Status.UptimeInSec)
+	assert.Equal(t, uint32(2), response.Status.NumShards)
+	assert.Equal(t, "http://observer:8080", response.Status.Observers[1][0].Address)
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/numbatx/numbat-proxy/api/errors"
+	"github.com/numbatx/numbat-proxy/config"
+)
+
+// newServerTlsConfig returns the TLS settings of the listener or nil when TLS is disabled
+func newServerTlsConfig(cfg config.ServerTlsConfig) (*tls.Config, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, errors.ErrInvalidTlsConfig
+	}
+
+	cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errors.ErrInvalidTlsConfig, err)
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}
+
+	if cfg.ClientCaCertFile == "" {
+		if cfg.RequireClientCert {
+			return nil, errors.ErrInvalidTlsConfig
+		}
+		return tlsConfig, nil
+	}
+
+	clientCaCerts, err := os.ReadFile(cfg.ClientCaCertFile)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errors.ErrInvalidTlsConfig, err)
+	}
+
+	clientCaCertPool := x509.NewCertPool()
+	if !clientCaCertPool.AppendCertsFromPEM(clientCaCerts) {
+		return nil, fmt.Errorf("%w: no certificate found in %s", errors.ErrInvalidTlsConfig, cfg.ClientCaCertFile)
+	}
+
+	tlsConfig.ClientCAs = clientCaCertPool
+	tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	if cfg.RequireClientCert {
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, nil
+}
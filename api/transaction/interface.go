@@ -1,10 +1,19 @@
 package transaction
 
 import (
-	"math/big"
+	"context"
+	"time"
+
+	"github.com/numbatx/numbat-proxy/data"
 )
 
 // FacadeHandler interface defines methods that can be used from `numbatProxyFacade` context variable
 type FacadeHandler interface {
-	SendTransaction(nonce uint64, sender string, receiver string, value *big.Int, code string, signature []byte) (string, error)
+	SendTransaction(ctx context.Context, tx *data.Transaction) (*data.TransactionSendResult, error)
+	SendMultipleTransactions(ctx context.Context, txs []*data.Transaction) ([]*data.TransactionSendResult, error)
+	SimulateTransaction(ctx context.Context, tx *data.Transaction) (*data.TransactionSimulationResult, error)
+	GetTransaction(ctx context.Context, txHash string, sender string, receiver string) (*data.TransactionDetails, error)
+	GetTransactionStatus(ctx context.Context, txHash string, sender string, receiver string) (string, error)
+	GetCrossShardTransactionStatus(ctx context.Context, txHash string, sender string, receiver string) (*data.CrossShardTransactionStatus, error)
+	WaitForTransactionFinality(ctx context.Context, txHash string, sender string, receiver string, maxWait time.Duration) (*data.CrossShardTransactionStatus, error)
 }
@@ -4,15 +4,24 @@ import (
 	"encoding/hex"
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/numbatx/numbat-proxy/api/errors"
+	"github.com/numbatx/numbat-proxy/api/middleware"
 	"github.com/numbatx/numbat-proxy/data"
 )
 
 // Routes defines transaction related routes
 func Routes(router *gin.RouterGroup) {
 	router.POST("/send", SendTransaction)
+	router.POST("/send-multiple", SendMultipleTransactions)
+	router.POST("/simulate", SimulateTransaction)
+	router.GET("/:txhash", GetTransaction)
+	router.GET("/:txhash/status", GetTransactionStatus)
+	router.GET("/:txhash/cross-shard-status", GetCrossShardTransactionStatus)
+	router.GET("/:txhash/wait-final", WaitForTransactionFinality)
 }
 
 // SendTransaction will receive a transaction from the client and propagate it for processing
@@ -30,17 +39,216 @@ func SendTransaction(c *gin.Context) {
 		return
 	}
 
-	signature, err := hex.DecodeString(gtx.Signature)
+	_, err = hex.DecodeString(gtx.Signature)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s: %s", errors.ErrInvalidSignatureHex.Error(), err.Error())})
 		return
 	}
+	if !isSenderAllowed(c, gtx.Sender) {
+		c.JSON(http.StatusForbidden, gin.H{"error": middleware.ErrSenderNotAllowed.Error()})
+		return
+	}
+
+	result, err := ef.SendTransaction(c.Request.Context(), &gtx)
+	if err != nil {
+		response := gin.H{"error": fmt.Sprintf("%s: %s", errors.ErrTxGenerationFailed.Error(), err.Error())}
+		field, isFieldInvalid := errors.InvalidTransactionField(err)
+		if isFieldInvalid {
+			response["field"] = field
+		}
+		nonceCheck, isNonceRejected := errors.RejectedNonceCheck(err)
+		if isNonceRejected {
+			response["nonceCheck"] = nonceCheck
+		}
+
+		c.JSON(errors.ResponseStatusCode(err), response)
+		return
+	}
+
+	response := gin.H{"txHash": result.TxHash}
+	if result.NonceCheck != nil {
+		response["nonceCheck"] = result.NonceCheck
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// SendMultipleTransactions will receive a list of transactions from the client and propagate them for processing.
+// The response holds, in the same order as the request, the hash or the error of each transaction
+func SendMultipleTransactions(c *gin.Context) {
+	ef, ok := c.MustGet("numbatProxyFacade").(FacadeHandler)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errors.ErrInvalidAppContext.Error()})
+		return
+	}
+
+	var txs []*data.Transaction
+	err := c.ShouldBindJSON(&txs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s: %s", errors.ErrValidation.Error(), err.Error())})
+		return
+	}
+	if len(txs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s: %s", errors.ErrValidation.Error(), errors.ErrEmptyTxList.Error())})
+		return
+	}
+
+	for idx, tx := range txs {
+		_, err = hex.DecodeString(tx.Signature)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s: transaction %d: %s", errors.ErrInvalidSignatureHex.Error(), idx, err.Error())})
+			return
+		}
+		if !isSenderAllowed(c, tx.Sender) {
+			c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("%s: transaction %d", middleware.ErrSenderNotAllowed.Error(), idx)})
+			return
+		}
+	}
+
+	// the rate limiter consumed a single write token for the request, each transaction costs one
+	if !middleware.TakeWriteTokens(c, len(txs)-1) {
+		return
+	}
+
+	results, err := ef.SendMultipleTransactions(c.Request.Context(), txs)
+	if err != nil {
+		c.JSON(errors.ResponseStatusCode(err), gin.H{"error": fmt.Sprintf("%s: %s", errors.ErrTxGenerationFailed.Error(), err.Error())})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
+
+// SimulateTransaction will receive a transaction from the client and return the outcome of its execution on
+// an observer. The transaction is not broadcast
+func SimulateTransaction(c *gin.Context) {
+	ef, ok := c.MustGet("numbatProxyFacade").(FacadeHandler)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errors.ErrInvalidAppContext.Error()})
+		return
+	}
+
+	var gtx = data.Transaction{}
+	err := c.ShouldBindJSON(&gtx)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s: %s", errors.ErrValidation.Error(), err.Error())})
+		return
+	}
+
+	_, err = hex.DecodeString(gtx.Signature)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s: %s", errors.ErrInvalidSignatureHex.Error(), err.Error())})
+		return
+	}
+
+	simulation, err := ef.SimulateTransaction(c.Request.Context(), &gtx)
+	if err != nil {
+		response := gin.H{"error": err.Error()}
+		field, isFieldInvalid := errors.InvalidTransactionField(err)
+		if isFieldInvalid {
+			response["field"] = field
+		}
+
+		c.JSON(errors.ResponseStatusCode(err), response)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"simulation": simulation})
+}
+
+// isSenderAllowed returns false if the request was authenticated with an API key that can not send
+// transactions from the sender's address
+func isSenderAllowed(c *gin.Context, sender string) bool {
+	policy, isAuthenticated := middleware.GetApiKeyPolicy(c)
+
+	return !isAuthenticated || policy.IsSenderAllowed(sender)
+}
+
+// GetTransaction returns the transaction with the provided hash. The optional sender and receiver
+// query parameters restrict the lookup to their shards
+func GetTransaction(c *gin.Context) {
+	ef, ok := c.MustGet("numbatProxyFacade").(FacadeHandler)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errors.ErrInvalidAppContext.Error()})
+		return
+	}
+
+	tx, err := ef.GetTransaction(c.Request.Context(), c.Param("txhash"), c.Query("sender"), c.Query("receiver"))
+	if err != nil {
+		c.JSON(errors.ResponseStatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"transaction": tx})
+}
+
+// GetTransactionStatus returns the status of the transaction with the provided hash. The optional sender
+// and receiver query parameters restrict the lookup to their shards. A transaction is pending only for the
+// observers reporting the transactions waiting in their pool; with the others, such a transaction is not
+// found until executed, while the cross-shard status reports it as pending
+func GetTransactionStatus(c *gin.Context) {
+	ef, ok := c.MustGet("numbatProxyFacade").(FacadeHandler)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errors.ErrInvalidAppContext.Error()})
+		return
+	}
+
+	status, err := ef.GetTransactionStatus(c.Request.Context(), c.Param("txhash"), c.Query("sender"), c.Query("receiver"))
+	if err != nil {
+		c.JSON(errors.ResponseStatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": status})
+}
+
+// GetCrossShardTransactionStatus returns the status of the transaction on both its sender's and receiver's
+// shards. The sender and receiver query parameters are mandatory. A transaction is reported as failed only
+// by the observers exposing the execution outcome in their transaction lookup; with the others, a
+// transaction whose execution failed can not be told apart from an executed one
+func GetCrossShardTransactionStatus(c *gin.Context) {
+	ef, ok := c.MustGet("numbatProxyFacade").(FacadeHandler)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errors.ErrInvalidAppContext.Error()})
+		return
+	}
+
+	status, err := ef.GetCrossShardTransactionStatus(c.Request.Context(), c.Param("txhash"), c.Query("sender"), c.Query("receiver"))
+	if err != nil {
+		c.JSON(errors.ResponseStatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": status})
+}
+
+// WaitForTransactionFinality holds the request until the transaction is final on both its sender's and
+// receiver's shards, failed, or the optional timeout query parameter (in seconds) elapsed. The last
+// known status is returned in all cases
+func WaitForTransactionFinality(c *gin.Context) {
+	ef, ok := c.MustGet("numbatProxyFacade").(FacadeHandler)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errors.ErrInvalidAppContext.Error()})
+		return
+	}
+
+	timeoutInSec, err := strconv.Atoi(c.DefaultQuery("timeout", "0"))
+	if err != nil || timeoutInSec < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s: %s", errors.ErrValidation.Error(), errors.ErrInvalidTimeout.Error())})
+		return
+	}
 
-	txHash, err := ef.SendTransaction(gtx.Nonce, gtx.Sender, gtx.Receiver, gtx.Value, gtx.Data, signature)
+	status, err := ef.WaitForTransactionFinality(
+		c.Request.Context(),
+		c.Param("txhash"),
+		c.Query("sender"),
+		c.Query("receiver"),
+		time.Duration(timeoutInSec)*time.Second,
+	)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("%s: %s", errors.ErrTxGenerationFailed.Error(), err.Error())})
+		c.JSON(errors.ResponseStatusCode(err), gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"txHash": txHash})
+	c.JSON(http.StatusOK, gin.H{"status": status})
 }
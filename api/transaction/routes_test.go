@@ -2,6 +2,7 @@ package transaction_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,13 +11,18 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/numbatx/numbat-proxy/api"
 	apiErrors "github.com/numbatx/numbat-proxy/api/errors"
+	"github.com/numbatx/numbat-proxy/api/middleware"
 	"github.com/numbatx/numbat-proxy/api/mock"
 	"github.com/numbatx/numbat-proxy/api/transaction"
+	"github.com/numbatx/numbat-proxy/config"
+	"github.com/numbatx/numbat-proxy/data"
+	"github.com/numbatx/numbat-proxy/process"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -151,9 +157,8 @@ func TestSendTransaction_ErrorWhenFacadeSendTransactionError(t *testing.T) {
 	errorString := "send transaction error"
 
 	facade := mock.Facade{
-		SendTransactionHandler: func(nonce uint64, sender string, receiver string, value *big.Int,
-			code string, signature []byte) (string, error) {
-			return "", errors.New(errorString)
+		SendTransactionHandler: func(ctx context.Context, tx *data.Transaction) (*data.TransactionSendResult, error) {
+			return nil, errors.New(errorString)
 		},
 	}
 	ws := startNodeServer(&facade)
@@ -177,6 +182,174 @@ func TestSendTransaction_ErrorWhenFacadeSendTransactionError(t *testing.T) {
 	assert.Contains(t, response.Error, errorString)
 }
 
+func TestSendTransaction_InvalidTransactionFieldShouldReturn400WithField(t *testing.T) {
+	t.Parallel()
+
+	facade := mock.Facade{
+		SendTransactionHandler: func(ctx context.Context, tx *data.Transaction) (*data.TransactionSendResult, error) {
+			return nil, &process.TransactionFieldError{Field: "gasLimit", Err: errors.New("missing value")}
+		},
+	}
+	ws := startNodeServer(&facade)
+
+	jsonStr := `{"sender":"aa","receiver":"bb","value":10,"signature":"aabbccdd"}`
+	req, _ := http.NewRequest("POST", "/transaction/send", bytes.NewBuffer([]byte(jsonStr)))
+
+	resp := httptest.NewRecorder()
+	ws.ServeHTTP(resp, req)
+
+	response := struct {
+		Error string `json:"error"`
+		Field string `json:"field"`
+	}{}
+	loadResponse(resp.Body, &response)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, "gasLimit", response.Field)
+	assert.Contains(t, response.Error, "invalid transaction: gasLimit: missing value")
+}
+
+func TestSendTransaction_RejectedNonceShouldReturn400WithNonceCheck(t *testing.T) {
+	t.Parallel()
+
+	nonceCheck := &data.NonceCheck{
+		Status:           data.NonceStatusTooLow,
+		AccountNonce:     5,
+		TransactionNonce: 2,
+		Message:          "nonce 2 is lower than the sender's current nonce 5",
+	}
+	facade := mock.Facade{
+		SendTransactionHandler: func(ctx context.Context, tx *data.Transaction) (*data.TransactionSendResult, error) {
+			return nil, &process.NonceError{Check: nonceCheck}
+		},
+	}
+	ws := startNodeServer(&facade)
+
+	jsonStr := `{"nonce":2,"sender":"aa","receiver":"bb","value":10,"signature":"aabbccdd"}`
+	req, _ := http.NewRequest("POST", "/transaction/send", bytes.NewBuffer([]byte(jsonStr)))
+
+	resp := httptest.NewRecorder()
+	ws.ServeHTTP(resp, req)
+
+	response := struct {
+		Error      string           `json:"error"`
+		NonceCheck *data.NonceCheck `json:"nonceCheck"`
+	}{}
+	loadResponse(resp.Body, &response)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Contains(t, response.Error, process.ErrInvalidNonce.Error())
+	assert.Equal(t, nonceCheck, response.NonceCheck)
+}
+
+func TestSendTransaction_ShouldReturnTheNonceCheck(t *testing.T) {
+	t.Parallel()
+
+	nonceCheck := &data.NonceCheck{Status: data.NonceStatusTooHigh, AccountNonce: 5, TransactionNonce: 500}
+	facade := mock.Facade{
+		SendTransactionHandler: func(ctx context.Context, tx *data.Transaction) (*data.TransactionSendResult, error) {
+			return &data.TransactionSendResult{TxHash: "tx hash", NonceCheck: nonceCheck}, nil
+		},
+	}
+	ws := startNodeServer(&facade)
+
+	jsonStr := `{"nonce":500,"sender":"aa","receiver":"bb","value":10,"signature":"aabbccdd"}`
+	req, _ := http.NewRequest("POST", "/transaction/send", bytes.NewBuffer([]byte(jsonStr)))
+
+	resp := httptest.NewRecorder()
+	ws.ServeHTTP(resp, req)
+
+	response := struct {
+		TxHash     string           `json:"txHash"`
+		NonceCheck *data.NonceCheck `json:"nonceCheck"`
+	}{}
+	loadResponse(resp.Body, &response)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "tx hash", response.TxHash)
+	assert.Equal(t, nonceCheck, response.NonceCheck)
+}
+
+func TestSimulateTransaction_ReturnsSuccessfully(t *testing.T) {
+	t.Parallel()
+
+	var receivedTx *data.Transaction
+	facade := mock.Facade{
+		SimulateTransactionHandler: func(ctx context.Context, tx *data.Transaction) (*data.TransactionSimulationResult, error) {
+			receivedTx = tx
+			return &data.TransactionSimulationResult{Status: "fail", GasUsed: 100, Error: "out of gas"}, nil
+		},
+	}
+	ws := startNodeServer(&facade)
+
+	jsonStr := `{"nonce":2,"sender":"aa","receiver":"bb","value":10,"gasLimit":100,"signature":"aabbccdd"}`
+	req, _ := http.NewRequest("POST", "/transaction/simulate", bytes.NewBuffer([]byte(jsonStr)))
+
+	resp := httptest.NewRecorder()
+	ws.ServeHTTP(resp, req)
+
+	response := struct {
+		Error      string                            `json:"error"`
+		Simulation *data.TransactionSimulationResult `json:"simulation"`
+	}{}
+	loadResponse(resp.Body, &response)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Empty(t, response.Error)
+	assert.Equal(t, &data.TransactionSimulationResult{Status: "fail", GasUsed: 100, Error: "out of gas"}, response.Simulation)
+	assert.Equal(t, uint64(2), receivedTx.Nonce)
+	assert.Equal(t, big.NewInt(100), receivedTx.GasLimit)
+}
+
+func TestSimulateTransaction_InvalidTransactionFieldShouldReturn400WithField(t *testing.T) {
+	t.Parallel()
+
+	facade := mock.Facade{
+		SimulateTransactionHandler: func(ctx context.Context, tx *data.Transaction) (*data.TransactionSimulationResult, error) {
+			return nil, &process.TransactionFieldError{Field: "value", Err: errors.New("missing value")}
+		},
+	}
+	ws := startNodeServer(&facade)
+
+	jsonStr := `{"sender":"aa","receiver":"bb","signature":"aabbccdd"}`
+	req, _ := http.NewRequest("POST", "/transaction/simulate", bytes.NewBuffer([]byte(jsonStr)))
+
+	resp := httptest.NewRecorder()
+	ws.ServeHTTP(resp, req)
+
+	response := struct {
+		Error string `json:"error"`
+		Field string `json:"field"`
+	}{}
+	loadResponse(resp.Body, &response)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, "value", response.Field)
+}
+
+func TestSimulateTransaction_NotSupportedByObserversShouldReturn501(t *testing.T) {
+	t.Parallel()
+
+	facade := mock.Facade{
+		SimulateTransactionHandler: func(ctx context.Context, tx *data.Transaction) (*data.TransactionSimulationResult, error) {
+			return nil, process.ErrSimulationNotSupported
+		},
+	}
+	ws := startNodeServer(&facade)
+
+	jsonStr := `{"nonce":2,"sender":"aa","receiver":"bb","value":10,"gasLimit":100,"signature":"aabbccdd"}`
+	req, _ := http.NewRequest("POST", "/transaction/simulate", bytes.NewBuffer([]byte(jsonStr)))
+
+	resp := httptest.NewRecorder()
+	ws.ServeHTTP(resp, req)
+
+	response := GeneralResponse{}
+	loadResponse(resp.Body, &response)
+
+	assert.Equal(t, http.StatusNotImplemented, resp.Code)
+	assert.Equal(t, process.ErrSimulationNotSupported.Error(), response.Error)
+}
+
 func TestSendTransaction_ReturnsSuccessfully(t *testing.T) {
 	t.Parallel()
 
@@ -189,9 +362,8 @@ func TestSendTransaction_ReturnsSuccessfully(t *testing.T) {
 	txHash := "tx hash"
 
 	facade := mock.Facade{
-		SendTransactionHandler: func(nonce uint64, sender string, receiver string, value *big.Int,
-			code string, signature []byte) (string, error) {
-			return txHash, nil
+		SendTransactionHandler: func(ctx context.Context, tx *data.Transaction) (*data.TransactionSendResult, error) {
+			return &data.TransactionSendResult{TxHash: txHash}, nil
 		},
 	}
 	ws := startNodeServer(&facade)
@@ -217,3 +389,295 @@ func TestSendTransaction_ReturnsSuccessfully(t *testing.T) {
 	assert.Empty(t, response.Error)
 	assert.Equal(t, txHash, response.TxHash)
 }
+
+func TestSendTransaction_ShouldForwardAllFieldsToFacade(t *testing.T) {
+	t.Parallel()
+
+	var receivedTx *data.Transaction
+	facade := mock.Facade{
+		SendTransactionHandler: func(ctx context.Context, tx *data.Transaction) (*data.TransactionSendResult, error) {
+			receivedTx = tx
+			return &data.TransactionSendResult{TxHash: "tx hash"}, nil
+		},
+	}
+	ws := startNodeServer(&facade)
+
+	jsonStr := `{
+		"nonce": 1,
+		"sender": "sender",
+		"receiver": "receiver",
+		"value": 10,
+		"gasPrice": 100,
+		"gasLimit": 1000,
+		"signature": "aabbccdd",
+		"challenge": "challenge",
+		"data": "data"
+	}`
+
+	req, _ := http.NewRequest("POST", "/transaction/send", bytes.NewBuffer([]byte(jsonStr)))
+
+	resp := httptest.NewRecorder()
+	ws.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, &data.Transaction{
+		Nonce:     1,
+		Value:     big.NewInt(10),
+		Receiver:  "receiver",
+		Sender:    "sender",
+		GasPrice:  big.NewInt(100),
+		GasLimit:  big.NewInt(1000),
+		Data:      "data",
+		Signature: "aabbccdd",
+		Challenge: "challenge",
+	}, receivedTx)
+}
+
+//------- SendMultipleTransactions
+
+func TestSendMultipleTransactions_EmptyListShouldError(t *testing.T) {
+	t.Parallel()
+
+	facade := mock.Facade{}
+	ws := startNodeServer(&facade)
+
+	req, _ := http.NewRequest("POST", "/transaction/send-multiple", bytes.NewBuffer([]byte("[]")))
+	resp := httptest.NewRecorder()
+	ws.ServeHTTP(resp, req)
+
+	response := GeneralResponse{}
+	loadResponse(resp.Body, &response)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Contains(t, response.Error, apiErrors.ErrEmptyTxList.Error())
+}
+
+func TestSendMultipleTransactions_InvalidHexSignatureShouldError(t *testing.T) {
+	t.Parallel()
+
+	facade := mock.Facade{}
+	ws := startNodeServer(&facade)
+
+	jsonStr := `[{"sender": "sender", "signature": "aabb"}, {"sender": "sender", "signature": "not hex"}]`
+	req, _ := http.NewRequest("POST", "/transaction/send-multiple", bytes.NewBuffer([]byte(jsonStr)))
+	resp := httptest.NewRecorder()
+	ws.ServeHTTP(resp, req)
+
+	response := GeneralResponse{}
+	loadResponse(resp.Body, &response)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Contains(t, response.Error, apiErrors.ErrInvalidSignatureHex.Error())
+	assert.Contains(t, response.Error, "transaction 1")
+}
+
+func TestSendMultipleTransactions_ReturnsSuccessfully(t *testing.T) {
+	t.Parallel()
+
+	results := []*data.TransactionSendResult{
+		{TxHash: "hash0"},
+		{Error: "send error"},
+	}
+	facade := mock.Facade{
+		SendMultipleTransactionsHandler: func(ctx context.Context, txs []*data.Transaction) ([]*data.TransactionSendResult, error) {
+			return results, nil
+		},
+	}
+	ws := startNodeServer(&facade)
+
+	jsonStr := `[{"nonce": 0, "sender": "sender", "signature": "aabb"}, {"nonce": 1, "sender": "sender", "signature": "ccdd"}]`
+	req, _ := http.NewRequest("POST", "/transaction/send-multiple", bytes.NewBuffer([]byte(jsonStr)))
+	resp := httptest.NewRecorder()
+	ws.ServeHTTP(resp, req)
+
+	response := struct {
+		Results []*data.TransactionSendResult `json:"results"`
+	}{}
+	loadResponse(resp.Body, &response)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, results, response.Results)
+}
+
+//------- GetTransaction
+
+func TestGetTransaction_ShouldPassHintsAndReturnTransaction(t *testing.T) {
+	t.Parallel()
+
+	facade := mock.Facade{
+		GetTransactionHandler: func(ctx context.Context, txHash string, sender string, receiver string) (*data.TransactionDetails, error) {
+			return &data.TransactionDetails{
+				Transaction: data.Transaction{Sender: sender, Receiver: receiver},
+				Hash:        txHash,
+			}, nil
+		},
+	}
+	ws := startNodeServer(&facade)
+
+	req, _ := http.NewRequest("GET", "/transaction/aabb?sender=01&receiver=02", nil)
+	resp := httptest.NewRecorder()
+	ws.ServeHTTP(resp, req)
+
+	response := struct {
+		Transaction data.TransactionDetails `json:"transaction"`
+	}{}
+	loadResponse(resp.Body, &response)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "aabb", response.Transaction.Hash)
+	assert.Equal(t, "01", response.Transaction.Sender)
+	assert.Equal(t, "02", response.Transaction.Receiver)
+}
+
+func TestGetTransaction_NotFoundShouldReturn404(t *testing.T) {
+	t.Parallel()
+
+	facade := mock.Facade{
+		GetTransactionHandler: func(ctx context.Context, txHash string, sender string, receiver string) (*data.TransactionDetails, error) {
+			return nil, process.ErrTransactionNotFound
+		},
+	}
+	ws := startNodeServer(&facade)
+
+	req, _ := http.NewRequest("GET", "/transaction/aabb", nil)
+	resp := httptest.NewRecorder()
+	ws.ServeHTTP(resp, req)
+
+	response := GeneralResponse{}
+	loadResponse(resp.Body, &response)
+
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Equal(t, process.ErrTransactionNotFound.Error(), response.Error)
+}
+
+func TestGetTransactionStatus_ReturnsSuccessfully(t *testing.T) {
+	t.Parallel()
+
+	facade := mock.Facade{
+		GetTransactionStatusHandler: func(ctx context.Context, txHash string, sender string, receiver string) (string, error) {
+			return "executed", nil
+		},
+	}
+	ws := startNodeServer(&facade)
+
+	req, _ := http.NewRequest("GET", "/transaction/aabb/status", nil)
+	resp := httptest.NewRecorder()
+	ws.ServeHTTP(resp, req)
+
+	response := struct {
+		Status string `json:"status"`
+	}{}
+	loadResponse(resp.Body, &response)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "executed", response.Status)
+}
+
+//------- cross shard status
+
+func TestGetCrossShardTransactionStatus_MissingReceiverShouldReturn400(t *testing.T) {
+	t.Parallel()
+
+	facade := mock.Facade{
+		GetCrossShardTransactionStatusHandler: func(ctx context.Context, txHash string, sender string, receiver string) (*data.CrossShardTransactionStatus, error) {
+			return nil, process.ErrMissingSenderOrReceiver
+		},
+	}
+	ws := startNodeServer(&facade)
+
+	req, _ := http.NewRequest("GET", "/transaction/aabb/cross-shard-status?sender=01", nil)
+	resp := httptest.NewRecorder()
+	ws.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestWaitForTransactionFinality_InvalidTimeoutShouldReturn400(t *testing.T) {
+	t.Parallel()
+
+	ws := startNodeServer(&mock.Facade{})
+
+	req, _ := http.NewRequest("GET", "/transaction/aabb/wait-final?sender=01&receiver=02&timeout=abc", nil)
+	resp := httptest.NewRecorder()
+	ws.ServeHTTP(resp, req)
+
+	response := GeneralResponse{}
+	loadResponse(resp.Body, &response)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Contains(t, response.Error, apiErrors.ErrInvalidTimeout.Error())
+}
+
+func TestWaitForTransactionFinality_ReturnsSuccessfully(t *testing.T) {
+	t.Parallel()
+
+	var receivedMaxWait time.Duration
+	facade := mock.Facade{
+		WaitForTransactionFinalityHandler: func(ctx context.Context, txHash string, sender string, receiver string, maxWait time.Duration) (*data.CrossShardTransactionStatus, error) {
+			receivedMaxWait = maxWait
+			return &data.CrossShardTransactionStatus{
+				Status:            data.TxStatusFinal,
+				SourceShard:       1,
+				SourceStatus:      data.TxStatusExecuted,
+				DestinationShard:  2,
+				DestinationStatus: data.TxStatusExecuted,
+			}, nil
+		},
+	}
+	ws := startNodeServer(&facade)
+
+	req, _ := http.NewRequest("GET", "/transaction/aabb/wait-final?sender=01&receiver=02&timeout=10", nil)
+	resp := httptest.NewRecorder()
+	ws.ServeHTTP(resp, req)
+
+	response := struct {
+		Status data.CrossShardTransactionStatus `json:"status"`
+	}{}
+	loadResponse(resp.Body, &response)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, 10*time.Second, receivedMaxWait)
+	assert.Equal(t, data.TxStatusFinal, response.Status.Status)
+	assert.Equal(t, uint32(2), response.Status.DestinationShard)
+}
+
+//------- API key policies
+
+func TestSendTransaction_SenderNotAllowedForApiKeyShouldReturn403(t *testing.T) {
+	t.Parallel()
+
+	codec, _ := process.NewAddressCodec(config.AddressesConfig{OutputFormat: process.Bech32AddressFormat})
+	authenticator, _ := middleware.NewAuthenticator(config.AuthenticationConfig{
+		Header: "X-Api-Key",
+		Keys: []*config.ApiKeyConfig{
+			{Name: "team", Key: "key", AllowedSenders: []string{"aa"}},
+		},
+	}, codec)
+	facade := mock.Facade{
+		SendTransactionHandler: func(ctx context.Context, tx *data.Transaction) (*data.TransactionSendResult, error) {
+			return &data.TransactionSendResult{TxHash: "hash"}, nil
+		},
+	}
+	ws := gin.New()
+	transactionRoute := ws.Group("/transaction")
+	transactionRoute.Use(authenticator.Handler(), api.WithNumbatProxyFacade(&facade))
+	transaction.Routes(transactionRoute)
+
+	sendTx := func(sender string) *httptest.ResponseRecorder {
+		jsonStr := fmt.Sprintf(`{"sender":"%s","receiver":"bb","value":1,"signature":"aabb"}`, sender)
+		req, _ := http.NewRequest("POST", "/transaction/send", bytes.NewBuffer([]byte(jsonStr)))
+		req.Header.Set("X-Api-Key", "key")
+		resp := httptest.NewRecorder()
+		ws.ServeHTTP(resp, req)
+
+		return resp
+	}
+
+	assert.Equal(t, http.StatusOK, sendTx("aa").Code)
+	assert.Equal(t, http.StatusOK, sendTx(codec.EncodeAddress([]byte{0xaa})).Code)
+	resp := sendTx("cc")
+	response := GeneralResponse{}
+	loadResponse(resp.Body, &response)
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	assert.Equal(t, middleware.ErrSenderNotAllowed.Error(), response.Error)
+}
@@ -1,24 +1,37 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/numbatx/gn-numbat/core"
 	"github.com/numbatx/gn-numbat/core/logger"
 	"github.com/numbatx/gn-numbat/data/state/addressConverters"
 	"github.com/numbatx/numbat-proxy/api"
+	"github.com/numbatx/numbat-proxy/api/middleware"
 	"github.com/numbatx/numbat-proxy/config"
 	"github.com/numbatx/numbat-proxy/data"
 	"github.com/numbatx/numbat-proxy/facade"
+	"github.com/numbatx/numbat-proxy/metrics"
 	"github.com/numbatx/numbat-proxy/process"
 	"github.com/numbatx/numbat-proxy/testing"
 	"github.com/pkg/profile"
 	"github.com/urfave/cli"
 )
 
+// defaultShutdownTimeout bounds the draining of the in-flight requests when no timeout is configured
+const defaultShutdownTimeout = 30 * time.Second
+
+// addressLength is the length, in bytes, of the accounts' addresses
+const addressLength = 32
+
 var (
 	log *logger.Logger
 
@@ -84,13 +97,11 @@ func main() {
 		return startProxy(c)
 	}
 
-	defer func() {
-		if testServer != nil {
-			testServer.Close()
-		}
-	}()
-
 	err := app.Run(os.Args)
+	// the test server stands in for the observers, so it is closed only after the in-flight requests drained
+	if testServer != nil {
+		testServer.Close()
+	}
 	if err != nil {
 		log.Error(err.Error())
 		os.Exit(1)
@@ -123,29 +134,135 @@ func startProxy(ctx *cli.Context) error {
 	}
 	log.Info(fmt.Sprintf("Initialized with config from: %s", configurationFileName))
 
-	stop := make(chan bool, 1)
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
 
-	epf, err := createNumbatProxyFacade(ctx, generalConfig)
+	registry := metrics.NewRegistry()
+	components, err := createNumbatProxyFacade(ctx, generalConfig, registry)
 	if err != nil {
 		return err
 	}
+	components.accountsNotifier.Start()
+	defer components.accountsNotifier.Close()
 
-	startWebServer(epf, generalConfig.GeneralSettings.ServerPort)
+	middlewares := make([]gin.HandlerFunc, 0)
+	configAppliers := []process.ConfigApplier{components.baseProcessor}
+	authenticator, err := createAuthenticator(generalConfig.Authentication, components.addressCodec)
+	if err != nil {
+		return err
+	}
+	if authenticator != nil {
+		middlewares = append(middlewares, authenticator.Handler())
+		configAppliers = append(configAppliers, authenticator)
+	}
+
+	appliers, err := process.NewConfigAppliers(generalConfig, configAppliers...)
+	if err != nil {
+		return err
+	}
+	cfgWatcher, err := createConfigWatcher(appliers, configurationFileName, generalConfig.GeneralSettings.CfgFileReadInterval)
+	if err != nil {
+		return err
+	}
+	if cfgWatcher != nil {
+		cfgWatcher.Start()
+		defer cfgWatcher.Close()
+	}
+
+	healthChecker, err := createObserversHealthChecker(components.baseProcessor, generalConfig.HealthCheck)
+	if err != nil {
+		return err
+	}
+	if healthChecker != nil {
+		healthChecker.Start()
+		defer healthChecker.Close()
+	}
+
+	rateLimiter, err := createRateLimiter(generalConfig.RateLimit, authenticator != nil)
+	if err != nil {
+		return err
+	}
+	if rateLimiter != nil {
+		rateLimiter.Start()
+		defer func() {
+			log.LogIfError(rateLimiter.Close())
+		}()
+		middlewares = append(middlewares, rateLimiter.Handler())
+	}
+
+	accessLogWriter, err := createAccessLogWriter(generalConfig.AccessLog)
+	if err != nil {
+		return err
+	}
+	if accessLogWriter != nil {
+		defer func() {
+			log.LogIfError(accessLogWriter.Close())
+		}()
+	}
+
+	server, err := api.Start(
+		components.facade,
+		generalConfig.GeneralSettings.ServerPort,
+		generalConfig.ServerTls,
+		registry,
+		accessLogWriter,
+		middlewares...,
+	)
+	if err != nil {
+		return err
+	}
+	// the account streams and the finality waits would outlast the drain, so they end as soon as the shutdown begins
+	server.RegisterOnShutdown(components.accountsNotifier.Close)
+	server.RegisterOnShutdown(components.txProcessor.Close)
+
+	log.Info("Application is now running...")
+	<-sigs
+	log.Info("terminating at user's signal...")
 
 	go func() {
 		<-sigs
-		log.Info("terminating at user's signal...")
-		stop <- true
+		log.Warn("forcing the exit at user's second signal")
+		os.Exit(1)
 	}()
 
-	log.Info("Application is now running...")
-	<-stop
+	shutdownWebServer(server, components.statusProcessor, generalConfig.GeneralSettings)
 
 	return nil
 }
 
+// shutdownWebServer makes the readiness probe fail, keeps serving during the shutdown delay so the load
+// balancers notice, then stops accepting connections and waits for the in-flight requests to finish. The
+// requests still running when the shutdown timeout elapses are cut off. A second signal exits right away
+func shutdownWebServer(
+	server *http.Server,
+	statusProc *process.ProxyStatusProcessor,
+	cfg config.GeneralSettingsConfig,
+) {
+	statusProc.SetShuttingDown()
+	if cfg.ShutdownDelayInSec > 0 {
+		log.Info(fmt.Sprintf("waiting %d seconds before draining the in-flight requests...", cfg.ShutdownDelayInSec))
+		time.Sleep(time.Duration(cfg.ShutdownDelayInSec) * time.Second)
+	}
+
+	shutdownTimeout := defaultShutdownTimeout
+	if cfg.ShutdownTimeoutInSec > 0 {
+		shutdownTimeout = time.Duration(cfg.ShutdownTimeoutInSec) * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	log.Info("draining the in-flight requests...")
+	err := server.Shutdown(ctx)
+	if err != nil {
+		log.Warn(fmt.Sprintf("in-flight requests cut off after %v: %s", shutdownTimeout, err.Error()))
+		log.LogIfError(server.Close())
+		return
+	}
+
+	log.Info("all the in-flight requests finished")
+}
+
 func loadMainConfig(filepath string, log *logger.Logger) (*config.Config, error) {
 	cfg := &config.Config{}
 	err := core.LoadTomlFile(cfg, filepath, log)
@@ -155,10 +272,21 @@ func loadMainConfig(filepath string, log *logger.Logger) (*config.Config, error)
 	return cfg, nil
 }
 
+// proxyComponents holds the components the proxy needs beside the facade in order to run and shut down
+type proxyComponents struct {
+	facade           *facade.NumbatProxyFacade
+	baseProcessor    *process.BaseProcessor
+	accountsNotifier *process.AccountsNotifier
+	txProcessor      *process.TransactionProcessor
+	statusProcessor  *process.ProxyStatusProcessor
+	addressCodec     process.AddressCodec
+}
+
 func createNumbatProxyFacade(
 	ctx *cli.Context,
 	cfg *config.Config,
-) (*facade.NumbatProxyFacade, error) {
+	registry *metrics.Registry,
+) (*proxyComponents, error) {
 
 	var testHttpServerEnabled bool
 	if ctx.IsSet(testHttpServerEn.Name) {
@@ -179,19 +307,24 @@ func createNumbatProxyFacade(
 			},
 		}
 
-		return createFacade(testCfg)
+		return createFacade(testCfg, registry, ctx.App.Version)
 	}
 
-	return createFacade(cfg)
+	return createFacade(cfg, registry, ctx.App.Version)
 }
 
-func createFacade(cfg *config.Config) (*facade.NumbatProxyFacade, error) {
-	addrConv, err := addressConverters.NewPlainAddressConverter(32, "")
+func createFacade(
+	cfg *config.Config,
+	registry *metrics.Registry,
+	version string,
+) (*proxyComponents, error) {
+
+	addrConv, err := addressConverters.NewPlainAddressConverter(addressLength, "")
 	if err != nil {
 		return nil, err
 	}
 
-	bp, err := process.NewBaseProcessor(addrConv)
+	bp, err := process.NewBaseProcessor(addrConv, registry)
 	if err != nil {
 		return nil, err
 	}
@@ -201,22 +334,152 @@ func createFacade(cfg *config.Config) (*facade.NumbatProxyFacade, error) {
 		return nil, err
 	}
 
-	accntProc, err := process.NewAccountProcessor(bp)
+	addressCodec, err := process.NewAddressCodec(cfg.Addresses)
+	if err != nil {
+		return nil, err
+	}
+
+	accntProc, err := process.NewAccountProcessor(bp, addressCodec)
+	if err != nil {
+		return nil, err
+	}
+
+	accountsCache, err := process.NewAccountsCache(accntProc, addressCodec, cfg.AccountsCache)
+	if err != nil {
+		return nil, err
+	}
+
+	txValidator, err := process.NewTransactionValidator(addressCodec, addressLength, cfg.TransactionValidation)
+	if err != nil {
+		return nil, err
+	}
+
+	nonceChecker, err := process.NewNonceChecker(accountsCache, cfg.NonceCheck)
+	if err != nil {
+		return nil, err
+	}
+
+	txProc, err := process.NewTransactionProcessor(bp, addressCodec, txValidator, nonceChecker)
 	if err != nil {
 		return nil, err
 	}
 
-	txProc, err := process.NewTransactionProcessor(bp)
+	accountsNotifier, err := process.NewAccountsNotifier(accntProc, addressCodec, cfg.AccountsStream)
 	if err != nil {
 		return nil, err
 	}
 
-	return facade.NewNumbatProxyFacade(accntProc, txProc)
+	statusProc, err := process.NewProxyStatusProcessor(bp, version, cfg.HealthCheck.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.NonceAllocation.Enabled && !cfg.Authentication.Enabled {
+		return nil, fmt.Errorf("%w: the nonce allocation requires the authentication to be enabled",
+			process.ErrInvalidNonceAllocationConfig)
+	}
+	nonceAllocator, err := process.NewNonceAllocator(accntProc, addressCodec, cfg.NonceAllocation)
+	if err != nil {
+		return nil, err
+	}
+
+	epf, err := facade.NewNumbatProxyFacade(accountsCache, txProc, accountsNotifier, statusProc, nonceAllocator)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proxyComponents{
+		facade:           epf,
+		baseProcessor:    bp,
+		accountsNotifier: accountsNotifier,
+		txProcessor:      txProc,
+		statusProcessor:  statusProc,
+		addressCodec:     addressCodec,
+	}, nil
 }
 
-func startWebServer(proxyHandler api.NumbatProxyHandler, port int) {
-	go func() {
-		err := api.Start(proxyHandler, port)
-		log.LogIfError(err)
-	}()
+func createConfigWatcher(
+	proc process.ConfigApplier,
+	configurationFileName string,
+	cfgFileReadInterval int,
+) (*process.ConfigWatcher, error) {
+
+	if testServer != nil {
+		log.Info("Config file watching is disabled while the test HTTP server is running")
+		return nil, nil
+	}
+	if cfgFileReadInterval <= 0 {
+		log.Info("Config file watching is disabled")
+		return nil, nil
+	}
+
+	loadConfig := func(filePath string) (*config.Config, error) {
+		return loadMainConfig(filePath, log)
+	}
+
+	readInterval := time.Duration(cfgFileReadInterval) * time.Second
+	log.Info(fmt.Sprintf("Watching config file %s for changes every %v", configurationFileName, readInterval))
+
+	return process.NewConfigWatcher(proc, configurationFileName, loadConfig, readInterval)
+}
+
+func createObserversHealthChecker(
+	handler process.ObserversHealthHandler,
+	cfg config.HealthCheckConfig,
+) (*process.ObserversHealthChecker, error) {
+
+	if !cfg.Enabled {
+		log.Info("Observers health checking is disabled")
+		return nil, nil
+	}
+
+	return process.NewObserversHealthChecker(handler, cfg)
+}
+
+func createAuthenticator(
+	cfg config.AuthenticationConfig,
+	codec process.AddressCodec,
+) (*middleware.Authenticator, error) {
+
+	if !cfg.Enabled {
+		log.Info("API key authentication is disabled")
+		return nil, nil
+	}
+
+	return middleware.NewAuthenticator(cfg, codec)
+}
+
+func createRateLimiter(cfg config.RateLimitConfig, isAuthenticationEnabled bool) (*middleware.RateLimiter, error) {
+	if !cfg.Enabled {
+		log.Info("Rate limiting is disabled")
+		return nil, nil
+	}
+	if cfg.KeyBy == middleware.KeyByApiKey && !isAuthenticationEnabled {
+		return nil, fmt.Errorf("%w: keying by API key requires the authentication to be enabled",
+			middleware.ErrInvalidRateLimitConfig)
+	}
+
+	return middleware.NewRateLimiter(cfg)
+}
+
+// nopCloser lets the standard output be used as an access log writer without being closed on shutdown
+type nopCloser struct {
+	io.Writer
+}
+
+// Close does nothing
+func (nc nopCloser) Close() error {
+	return nil
+}
+
+func createAccessLogWriter(cfg config.AccessLogConfig) (io.WriteCloser, error) {
+	if !cfg.Enabled {
+		log.Info("JSON access log is disabled")
+		return nil, nil
+	}
+	if cfg.FilePath == "" {
+		return nopCloser{Writer: os.Stdout}, nil
+	}
+
+	return os.OpenFile(cfg.FilePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0640)
 }
@@ -4,12 +4,194 @@ import "github.com/numbatx/numbat-proxy/data"
 
 // GeneralSettingsConfig will hold the general settings for a node
 type GeneralSettingsConfig struct {
-	ServerPort          int
-	CfgFileReadInterval int
+	ServerPort           int
+	CfgFileReadInterval  int
+	ObserversSelection   string
+	ShutdownDelayInSec   int
+	ShutdownTimeoutInSec int
+}
+
+// AddressesConfig will hold the formats of the accounts' addresses. The requests can hold either hex or
+// bech32 addresses, the latter using Bech32Prefix, while the responses use OutputFormat, "hex" or "bech32".
+// The settings are only read at start
+type AddressesConfig struct {
+	OutputFormat string
+	Bech32Prefix string
+}
+
+// TransactionValidationConfig will hold the checks made on the transactions before relaying them to the
+// observers. The addresses, value, signature length and the presence of the gas fields are always checked
+// when enabled, while the zero bounds are not. VerifySignature also checks the signatures as the observers do
+type TransactionValidationConfig struct {
+	Enabled         bool
+	MinGasPrice     uint64
+	MaxGasPrice     uint64
+	MinGasLimit     uint64
+	MaxGasLimit     uint64
+	MaxDataLength   int
+	VerifySignature bool
+}
+
+// NonceCheckConfig will hold the settings of the comparison between the nonce of each sent transaction and its
+// sender's current nonce. A nonce lower than the current one, or more than MaxNonceGap ahead of it, is reported
+// in the send response and, if RejectInvalidNonces is true, the transaction is not sent
+type NonceCheckConfig struct {
+	Enabled             bool
+	MaxNonceGap         uint64
+	RejectInvalidNonces bool
+}
+
+// NonceAllocationConfig will hold the settings of the nonces handed out by the proxy. A reserved nonce is held
+// for ReservationTTLInMs and the nonce of a relayed transaction for PendingTTLInMs, unless the sender's nonce
+// moves past it sooner. A sender can not have more than MaxNoncesPerSender tracked nonces and the nonces of at
+// most MaxSenders senders are tracked. It requires the authentication to be enabled
+type NonceAllocationConfig struct {
+	Enabled            bool
+	ReservationTTLInMs int
+	PendingTTLInMs     int
+	MaxNoncesPerSender int
+	MaxSenders         int
+}
+
+// AccessLogConfig will hold the settings of the JSON access log. The lines are written to FilePath or, if it
+// is empty, to the standard output
+type AccessLogConfig struct {
+	Enabled  bool
+	FilePath string
+}
+
+// HealthCheckConfig will hold the settings used when actively probing the observers
+type HealthCheckConfig struct {
+	Enabled           bool
+	Path              string
+	IntervalInSec     int
+	ProbeTimeoutInSec int
+	FailureThreshold  int
+	RecoveryThreshold int
+}
+
+// HttpClientConfig will hold the settings of the http clients used when calling the observers.
+// Zero values fall back to the proxy's defaults. The Tls files are used for the observers with an https
+// address: TlsCaCertFile replaces the system's CA bundle and TlsCertFile with TlsKeyFile are the client
+// certificate presented to the observers requiring mutual TLS
+type HttpClientConfig struct {
+	DialTimeoutInMs      int
+	RequestTimeoutInMs   int
+	MaxIdleConnsPerHost  int
+	IdleConnTimeoutInSec int
+	KeepAliveInSec       int
+	TlsCaCertFile        string
+	TlsCertFile          string
+	TlsKeyFile           string
+	ObserverOverrides    []*ObserverHttpClientConfig
+}
+
+// ObserverHttpClientConfig will hold the http client settings that override the general ones for
+// the observer with the same address. Zero values keep the general setting
+type ObserverHttpClientConfig struct {
+	Address              string
+	DialTimeoutInMs      int
+	RequestTimeoutInMs   int
+	MaxIdleConnsPerHost  int
+	IdleConnTimeoutInSec int
+	KeepAliveInSec       int
+	TlsCaCertFile        string
+	TlsCertFile          string
+	TlsKeyFile           string
+}
+
+// ServerTlsConfig will hold the settings of the TLS listener serving the proxy's clients. When
+// ClientCaCertFile is set, the client certificates are verified against it and, if RequireClientCert
+// is true, the clients without a certificate are rejected
+type ServerTlsConfig struct {
+	Enabled           bool
+	CertFile          string
+	KeyFile           string
+	ClientCaCertFile  string
+	RequireClientCert bool
+}
+
+// HedgingConfig will hold the settings of the hedged reads. When an observer does not answer a read
+// within the LatencyPercentile of the read latencies measured on its shard, but not sooner than MinDelayInMs, the read
+// is also sent to the next observer of the shard
+type HedgingConfig struct {
+	Enabled           bool
+	LatencyPercentile int
+	MinDelayInMs      int
+}
+
+// AccountsCacheConfig will hold the settings of the in-memory accounts cache
+type AccountsCacheConfig struct {
+	Enabled bool
+	Size    int
+	TTLInMs int
+}
+
+// AccountsStreamConfig will hold the settings of the account changes stream. Zero values fall back
+// to the proxy's defaults
+type AccountsStreamConfig struct {
+	PollIntervalInMs            int
+	MaxAddressesPerSubscription int
+	MaxSubscriptions            int
+	MaxPolledAddresses          int
+}
+
+// RateLimitConfig will hold the settings of the per client rate limiting. Clients are identified by
+// their IP or, when KeyBy is "api-key", by the name of the API key they were authenticated with. The
+// X-Forwarded-For header is only read when the request comes from one of the TrustedProxies. At most
+// MaxClients buckets are kept. The buckets are saved in SnapshotFile, if provided, so they survive restarts
+type RateLimitConfig struct {
+	Enabled               bool
+	KeyBy                 string
+	TrustedProxies        []string
+	MaxClients            int
+	Read                  RateLimitRuleConfig
+	Write                 RateLimitRuleConfig
+	SnapshotFile          string
+	SnapshotIntervalInSec int
+}
+
+// RateLimitRuleConfig defines a token bucket: RequestsPerSecond tokens are added each second, up to Burst
+type RateLimitRuleConfig struct {
+	RequestsPerSecond float64
+	Burst             int
+}
+
+// AuthenticationConfig will hold the API keys accepted by the proxy. The keys are read again when the config
+// file is reloaded, while Enabled and Header are only read at start
+type AuthenticationConfig struct {
+	Enabled bool
+	Header  string
+	Keys    []*ApiKeyConfig
+}
+
+// ApiKeyConfig defines a client's API key and what the client is allowed to do. An empty AllowedRouteGroups
+// allows all the route groups, an empty AllowedSenders allows sending transactions from any address and
+// a zero rate limit rule falls back to the general one
+type ApiKeyConfig struct {
+	Name               string
+	Key                string
+	AllowedRouteGroups []string
+	AllowedSenders     []string
+	ReadRateLimit      RateLimitRuleConfig
+	WriteRateLimit     RateLimitRuleConfig
 }
 
 // Config will hold the whole config file's data
 type Config struct {
-	GeneralSettings GeneralSettingsConfig
-	Observers       []*data.Observer
+	GeneralSettings       GeneralSettingsConfig
+	ServerTls             ServerTlsConfig
+	Addresses             AddressesConfig
+	TransactionValidation TransactionValidationConfig
+	NonceCheck            NonceCheckConfig
+	NonceAllocation       NonceAllocationConfig
+	AccessLog             AccessLogConfig
+	HealthCheck           HealthCheckConfig
+	HttpClient            HttpClientConfig
+	Hedging               HedgingConfig
+	AccountsCache         AccountsCacheConfig
+	AccountsStream        AccountsStreamConfig
+	RateLimit             RateLimitConfig
+	Authentication        AuthenticationConfig
+	Observers             []*data.Observer
 }
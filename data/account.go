@@ -1,5 +1,7 @@
 package data
 
+import "time"
+
 // Account defines the data structure for an account
 type Account struct {
 	Address  string `json:"address"`
@@ -7,9 +9,33 @@ type Account struct {
 	Balance  string `json:"balance"`
 	CodeHash []byte `json:"codeHash"`
 	RootHash []byte `json:"rootHash"`
+
+	// FromCache is set when the account was served from the proxy's cache instead of an observer
+	FromCache bool `json:"-"`
 }
 
 // ResponseAccount defines a wrapped account that the node respond with
 type ResponseAccount struct {
 	AccountData Account `json:"account"`
 }
+
+// AccountChange defines the data structure pushed to the subscribers of an account when its nonce or
+// balance changes. The first change received for an address holds its current state and an empty previous balance
+type AccountChange struct {
+	Address         string `json:"address"`
+	Nonce           uint64 `json:"nonce"`
+	Balance         string `json:"balance"`
+	PreviousNonce   uint64 `json:"previousNonce"`
+	PreviousBalance string `json:"previousBalance,omitempty"`
+}
+
+// NonceReservation defines the data structure of a nonce handed out by the proxy to a sender. The nonce is
+// reserved until ExpiresAt or until a transaction with that nonce is sent through the proxy. NumPending is the
+// number of the sender's transactions relayed by the proxy and not yet executed
+type NonceReservation struct {
+	Address      string    `json:"address"`
+	Nonce        uint64    `json:"nonce"`
+	AccountNonce uint64    `json:"accountNonce"`
+	NumPending   int       `json:"numPending"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+}
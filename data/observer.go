@@ -4,4 +4,6 @@ package data
 type Observer struct {
 	ShardId uint32
 	Address string
+	// Weight is used by the weighted selection strategy. Defaults to 1 when not set
+	Weight uint32
 }
@@ -1,6 +1,9 @@
 package data
 
-import "math/big"
+import (
+	"encoding/json"
+	"math/big"
+)
 
 // Transaction represents the structure that maps and validates user input for publishing a new transaction
 type Transaction struct {
@@ -19,3 +22,108 @@ type Transaction struct {
 type ResponseTransaction struct {
 	TxHash string `json:"txHash"`
 }
+
+// TransactionSendResult holds the outcome of sending a transaction. Field names the transaction's field that
+// did not pass the validation, if that is why it was not sent, and NonceCheck is set when the nonces are checked
+type TransactionSendResult struct {
+	TxHash     string      `json:"txHash,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	Field      string      `json:"field,omitempty"`
+	NonceCheck *NonceCheck `json:"nonceCheck,omitempty"`
+}
+
+const (
+	// NonceStatusOk signals that the transaction's nonce can be executed once the sender's previous
+	// transactions are
+	NonceStatusOk = "ok"
+	// NonceStatusTooLow signals that the transaction's nonce was already used, so it will never be executed
+	NonceStatusTooLow = "too-low"
+	// NonceStatusTooHigh signals that the transaction's nonce is too far ahead of the sender's nonce, so it
+	// will wait in the pool until the missing nonces are sent
+	NonceStatusTooHigh = "too-high"
+	// NonceStatusUnknown signals that the sender's nonce could not be fetched
+	NonceStatusUnknown = "unknown"
+)
+
+// NonceCheck holds the outcome of comparing a transaction's nonce with its sender's current nonce
+type NonceCheck struct {
+	Status           string `json:"status"`
+	AccountNonce     uint64 `json:"accountNonce"`
+	TransactionNonce uint64 `json:"transactionNonce"`
+	Message          string `json:"message,omitempty"`
+}
+
+// TransactionSimulationResult holds the outcome of executing a transaction on an observer without broadcasting
+// it. ReturnData holds, as provided by the observer, the values returned by a smart contract call and Error is
+// set when the execution fails
+type TransactionSimulationResult struct {
+	Status     string          `json:"status"`
+	ReturnData json.RawMessage `json:"returnData,omitempty"`
+	GasUsed    uint64          `json:"gasUsed"`
+	Error      string          `json:"error,omitempty"`
+}
+
+// ResponseTransactionSimulation defines a wrapped transaction simulation result that the node respond with
+type ResponseTransactionSimulation struct {
+	Simulation TransactionSimulationResult `json:"simulation"`
+}
+
+// TransactionDetails holds a transaction as returned by an observer, along with the block it was included in.
+// Status is the execution outcome reported by the observers that expose it, one of the ObserverTxStatus
+// values, and is empty otherwise
+type TransactionDetails struct {
+	Transaction
+	ShardId     uint32 `json:"shardId"`
+	Hash        string `json:"hash"`
+	BlockNumber uint64 `json:"blockNumber"`
+	BlockHash   string `json:"blockHash"`
+	Timestamp   uint64 `json:"timestamp"`
+	Status      string `json:"status,omitempty"`
+}
+
+const (
+	// ObserverTxStatusPending is reported by an observer for a transaction it knows but did not execute yet
+	ObserverTxStatusPending = "pending"
+	// ObserverTxStatusSuccess is reported by an observer for a transaction it executed successfully
+	ObserverTxStatusSuccess = "success"
+	// ObserverTxStatusFail is reported by an observer for a transaction whose execution failed
+	ObserverTxStatusFail = "fail"
+	// ObserverTxStatusInvalid is reported by an observer for a transaction rejected before its execution
+	ObserverTxStatusInvalid = "invalid"
+)
+
+// ResponseTransactionDetails defines a wrapped transaction that the node respond with
+type ResponseTransactionDetails struct {
+	Transaction TransactionDetails `json:"transaction"`
+}
+
+const (
+	// TxStatusPending signals that the transaction was not yet executed on its source shard
+	TxStatusPending = "pending"
+	// TxStatusExecuted signals that the shard that answered executed the transaction. The observers only return
+	// the transactions they stored after executing them
+	TxStatusExecuted = "executed"
+	// TxStatusExecutedOnSource signals that a cross-shard transaction was executed on its source shard
+	// but not yet on its destination shard
+	TxStatusExecutedOnSource = "executed-on-source"
+	// TxStatusFinal signals that the transaction was executed on both its source and destination shards
+	TxStatusFinal = "final"
+	// TxStatusFailed signals that the execution of the transaction failed. It is only known when the observers
+	// report the transactions' execution outcome
+	TxStatusFailed = "failed"
+)
+
+// CrossShardTransactionStatus holds the combined status of a transaction along with its status, pending,
+// executed or failed, on the sender's (source) and receiver's (destination) shards
+type CrossShardTransactionStatus struct {
+	Status            string `json:"status"`
+	SourceShard       uint32 `json:"sourceShard"`
+	SourceStatus      string `json:"sourceStatus"`
+	DestinationShard  uint32 `json:"destinationShard"`
+	DestinationStatus string `json:"destinationStatus"`
+}
+
+// IsFinal returns true if the combined status will not change anymore
+func (csts *CrossShardTransactionStatus) IsFinal() bool {
+	return csts.Status == TxStatusFinal || csts.Status == TxStatusFailed
+}
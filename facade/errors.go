@@ -5,5 +5,14 @@ import "github.com/pkg/errors"
 // ErrNilAccountProcessor signals that a nil account processor has been provided
 var ErrNilAccountProcessor = errors.New("nil account processor provided")
 
+// ErrNilAccountsNotifier signals that a nil accounts notifier has been provided
+var ErrNilAccountsNotifier = errors.New("nil accounts notifier provided")
+
 // ErrNilTransactionProcessor signals that a nil transaction processor has been provided
 var ErrNilTransactionProcessor = errors.New("nil transaction processor provided")
+
+// ErrNilNonceAllocator signals that a nil nonce allocator has been provided
+var ErrNilNonceAllocator = errors.New("nil nonce allocator provided")
+
+// ErrNilProxyStatusProcessor signals that a nil proxy status processor has been provided
+var ErrNilProxyStatusProcessor = errors.New("nil proxy status processor provided")
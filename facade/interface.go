@@ -1,17 +1,45 @@
 package facade
 
 import (
-	"math/big"
+	"context"
+	"time"
 
 	"github.com/numbatx/numbat-proxy/data"
+	"github.com/numbatx/numbat-proxy/process"
 )
 
-// AccountProcessor defines what an account request processor should do
+// AccountProcessor defines what an account request processor should do. InvalidateAccounts is called
+// with the sender and receiver of each transaction successfully sent through the proxy
 type AccountProcessor interface {
-	GetAccount(address string) (*data.Account, error)
+	GetAccount(ctx context.Context, address string) (*data.Account, error)
+	InvalidateAccounts(addresses ...string)
+}
+
+// AccountsNotifier defines what an account changes notifier should do
+type AccountsNotifier interface {
+	Subscribe(addresses []string) (<-chan *data.AccountChange, func(), error)
+}
+
+// NonceAllocator defines what the proxy managed nonce allocation should do. RecordSentTransaction is called
+// with the sender and nonce of each transaction successfully sent through the proxy
+type NonceAllocator interface {
+	ReserveNonce(ctx context.Context, address string) (*data.NonceReservation, error)
+	RecordSentTransaction(sender string, nonce uint64)
 }
 
 // TransactionProcessor defines what a transaction request processor should do
 type TransactionProcessor interface {
-	SendTransaction(nonce uint64, sender string, receiver string, value *big.Int, code string, signature []byte) (string, error)
+	SendTransaction(ctx context.Context, tx *data.Transaction) (*data.TransactionSendResult, error)
+	SendMultipleTransactions(ctx context.Context, txs []*data.Transaction) ([]*data.TransactionSendResult, error)
+	SimulateTransaction(ctx context.Context, tx *data.Transaction) (*data.TransactionSimulationResult, error)
+	GetTransaction(ctx context.Context, txHash string, sender string, receiver string) (*data.TransactionDetails, error)
+	GetTransactionStatus(ctx context.Context, txHash string, sender string, receiver string) (string, error)
+	GetCrossShardTransactionStatus(ctx context.Context, txHash string, sender string, receiver string) (*data.CrossShardTransactionStatus, error)
+	WaitForTransactionFinality(ctx context.Context, txHash string, sender string, receiver string, maxWait time.Duration) (*data.CrossShardTransactionStatus, error)
+}
+
+// ProxyStatusProcessor defines what the processor reporting the proxy's own state should do
+type ProxyStatusProcessor interface {
+	CheckReadiness(ctx context.Context) error
+	GetProxyStatus() *process.ProxyStatus
 }
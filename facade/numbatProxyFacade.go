@@ -1,21 +1,29 @@
 package facade
 
 import (
-	"math/big"
+	"context"
+	"time"
 
 	"github.com/numbatx/numbat-proxy/data"
+	"github.com/numbatx/numbat-proxy/process"
 )
 
 // NumbatProxyFacade implements the facade used in api calls
 type NumbatProxyFacade struct {
-	accountProc AccountProcessor
-	txProc      TransactionProcessor
+	accountProc      AccountProcessor
+	txProc           TransactionProcessor
+	accountsNotifier AccountsNotifier
+	statusProc       ProxyStatusProcessor
+	nonceAllocator   NonceAllocator
 }
 
 // NewNumbatProxyFacade creates a new NumbatProxyFacade instance
 func NewNumbatProxyFacade(
 	accountProc AccountProcessor,
 	txProc TransactionProcessor,
+	accountsNotifier AccountsNotifier,
+	statusProc ProxyStatusProcessor,
+	nonceAllocator NonceAllocator,
 ) (*NumbatProxyFacade, error) {
 
 	if accountProc == nil {
@@ -24,27 +32,136 @@ func NewNumbatProxyFacade(
 	if txProc == nil {
 		return nil, ErrNilTransactionProcessor
 	}
+	if accountsNotifier == nil {
+		return nil, ErrNilAccountsNotifier
+	}
+	if statusProc == nil {
+		return nil, ErrNilProxyStatusProcessor
+	}
+	if nonceAllocator == nil {
+		return nil, ErrNilNonceAllocator
+	}
 
 	return &NumbatProxyFacade{
-		accountProc: accountProc,
-		txProc:      txProc,
+		accountProc:      accountProc,
+		txProc:           txProc,
+		accountsNotifier: accountsNotifier,
+		statusProc:       statusProc,
+		nonceAllocator:   nonceAllocator,
 	}, nil
 }
 
 // GetAccount returns an account based on the input address
-func (epf *NumbatProxyFacade) GetAccount(address string) (*data.Account, error) {
-	return epf.accountProc.GetAccount(address)
+func (epf *NumbatProxyFacade) GetAccount(ctx context.Context, address string) (*data.Account, error) {
+	return epf.accountProc.GetAccount(ctx, address)
+}
+
+// SubscribeToAccounts returns the channel on which the nonce and balance changes of the provided addresses
+// are pushed, together with the function that ends the subscription
+func (epf *NumbatProxyFacade) SubscribeToAccounts(addresses []string) (<-chan *data.AccountChange, func(), error) {
+	return epf.accountsNotifier.Subscribe(addresses)
+}
+
+// ReserveNonce returns the next nonce the sender should use, reserved for a limited time
+func (epf *NumbatProxyFacade) ReserveNonce(ctx context.Context, address string) (*data.NonceReservation, error) {
+	return epf.nonceAllocator.ReserveNonce(ctx, address)
 }
 
 // SendTransaction should sends the transaction to the correct observer
-func (epf *NumbatProxyFacade) SendTransaction(
-	nonce uint64,
+func (epf *NumbatProxyFacade) SendTransaction(ctx context.Context, tx *data.Transaction) (*data.TransactionSendResult, error) {
+	result, err := epf.txProc.SendTransaction(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	epf.accountProc.InvalidateAccounts(tx.Sender, tx.Receiver)
+	epf.nonceAllocator.RecordSentTransaction(tx.Sender, tx.Nonce)
+
+	return result, nil
+}
+
+// SendMultipleTransactions sends the transactions to the observers of their sender's shards
+func (epf *NumbatProxyFacade) SendMultipleTransactions(
+	ctx context.Context,
+	txs []*data.Transaction,
+) ([]*data.TransactionSendResult, error) {
+
+	results, err := epf.txProc.SendMultipleTransactions(ctx, txs)
+	if err != nil {
+		return nil, err
+	}
+
+	for idx, result := range results {
+		if result.Error == "" {
+			epf.accountProc.InvalidateAccounts(txs[idx].Sender, txs[idx].Receiver)
+			epf.nonceAllocator.RecordSentTransaction(txs[idx].Sender, txs[idx].Nonce)
+		}
+	}
+
+	return results, nil
+}
+
+// SimulateTransaction executes the transaction on an observer of the sender's shard without broadcasting it
+func (epf *NumbatProxyFacade) SimulateTransaction(
+	ctx context.Context,
+	tx *data.Transaction,
+) (*data.TransactionSimulationResult, error) {
+
+	return epf.txProc.SimulateTransaction(ctx, tx)
+}
+
+// GetTransaction returns the transaction with the provided hash, looked up in the shards of the sender
+// and receiver, if provided, or in all the shards otherwise
+func (epf *NumbatProxyFacade) GetTransaction(
+	ctx context.Context,
+	txHash string,
+	sender string,
+	receiver string,
+) (*data.TransactionDetails, error) {
+
+	return epf.txProc.GetTransaction(ctx, txHash, sender, receiver)
+}
+
+// GetTransactionStatus returns the status of the transaction with the provided hash
+func (epf *NumbatProxyFacade) GetTransactionStatus(
+	ctx context.Context,
+	txHash string,
 	sender string,
 	receiver string,
-	value *big.Int,
-	code string,
-	signature []byte,
 ) (string, error) {
 
-	return epf.txProc.SendTransaction(nonce, sender, receiver, value, code, signature)
+	return epf.txProc.GetTransactionStatus(ctx, txHash, sender, receiver)
+}
+
+// GetCrossShardTransactionStatus returns the combined status of the transaction on the sender's and receiver's shards
+func (epf *NumbatProxyFacade) GetCrossShardTransactionStatus(
+	ctx context.Context,
+	txHash string,
+	sender string,
+	receiver string,
+) (*data.CrossShardTransactionStatus, error) {
+
+	return epf.txProc.GetCrossShardTransactionStatus(ctx, txHash, sender, receiver)
+}
+
+// WaitForTransactionFinality waits until the transaction is final on both its shards, failed, or maxWait elapses
+func (epf *NumbatProxyFacade) WaitForTransactionFinality(
+	ctx context.Context,
+	txHash string,
+	sender string,
+	receiver string,
+	maxWait time.Duration,
+) (*data.CrossShardTransactionStatus, error) {
+
+	return epf.txProc.WaitForTransactionFinality(ctx, txHash, sender, receiver, maxWait)
+}
+
+// CheckReadiness returns nil if the proxy is ready to serve requests
+func (epf *NumbatProxyFacade) CheckReadiness(ctx context.Context) error {
+	return epf.statusProc.CheckReadiness(ctx)
+}
+
+// GetProxyStatus returns the state of the proxy, with the secrets redacted
+func (epf *NumbatProxyFacade) GetProxyStatus() *process.ProxyStatus {
+	return epf.statusProc.GetProxyStatus()
 }
@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+type labeledValue struct {
+	labelValues []string
+	value       float64
+}
+
+// CounterVec is a monotonically increasing value, kept separately for each combination of label values
+type CounterVec struct {
+	metricName string
+	help       string
+	labelNames []string
+
+	mutValues sync.Mutex
+	values    map[string]*labeledValue
+}
+
+// Inc increments by one the counter of the provided label values
+func (cv *CounterVec) Inc(labelValues ...string) {
+	cv.Add(1, labelValues...)
+}
+
+// Add increments the counter of the provided label values. Negative deltas and label values that
+// do not match the label names are ignored
+func (cv *CounterVec) Add(delta float64, labelValues ...string) {
+	if delta < 0 || len(labelValues) != len(cv.labelNames) {
+		return
+	}
+
+	key := labelsKey(labelValues)
+
+	cv.mutValues.Lock()
+	defer cv.mutValues.Unlock()
+
+	lv, ok := cv.values[key]
+	if !ok {
+		lv = &labeledValue{labelValues: append([]string(nil), labelValues...)}
+		cv.values[key] = lv
+	}
+	lv.value += delta
+}
+
+// Value returns the counter of the provided label values
+func (cv *CounterVec) Value(labelValues ...string) float64 {
+	cv.mutValues.Lock()
+	defer cv.mutValues.Unlock()
+
+	lv, ok := cv.values[labelsKey(labelValues)]
+	if !ok {
+		return 0
+	}
+
+	return lv.value
+}
+
+func (cv *CounterVec) name() string {
+	return cv.metricName
+}
+
+func (cv *CounterVec) write(w *bufio.Writer) {
+	cv.mutValues.Lock()
+	defer cv.mutValues.Unlock()
+
+	writeHeader(w, cv.metricName, cv.help, "counter")
+	keys := make([]string, 0, len(cv.values))
+	for key := range cv.values {
+		keys = append(keys, key)
+	}
+	for _, key := range sortedKeys(keys) {
+		lv := cv.values[key]
+		_, _ = fmt.Fprintf(w, "%s%s %s\n",
+			cv.metricName,
+			formatLabels(cv.labelNames, lv.labelValues, "", ""),
+			formatFloat(lv.value),
+		)
+	}
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
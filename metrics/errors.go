@@ -0,0 +1,12 @@
+package metrics
+
+import "errors"
+
+// ErrDuplicatedMetric signals that a metric with the same name has already been registered
+var ErrDuplicatedMetric = errors.New("metric already registered")
+
+// ErrInvalidBuckets signals that the histogram buckets are empty or not sorted
+var ErrInvalidBuckets = errors.New("invalid histogram buckets")
+
+// ErrNilCollectFunc signals that a nil collect function has been provided for a gauge
+var ErrNilCollectFunc = errors.New("nil collect function")
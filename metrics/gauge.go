@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+)
+
+// GaugeValue is a value reported by a gauge function, together with its label values
+type GaugeValue struct {
+	LabelValues []string
+	Value       float64
+}
+
+type gaugeFunc struct {
+	metricName string
+	help       string
+	labelNames []string
+	collect    func() []GaugeValue
+}
+
+func (gf *gaugeFunc) name() string {
+	return gf.metricName
+}
+
+func (gf *gaugeFunc) write(w *bufio.Writer) {
+	writeHeader(w, gf.metricName, gf.help, "gauge")
+	for _, gv := range gf.collect() {
+		if len(gv.LabelValues) != len(gf.labelNames) {
+			continue
+		}
+
+		_, _ = fmt.Fprintf(w, "%s%s %s\n",
+			gf.metricName,
+			formatLabels(gf.labelNames, gv.LabelValues, "", ""),
+			formatFloat(gv.Value),
+		)
+	}
+}
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"sync"
+)
+
+type labeledHistogram struct {
+	labelValues  []string
+	bucketCounts []uint64
+	count        uint64
+	sum          float64
+}
+
+// HistogramVec counts the observed values in buckets, separately for each combination of label values
+type HistogramVec struct {
+	metricName string
+	help       string
+	buckets    []float64
+	labelNames []string
+
+	mutValues sync.Mutex
+	values    map[string]*labeledHistogram
+}
+
+// Observe adds a value to the histogram of the provided label values. Label values that do not match
+// the label names are ignored
+func (hv *HistogramVec) Observe(value float64, labelValues ...string) {
+	if len(labelValues) != len(hv.labelNames) {
+		return
+	}
+
+	key := labelsKey(labelValues)
+
+	hv.mutValues.Lock()
+	defer hv.mutValues.Unlock()
+
+	lh, ok := hv.values[key]
+	if !ok {
+		lh = &labeledHistogram{
+			labelValues:  append([]string(nil), labelValues...),
+			bucketCounts: make([]uint64, len(hv.buckets)),
+		}
+		hv.values[key] = lh
+	}
+
+	for i, upperBound := range hv.buckets {
+		if value <= upperBound {
+			lh.bucketCounts[i]++
+		}
+	}
+	lh.count++
+	lh.sum += value
+}
+
+// Count returns the number of values observed for the provided label values
+func (hv *HistogramVec) Count(labelValues ...string) uint64 {
+	hv.mutValues.Lock()
+	defer hv.mutValues.Unlock()
+
+	lh, ok := hv.values[labelsKey(labelValues)]
+	if !ok {
+		return 0
+	}
+
+	return lh.count
+}
+
+func (hv *HistogramVec) name() string {
+	return hv.metricName
+}
+
+func (hv *HistogramVec) write(w *bufio.Writer) {
+	hv.mutValues.Lock()
+	defer hv.mutValues.Unlock()
+
+	writeHeader(w, hv.metricName, hv.help, "histogram")
+	keys := make([]string, 0, len(hv.values))
+	for key := range hv.values {
+		keys = append(keys, key)
+	}
+	for _, key := range sortedKeys(keys) {
+		lh := hv.values[key]
+		for i, upperBound := range hv.buckets {
+			_, _ = fmt.Fprintf(w, "%s_bucket%s %d\n",
+				hv.metricName,
+				formatLabels(hv.labelNames, lh.labelValues, "le", formatFloat(upperBound)),
+				lh.bucketCounts[i],
+			)
+		}
+		_, _ = fmt.Fprintf(w, "%s_bucket%s %d\n", hv.metricName, formatLabels(hv.labelNames, lh.labelValues, "le", "+Inf"), lh.count)
+
+		labels := formatLabels(hv.labelNames, lh.labelValues, "", "")
+		_, _ = fmt.Fprintf(w, "%s_sum%s %s\n", hv.metricName, labels, formatFloat(lh.sum))
+		_, _ = fmt.Fprintf(w, "%s_count%s %d\n", hv.metricName, labels, lh.count)
+	}
+}
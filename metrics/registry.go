@@ -0,0 +1,165 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultLatencyBuckets are the histogram buckets, in seconds, used for request latencies
+var DefaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type collector interface {
+	name() string
+	write(w *bufio.Writer)
+}
+
+// Registry holds the metrics of the proxy and renders them in the Prometheus text exposition format
+type Registry struct {
+	mutCollectors sync.RWMutex
+	collectors    map[string]collector
+}
+
+// NewRegistry creates a new, empty, instance of Registry
+func NewRegistry() *Registry {
+	return &Registry{
+		collectors: make(map[string]collector),
+	}
+}
+
+// NewCounterVec creates and registers a counter with the provided label names
+func (r *Registry) NewCounterVec(name string, help string, labelNames ...string) (*CounterVec, error) {
+	counter := &CounterVec{
+		metricName: name,
+		help:       help,
+		labelNames: labelNames,
+		values:     make(map[string]*labeledValue),
+	}
+
+	err := r.register(counter)
+	if err != nil {
+		return nil, err
+	}
+
+	return counter, nil
+}
+
+// NewHistogramVec creates and registers a histogram with the provided upper bounds and label names
+func (r *Registry) NewHistogramVec(
+	name string,
+	help string,
+	buckets []float64,
+	labelNames ...string,
+) (*HistogramVec, error) {
+
+	if len(buckets) == 0 || !sort.Float64sAreSorted(buckets) {
+		return nil, ErrInvalidBuckets
+	}
+
+	histogram := &HistogramVec{
+		metricName: name,
+		help:       help,
+		buckets:    buckets,
+		labelNames: labelNames,
+		values:     make(map[string]*labeledHistogram),
+	}
+
+	err := r.register(histogram)
+	if err != nil {
+		return nil, err
+	}
+
+	return histogram, nil
+}
+
+// NewGaugeFunc registers a gauge whose values are computed by collect each time the metrics are rendered
+func (r *Registry) NewGaugeFunc(name string, help string, labelNames []string, collect func() []GaugeValue) error {
+	if collect == nil {
+		return ErrNilCollectFunc
+	}
+
+	return r.register(&gaugeFunc{
+		metricName: name,
+		help:       help,
+		labelNames: labelNames,
+		collect:    collect,
+	})
+}
+
+func (r *Registry) register(c collector) error {
+	r.mutCollectors.Lock()
+	defer r.mutCollectors.Unlock()
+
+	_, exists := r.collectors[c.name()]
+	if exists {
+		return fmt.Errorf("%w: %s", ErrDuplicatedMetric, c.name())
+	}
+
+	r.collectors[c.name()] = c
+
+	return nil
+}
+
+// Write renders all the metrics, sorted by name, in the Prometheus text exposition format
+func (r *Registry) Write(w io.Writer) error {
+	r.mutCollectors.RLock()
+	names := make([]string, 0, len(r.collectors))
+	for name := range r.collectors {
+		names = append(names, name)
+	}
+	collectors := make([]collector, 0, len(names))
+	sort.Strings(names)
+	for _, name := range names {
+		collectors = append(collectors, r.collectors[name])
+	}
+	r.mutCollectors.RUnlock()
+
+	bw := bufio.NewWriter(w)
+	for _, c := range collectors {
+		c.write(bw)
+	}
+
+	return bw.Flush()
+}
+
+func writeHeader(w *bufio.Writer, name string, help string, metricType string) {
+	_, _ = fmt.Fprintf(w, "# HELP %s %s\n", name, strings.ReplaceAll(help, "\n", " "))
+	_, _ = fmt.Fprintf(w, "# TYPE %s %s\n", name, metricType)
+}
+
+// formatLabels returns the {name="value",...} part of a sample. The extra label, if not empty, is appended
+func formatLabels(labelNames []string, labelValues []string, extraName string, extraValue string) string {
+	if len(labelNames) == 0 && extraName == "" {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(labelNames)+1)
+	for i, labelName := range labelNames {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, labelName, escapeLabelValue(labelValues[i])))
+	}
+	if extraName != "" {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, extraName, escapeLabelValue(extraValue)))
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func escapeLabelValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, "\n", `\n`)
+
+	return strings.ReplaceAll(value, `"`, `\"`)
+}
+
+func labelsKey(labelValues []string) string {
+	return strings.Join(labelValues, "\xff")
+}
+
+func sortedKeys(keys []string) []string {
+	sort.Strings(keys)
+
+	return keys
+}
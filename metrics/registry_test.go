@@ -0,0 +1,90 @@
+package metrics_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/numbatx/numbat-proxy/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_DuplicatedNameShouldErr(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.NewRegistry()
+	_, err := registry.NewCounterVec("requests_total", "help")
+	assert.Nil(t, err)
+
+	_, err = registry.NewHistogramVec("requests_total", "help", metrics.DefaultLatencyBuckets)
+	assert.True(t, errors.Is(err, metrics.ErrDuplicatedMetric))
+}
+
+func TestRegistry_NewHistogramVecUnsortedBucketsShouldErr(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.NewRegistry()
+	hv, err := registry.NewHistogramVec("duration_seconds", "help", []float64{1, 0.5})
+
+	assert.Nil(t, hv)
+	assert.Equal(t, metrics.ErrInvalidBuckets, err)
+}
+
+func TestRegistry_NewGaugeFuncNilCollectShouldErr(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.NewRegistry()
+	err := registry.NewGaugeFunc("observers", "help", nil, nil)
+
+	assert.Equal(t, metrics.ErrNilCollectFunc, err)
+}
+
+func TestCounterVec_InvalidValuesShouldBeIgnored(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.NewRegistry()
+	cv, _ := registry.NewCounterVec("requests_total", "help", "route")
+	cv.Inc("/a")
+	cv.Add(-5, "/a")
+	cv.Inc("/a", "extra")
+
+	assert.Equal(t, float64(1), cv.Value("/a"))
+}
+
+func TestRegistry_WriteShouldRenderTextFormat(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.NewRegistry()
+	cv, _ := registry.NewCounterVec("requests_total", "Number of requests", "route", "status")
+	hv, _ := registry.NewHistogramVec("duration_seconds", "Duration of requests", []float64{0.1, 1}, "route")
+	_ = registry.NewGaugeFunc("observers", "Number of observers", []string{"shard"}, func() []metrics.GaugeValue {
+		return []metrics.GaugeValue{{LabelValues: []string{"0"}, Value: 3}}
+	})
+
+	cv.Inc("/b", "200")
+	cv.Add(2, `/a"quoted"`, "500")
+	hv.Observe(0.05, "/a")
+	hv.Observe(0.5, "/a")
+	hv.Observe(5, "/a")
+
+	buff := &bytes.Buffer{}
+	err := registry.Write(buff)
+
+	expected := `# HELP duration_seconds Duration of requests
+# TYPE duration_seconds histogram
+duration_seconds_bucket{route="/a",le="0.1"} 1
+duration_seconds_bucket{route="/a",le="1"} 2
+duration_seconds_bucket{route="/a",le="+Inf"} 3
+duration_seconds_sum{route="/a"} 5.55
+duration_seconds_count{route="/a"} 3
+# HELP observers Number of observers
+# TYPE observers gauge
+observers{shard="0"} 3
+# HELP requests_total Number of requests
+# TYPE requests_total counter
+requests_total{route="/a\"quoted\"",status="500"} 2
+requests_total{route="/b",status="200"} 1
+`
+	assert.Nil(t, err)
+	assert.Equal(t, expected, buff.String())
+}
@@ -1,6 +1,7 @@
 package process
 
 import (
+	"context"
 	"encoding/hex"
 	"fmt"
 
@@ -12,23 +13,29 @@ const AddressPath = "/address/"
 
 // AccountProcessor is able to process account requests
 type AccountProcessor struct {
-	proc Processor
+	proc  Processor
+	codec AddressCodec
 }
 
 // NewAccountProcessor creates a new instance of AccountProcessor
-func NewAccountProcessor(proc Processor) (*AccountProcessor, error) {
+func NewAccountProcessor(proc Processor, codec AddressCodec) (*AccountProcessor, error) {
 	if proc == nil {
 		return nil, ErrNilCoreProcessor
 	}
+	if codec == nil {
+		return nil, ErrNilAddressCodec
+	}
 
 	return &AccountProcessor{
-		proc: proc,
+		proc:  proc,
+		codec: codec,
 	}, nil
 }
 
-// GetAccount resolves the request by sending the request to the right observer and replies back the answer
-func (ap *AccountProcessor) GetAccount(address string) (*data.Account, error) {
-	addressBytes, err := hex.DecodeString(address)
+// GetAccount resolves the request by sending the request to the right observer and replies back the answer.
+// The address can be either hex or bech32 encoded and the returned account's address is in the output format
+func (ap *AccountProcessor) GetAccount(ctx context.Context, address string) (*data.Account, error) {
+	addressBytes, err := ap.codec.DecodeAddress(address)
 	if err != nil {
 		return nil, err
 	}
@@ -43,17 +50,23 @@ func (ap *AccountProcessor) GetAccount(address string) (*data.Account, error) {
 		return nil, err
 	}
 
-	for _, observer := range observers {
-		responseAccount := &data.ResponseAccount{}
+	path := AddressPath + hex.EncodeToString(addressBytes)
+	createResponse := func() interface{} {
+		return &data.ResponseAccount{}
+	}
+	response, observerAddress, err := readFromObservers(ctx, ap.proc, observers, path, createResponse)
+	if err != nil {
+		return nil, err
+	}
 
-		err = ap.proc.CallGetRestEndPoint(observer.Address, AddressPath+address, responseAccount)
-		if err == nil {
-			log.Info(fmt.Sprintf("Got account request from observer %v from shard %v", observer.Address, shardId))
-			return &responseAccount.AccountData, nil
-		}
+	log.Info(fmt.Sprintf("Got account request from observer %v from shard %v, request id %s",
+		observerAddress,
+		shardId,
+		GetRequestId(ctx),
+	))
 
-		log.LogIfError(err)
-	}
+	account := &response.(*data.ResponseAccount).AccountData
+	account.Address = fromObserverAddress(ap.codec, account.Address)
 
-	return nil, ErrSendingRequest
+	return account, nil
 }
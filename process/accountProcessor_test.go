@@ -1,8 +1,12 @@
 package process_test
 
 import (
+	"context"
 	"errors"
+	"net/http"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/numbatx/numbat-proxy/data"
 	"github.com/numbatx/numbat-proxy/process"
@@ -13,7 +17,7 @@ import (
 func TestNewAccountProcessor_NilCoreProcessorShouldErr(t *testing.T) {
 	t.Parallel()
 
-	ap, err := process.NewAccountProcessor(nil)
+	ap, err := process.NewAccountProcessor(nil, createHexAddressCodec())
 
 	assert.Nil(t, ap)
 	assert.Equal(t, process.ErrNilCoreProcessor, err)
@@ -22,25 +26,78 @@ func TestNewAccountProcessor_NilCoreProcessorShouldErr(t *testing.T) {
 func TestNewAccountProcessor_WithCoreProcessorShouldWork(t *testing.T) {
 	t.Parallel()
 
-	ap, err := process.NewAccountProcessor(&mock.ProcessorStub{})
+	ap, err := process.NewAccountProcessor(&mock.ProcessorStub{}, createHexAddressCodec())
 
 	assert.NotNil(t, ap)
 	assert.Nil(t, err)
 }
 
+func TestNewAccountProcessor_NilAddressCodecShouldErr(t *testing.T) {
+	t.Parallel()
+
+	ap, err := process.NewAccountProcessor(&mock.ProcessorStub{}, nil)
+
+	assert.Nil(t, ap)
+	assert.Equal(t, process.ErrNilAddressCodec, err)
+}
+
 //------- GetAccount
 
 func TestAccountProcessor_GetAccountInvalidHexAdressShouldErr(t *testing.T) {
 	t.Parallel()
 
-	ap, _ := process.NewAccountProcessor(&mock.ProcessorStub{})
-	accnt, err := ap.GetAccount("invalid hex number")
+	ap, _ := process.NewAccountProcessor(&mock.ProcessorStub{}, createHexAddressCodec())
+	accnt, err := ap.GetAccount(context.Background(), "invalid hex number")
 
 	assert.Nil(t, accnt)
 	assert.NotNil(t, err)
 	assert.Contains(t, err.Error(), "invalid byte")
 }
 
+func TestAccountProcessor_GetAccountWrongBech32ChecksumShouldErr(t *testing.T) {
+	t.Parallel()
+
+	codec := createBech32AddressCodec()
+	address := codec.EncodeAddress([]byte{0xde, 0xad, 0xbe, 0xef})
+	ap, _ := process.NewAccountProcessor(&mock.ProcessorStub{}, codec)
+
+	accnt, err := ap.GetAccount(context.Background(), address[:len(address)-1]+"x")
+
+	assert.Nil(t, accnt)
+	assert.True(t, errors.Is(err, process.ErrInvalidAddress))
+	assert.True(t, errors.Is(err, process.ErrInvalidAddressChecksum))
+}
+
+func TestAccountProcessor_GetAccountBech32AddressShouldQueryHexAddress(t *testing.T) {
+	t.Parallel()
+
+	codec := createBech32AddressCodec()
+	address := codec.EncodeAddress([]byte{0xde, 0xad, 0xbe, 0xef})
+	var computedAddress []byte
+	var queriedPath string
+	ap, _ := process.NewAccountProcessor(&mock.ProcessorStub{
+		ComputeShardIdCalled: func(addressBuff []byte) (u uint32, e error) {
+			computedAddress = addressBuff
+			return 0, nil
+		},
+		GetObserversCalled: func(shardId uint32) (observers []*data.Observer, e error) {
+			return []*data.Observer{{Address: "address1", ShardId: 0}}, nil
+		},
+		CallGetRestEndPointCalled: func(ctx context.Context, address string, path string, value interface{}) error {
+			queriedPath = path
+			value.(*data.ResponseAccount).AccountData.Address = "deadbeef"
+			return nil
+		},
+	}, codec)
+
+	accnt, err := ap.GetAccount(context.Background(), address)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0xde, 0xad, 0xbe, 0xef}, computedAddress)
+	assert.Equal(t, process.AddressPath+"deadbeef", queriedPath)
+	assert.Equal(t, address, accnt.Address)
+}
+
 func TestAccountProcessor_GetAccountComputeShardIdFailsShouldErr(t *testing.T) {
 	t.Parallel()
 
@@ -49,9 +106,9 @@ func TestAccountProcessor_GetAccountComputeShardIdFailsShouldErr(t *testing.T) {
 		ComputeShardIdCalled: func(addressBuff []byte) (u uint32, e error) {
 			return 0, errExpected
 		},
-	})
+	}, createHexAddressCodec())
 	address := "DEADBEEF"
-	accnt, err := ap.GetAccount(address)
+	accnt, err := ap.GetAccount(context.Background(), address)
 
 	assert.Nil(t, accnt)
 	assert.Equal(t, errExpected, err)
@@ -68,9 +125,9 @@ func TestAccountProcessor_GetAccountGetObserversFailsShouldErr(t *testing.T) {
 		GetObserversCalled: func(shardId uint32) (observers []*data.Observer, e error) {
 			return nil, errExpected
 		},
-	})
+	}, createHexAddressCodec())
 	address := "DEADBEEF"
-	accnt, err := ap.GetAccount(address)
+	accnt, err := ap.GetAccount(context.Background(), address)
 
 	assert.Nil(t, accnt)
 	assert.Equal(t, errExpected, err)
@@ -90,15 +147,47 @@ func TestAccountProcessor_GetAccountSendingFailsOnAllObserversShouldErr(t *testi
 				{Address: "adress2", ShardId: 0},
 			}, nil
 		},
-		CallGetRestEndPointCalled: func(address string, path string, value interface{}) error {
+		CallGetRestEndPointCalled: func(ctx context.Context, address string, path string, value interface{}) error {
 			return errExpected
 		},
-	})
+	}, createHexAddressCodec())
 	address := "DEADBEEF"
-	accnt, err := ap.GetAccount(address)
+	accnt, err := ap.GetAccount(context.Background(), address)
+
+	assert.Nil(t, accnt)
+	assert.True(t, errors.Is(err, process.ErrSendingRequest))
+	assert.True(t, errors.Is(err, errExpected))
+}
+
+func TestAccountProcessor_GetAccountNotRetryableErrorShouldNotTryOtherObservers(t *testing.T) {
+	t.Parallel()
+
+	errExpected := &process.ObserverError{
+		Address:    "address1",
+		StatusCode: http.StatusNotFound,
+		Message:    "account not found",
+	}
+	numCalls := 0
+	ap, _ := process.NewAccountProcessor(&mock.ProcessorStub{
+		ComputeShardIdCalled: func(addressBuff []byte) (u uint32, e error) {
+			return 0, nil
+		},
+		GetObserversCalled: func(shardId uint32) (observers []*data.Observer, e error) {
+			return []*data.Observer{
+				{Address: "address1", ShardId: 0},
+				{Address: "address2", ShardId: 0},
+			}, nil
+		},
+		CallGetRestEndPointCalled: func(ctx context.Context, address string, path string, value interface{}) error {
+			numCalls++
+			return errExpected
+		},
+	}, createHexAddressCodec())
+	accnt, err := ap.GetAccount(context.Background(), "DEADBEEF")
 
 	assert.Nil(t, accnt)
-	assert.Equal(t, process.ErrSendingRequest, err)
+	assert.Equal(t, errExpected, err)
+	assert.Equal(t, 1, numCalls)
 }
 
 func TestAccountProcessor_GetAccountSendingFailsOnFirstObserverShouldStillSend(t *testing.T) {
@@ -121,7 +210,7 @@ func TestAccountProcessor_GetAccountSendingFailsOnFirstObserverShouldStillSend(t
 				{Address: "adress2", ShardId: 0},
 			}, nil
 		},
-		CallGetRestEndPointCalled: func(address string, path string, value interface{}) error {
+		CallGetRestEndPointCalled: func(ctx context.Context, address string, path string, value interface{}) error {
 			if address == addressFail {
 				return errExpected
 			}
@@ -130,10 +219,92 @@ func TestAccountProcessor_GetAccountSendingFailsOnFirstObserverShouldStillSend(t
 			valRespond.AccountData = respondedAccount.AccountData
 			return nil
 		},
-	})
+	}, createHexAddressCodec())
 	address := "DEADBEEF"
-	accnt, err := ap.GetAccount(address)
+	accnt, err := ap.GetAccount(context.Background(), address)
 
 	assert.Equal(t, &respondedAccount.AccountData, accnt)
 	assert.Nil(t, err)
 }
+
+//------- hedging
+
+func createHedgingProcessorStub(hedgingDelay time.Duration, isSlowObserverCanceled chan struct{}) *mock.ProcessorStub {
+	return &mock.ProcessorStub{
+		ComputeShardIdCalled: func(addressBuff []byte) (uint32, error) {
+			return 0, nil
+		},
+		GetObserversCalled: func(shardId uint32) (observers []*data.Observer, e error) {
+			return []*data.Observer{
+				{Address: "slow", ShardId: 0},
+				{Address: "fast", ShardId: 0},
+			}, nil
+		},
+		GetHedgingDelayCalled: func(_ uint32) time.Duration {
+			return hedgingDelay
+		},
+		CallGetRestEndPointCalled: func(ctx context.Context, address string, path string, value interface{}) error {
+			if address == "slow" {
+				select {
+				case <-ctx.Done():
+					close(isSlowObserverCanceled)
+					return ctx.Err()
+				case <-time.After(time.Second):
+				}
+			}
+
+			value.(*data.ResponseAccount).AccountData.Address = address
+			return nil
+		},
+	}
+}
+
+func TestAccountProcessor_GetAccountHedgingShouldUseFirstAnswerAndCancelTheOther(t *testing.T) {
+	t.Parallel()
+
+	isSlowObserverCanceled := make(chan struct{})
+	ap, _ := process.NewAccountProcessor(createHedgingProcessorStub(10*time.Millisecond, isSlowObserverCanceled), createHexAddressCodec())
+
+	start := time.Now()
+	account, err := ap.GetAccount(context.Background(), "aabb")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "fast", account.Address)
+	assert.True(t, time.Since(start) < 500*time.Millisecond)
+	select {
+	case <-isSlowObserverCanceled:
+	case <-time.After(time.Second):
+		assert.Fail(t, "the slow observer's request should have been canceled")
+	}
+}
+
+func TestAccountProcessor_GetAccountShouldRecordTheLatencyOfClientReadsOnly(t *testing.T) {
+	t.Parallel()
+
+	proc := createHedgingProcessorStub(0, make(chan struct{}))
+	numRecorded := uint32(0)
+	proc.CallGetRestEndPointCalled = func(ctx context.Context, address string, path string, value interface{}) error {
+		return nil
+	}
+	proc.RecordReadLatencyCalled = func(shardId uint32, latency time.Duration) {
+		atomic.AddUint32(&numRecorded, 1)
+	}
+	ap, _ := process.NewAccountProcessor(proc, createHexAddressCodec())
+
+	_, _ = ap.GetAccount(context.Background(), "aabb")
+	assert.Equal(t, uint32(0), atomic.LoadUint32(&numRecorded))
+
+	_, _ = ap.GetAccount(process.NewRequestContext(context.Background(), "request"), "aabb")
+	assert.Equal(t, uint32(1), atomic.LoadUint32(&numRecorded))
+}
+
+func TestAccountProcessor_GetAccountWithoutHedgingShouldWaitForFirstObserver(t *testing.T) {
+	t.Parallel()
+
+	ap, _ := process.NewAccountProcessor(createHedgingProcessorStub(0, make(chan struct{})), createHexAddressCodec())
+
+	account, err := ap.GetAccount(context.Background(), "aabb")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "slow", account.Address)
+}
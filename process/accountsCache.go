@@ -0,0 +1,121 @@
+package process
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/numbatx/gn-numbat/storage/lrucache"
+	"github.com/numbatx/numbat-proxy/config"
+	"github.com/numbatx/numbat-proxy/data"
+)
+
+type cachedAccount struct {
+	account   data.Account
+	expiresAt time.Time
+}
+
+// AccountsCache is an account getter that keeps, for a limited time, the most recently requested accounts in
+// memory. The accounts are cached by their hex address, so both address formats hit the same entry. When
+// disabled, all the requests are passed to the wrapped account getter
+type AccountsCache struct {
+	accountGetter AccountGetter
+	codec         AddressCodec
+	isEnabled     bool
+	ttl           time.Duration
+	cache         *lrucache.LRUCache
+	generation    uint64
+}
+
+// NewAccountsCache creates a new instance of AccountsCache
+func NewAccountsCache(
+	accountGetter AccountGetter,
+	codec AddressCodec,
+	cfg config.AccountsCacheConfig,
+) (*AccountsCache, error) {
+
+	if accountGetter == nil {
+		return nil, ErrNilAccountGetter
+	}
+	if codec == nil {
+		return nil, ErrNilAddressCodec
+	}
+	if !cfg.Enabled {
+		return &AccountsCache{
+			accountGetter: accountGetter,
+			codec:         codec,
+		}, nil
+	}
+	if cfg.Size <= 0 || cfg.TTLInMs <= 0 {
+		return nil, ErrInvalidAccountsCacheConfig
+	}
+
+	cache, err := lrucache.NewCache(cfg.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AccountsCache{
+		accountGetter: accountGetter,
+		codec:         codec,
+		isEnabled:     true,
+		ttl:           time.Duration(cfg.TTLInMs) * time.Millisecond,
+		cache:         cache,
+	}, nil
+}
+
+// GetAccount returns the cached account, if not expired, or requests it from the wrapped account getter
+func (ac *AccountsCache) GetAccount(ctx context.Context, address string) (*data.Account, error) {
+	if !ac.isEnabled {
+		return ac.accountGetter.GetAccount(ctx, address)
+	}
+
+	// the addresses that can not be decoded are passed on so the wrapped account getter reports the error
+	key, err := normalizeAddress(ac.codec, address)
+	if err != nil {
+		return ac.accountGetter.GetAccount(ctx, address)
+	}
+
+	value, ok := ac.cache.Get([]byte(key))
+	if ok {
+		entry := value.(*cachedAccount)
+		if time.Now().Before(entry.expiresAt) {
+			account := entry.account
+			account.FromCache = true
+			return &account, nil
+		}
+	}
+
+	// an account fetched while an invalidation happens is not cached as it might be stale already
+	generation := atomic.LoadUint64(&ac.generation)
+	account, err := ac.accountGetter.GetAccount(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	if atomic.LoadUint64(&ac.generation) == generation {
+		ac.cache.Put([]byte(key), &cachedAccount{
+			account:   *account,
+			expiresAt: time.Now().Add(ac.ttl),
+		})
+	}
+
+	return account, nil
+}
+
+// InvalidateAccounts removes the provided addresses, in any of the accepted formats, from the cache
+func (ac *AccountsCache) InvalidateAccounts(addresses ...string) {
+	if !ac.isEnabled {
+		return
+	}
+
+	atomic.AddUint64(&ac.generation, 1)
+	for _, address := range addresses {
+		key, err := normalizeAddress(ac.codec, address)
+		if err != nil {
+			continue
+		}
+
+		ac.cache.Remove([]byte(key))
+	}
+}
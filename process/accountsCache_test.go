@@ -0,0 +1,126 @@
+package process_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/numbatx/numbat-proxy/config"
+	"github.com/numbatx/numbat-proxy/data"
+	"github.com/numbatx/numbat-proxy/process"
+	"github.com/numbatx/numbat-proxy/process/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func createCountingAccountGetter(numCalls *int32) *mock.AccountGetterStub {
+	return &mock.AccountGetterStub{
+		GetAccountCalled: func(ctx context.Context, address string) (*data.Account, error) {
+			nonce := atomic.AddInt32(numCalls, 1)
+
+			return &data.Account{Address: address, Nonce: uint64(nonce)}, nil
+		},
+	}
+}
+
+func TestNewAccountsCache_NilAccountGetterShouldErr(t *testing.T) {
+	t.Parallel()
+
+	ac, err := process.NewAccountsCache(nil, createHexAddressCodec(), config.AccountsCacheConfig{})
+
+	assert.Nil(t, ac)
+	assert.Equal(t, process.ErrNilAccountGetter, err)
+}
+
+func TestNewAccountsCache_InvalidConfigShouldErr(t *testing.T) {
+	t.Parallel()
+
+	ac, err := process.NewAccountsCache(&mock.AccountGetterStub{}, createHexAddressCodec(), config.AccountsCacheConfig{
+		Enabled: true,
+		Size:    10,
+	})
+
+	assert.Nil(t, ac)
+	assert.Equal(t, process.ErrInvalidAccountsCacheConfig, err)
+}
+
+func TestAccountsCache_DisabledShouldPassRequestsThrough(t *testing.T) {
+	t.Parallel()
+
+	numCalls := int32(0)
+	ac, _ := process.NewAccountsCache(createCountingAccountGetter(&numCalls), createHexAddressCodec(), config.AccountsCacheConfig{})
+
+	_, _ = ac.GetAccount(context.Background(), "aa")
+	account, err := ac.GetAccount(context.Background(), "aa")
+
+	assert.Nil(t, err)
+	assert.False(t, account.FromCache)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&numCalls))
+}
+
+func TestAccountsCache_GetAccountShouldServeFromCacheUntilExpired(t *testing.T) {
+	t.Parallel()
+
+	numCalls := int32(0)
+	ac, _ := process.NewAccountsCache(createCountingAccountGetter(&numCalls), createHexAddressCodec(), config.AccountsCacheConfig{
+		Enabled: true,
+		Size:    10,
+		TTLInMs: 100,
+	})
+
+	account, _ := ac.GetAccount(context.Background(), "aa")
+	assert.False(t, account.FromCache)
+
+	account, _ = ac.GetAccount(context.Background(), "aa")
+	assert.True(t, account.FromCache)
+	assert.Equal(t, uint64(1), account.Nonce)
+
+	time.Sleep(150 * time.Millisecond)
+	account, _ = ac.GetAccount(context.Background(), "aa")
+	assert.False(t, account.FromCache)
+	assert.Equal(t, uint64(2), account.Nonce)
+}
+
+func TestAccountsCache_InvalidateAccountsShouldEvict(t *testing.T) {
+	t.Parallel()
+
+	numCalls := int32(0)
+	ac, _ := process.NewAccountsCache(createCountingAccountGetter(&numCalls), createHexAddressCodec(), config.AccountsCacheConfig{
+		Enabled: true,
+		Size:    10,
+		TTLInMs: 60000,
+	})
+
+	_, _ = ac.GetAccount(context.Background(), "aa")
+	_, _ = ac.GetAccount(context.Background(), "bb")
+	ac.InvalidateAccounts("aa")
+
+	account, _ := ac.GetAccount(context.Background(), "aa")
+	assert.False(t, account.FromCache)
+	account, _ = ac.GetAccount(context.Background(), "bb")
+	assert.True(t, account.FromCache)
+}
+
+func TestAccountsCache_BothAddressFormatsShouldShareTheCachedAccount(t *testing.T) {
+	t.Parallel()
+
+	codec := createBech32AddressCodec()
+	bech32Address := codec.EncodeAddress([]byte{0xaa})
+	numCalls := int32(0)
+	ac, _ := process.NewAccountsCache(createCountingAccountGetter(&numCalls), codec, config.AccountsCacheConfig{
+		Enabled: true,
+		Size:    10,
+		TTLInMs: 60000,
+	})
+
+	_, _ = ac.GetAccount(context.Background(), "AA")
+	account, err := ac.GetAccount(context.Background(), bech32Address)
+	assert.Nil(t, err)
+	assert.True(t, account.FromCache)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&numCalls))
+
+	ac.InvalidateAccounts(bech32Address)
+	account, _ = ac.GetAccount(context.Background(), "aa")
+	assert.False(t, account.FromCache)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&numCalls))
+}
@@ -0,0 +1,330 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/numbatx/numbat-proxy/config"
+	"github.com/numbatx/numbat-proxy/data"
+)
+
+const defaultAccountsPollInterval = time.Second
+const defaultMaxAddressesPerSubscription = 100
+const defaultMaxSubscriptions = 10000
+const defaultMaxPolledAddresses = 10000
+const accountPollTimeout = 5 * time.Second
+const maxConcurrentAccountPolls = 16
+const accountChangesBufferSize = 32
+
+type accountsSubscriber struct {
+	id          uint64
+	addresses   []string
+	chanChanges chan *data.AccountChange
+}
+
+type watchedAccount struct {
+	lastAccount *data.Account
+	subscribers map[uint64]*accountsSubscriber
+}
+
+// AccountsNotifier polls, once per interval, every address that has at least one subscriber and pushes
+// the nonce and balance changes to all the subscribers of that address. A subscriber that does not
+// consume its changes fast enough is dropped. The number of subscriptions and of distinct polled addresses
+// are capped, as each of them costs the observers a request per interval
+type AccountsNotifier struct {
+	accountGetter      AccountGetter
+	codec              AddressCodec
+	pollInterval       time.Duration
+	maxAddresses       int
+	maxSubscriptions   int
+	maxPolledAddresses int
+
+	mutSubscriptions sync.Mutex
+	accounts         map[string]*watchedAccount
+	subscribers      map[uint64]*accountsSubscriber
+	lastSubscriberId uint64
+	isClosed         bool
+	chanClose        chan struct{}
+	closeOnce        sync.Once
+}
+
+// NewAccountsNotifier creates a new instance of AccountsNotifier
+func NewAccountsNotifier(
+	accountGetter AccountGetter,
+	codec AddressCodec,
+	cfg config.AccountsStreamConfig,
+) (*AccountsNotifier, error) {
+
+	if accountGetter == nil {
+		return nil, ErrNilAccountGetter
+	}
+	if codec == nil {
+		return nil, ErrNilAddressCodec
+	}
+
+	return &AccountsNotifier{
+		accountGetter:      accountGetter,
+		codec:              codec,
+		pollInterval:       durationOrDefault(cfg.PollIntervalInMs, time.Millisecond, defaultAccountsPollInterval),
+		maxAddresses:       intOrDefault(cfg.MaxAddressesPerSubscription, defaultMaxAddressesPerSubscription),
+		maxSubscriptions:   intOrDefault(cfg.MaxSubscriptions, defaultMaxSubscriptions),
+		maxPolledAddresses: intOrDefault(cfg.MaxPolledAddresses, defaultMaxPolledAddresses),
+		accounts:           make(map[string]*watchedAccount),
+		subscribers:        make(map[uint64]*accountsSubscriber),
+		chanClose:          make(chan struct{}),
+	}, nil
+}
+
+func intOrDefault(value int, defaultValue int) int {
+	if value <= 0 {
+		return defaultValue
+	}
+
+	return value
+}
+
+// Start launches the go routine that will periodically poll the subscribed accounts
+func (an *AccountsNotifier) Start() {
+	go an.run()
+}
+
+func (an *AccountsNotifier) run() {
+	ticker := time.NewTicker(an.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-an.chanClose:
+			return
+		case <-ticker.C:
+			an.PollAccounts()
+		}
+	}
+}
+
+// Subscribe registers a subscriber for the changes of the provided addresses. It returns the channel on
+// which the changes are pushed and the function that cancels the subscription. The channel is closed when
+// the subscription ends. If an address is already polled, its current state is pushed right away. A
+// subscription that would exceed the maximum number of subscriptions or of polled addresses is refused
+func (an *AccountsNotifier) Subscribe(addresses []string) (<-chan *data.AccountChange, func(), error) {
+	uniqueAddresses, err := an.checkAddresses(addresses)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	an.mutSubscriptions.Lock()
+	defer an.mutSubscriptions.Unlock()
+
+	if an.isClosed {
+		return nil, nil, ErrAccountsNotifierClosed
+	}
+	if len(an.subscribers) >= an.maxSubscriptions {
+		return nil, nil, ErrTooManySubscriptions
+	}
+	numNewAddresses := 0
+	for _, address := range uniqueAddresses {
+		_, isPolled := an.accounts[address]
+		if !isPolled {
+			numNewAddresses++
+		}
+	}
+	if len(an.accounts)+numNewAddresses > an.maxPolledAddresses {
+		return nil, nil, ErrTooManyPolledAddresses
+	}
+
+	an.lastSubscriberId++
+	subscriber := &accountsSubscriber{
+		id:          an.lastSubscriberId,
+		addresses:   uniqueAddresses,
+		chanChanges: make(chan *data.AccountChange, len(uniqueAddresses)+accountChangesBufferSize),
+	}
+	an.subscribers[subscriber.id] = subscriber
+
+	for _, address := range uniqueAddresses {
+		account, ok := an.accounts[address]
+		if !ok {
+			account = &watchedAccount{
+				subscribers: make(map[uint64]*accountsSubscriber),
+			}
+			an.accounts[address] = account
+		}
+
+		account.subscribers[subscriber.id] = subscriber
+		if account.lastAccount != nil {
+			subscriber.chanChanges <- an.newAccountChange(address, nil, account.lastAccount)
+		}
+	}
+
+	unsubscribe := func() {
+		an.mutSubscriptions.Lock()
+		an.removeSubscriber(subscriber)
+		an.mutSubscriptions.Unlock()
+	}
+
+	return subscriber.chanChanges, unsubscribe, nil
+}
+
+// checkAddresses returns the distinct provided addresses converted to hex, so an account subscribed in
+// both formats is polled once
+func (an *AccountsNotifier) checkAddresses(addresses []string) ([]string, error) {
+	if len(addresses) == 0 {
+		return nil, ErrEmptyAddressesList
+	}
+	if len(addresses) > an.maxAddresses {
+		return nil, ErrTooManyAddresses
+	}
+
+	uniqueAddresses := make([]string, 0, len(addresses))
+	seen := make(map[string]struct{}, len(addresses))
+	for _, providedAddress := range addresses {
+		address, err := normalizeAddress(an.codec, providedAddress)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", providedAddress, err)
+		}
+
+		_, ok := seen[address]
+		if ok {
+			continue
+		}
+		seen[address] = struct{}{}
+		uniqueAddresses = append(uniqueAddresses, address)
+	}
+
+	return uniqueAddresses, nil
+}
+
+// removeSubscriber should be called under mutSubscriptions. The addresses left without subscribers
+// are no longer polled
+func (an *AccountsNotifier) removeSubscriber(subscriber *accountsSubscriber) {
+	_, ok := an.subscribers[subscriber.id]
+	if !ok {
+		return
+	}
+
+	delete(an.subscribers, subscriber.id)
+	for _, address := range subscriber.addresses {
+		account, found := an.accounts[address]
+		if !found {
+			continue
+		}
+
+		delete(account.subscribers, subscriber.id)
+		if len(account.subscribers) == 0 {
+			delete(an.accounts, address)
+		}
+	}
+
+	close(subscriber.chanChanges)
+}
+
+// PollAccounts requests, once and with a bounded concurrency, all the subscribed accounts and pushes
+// their changes to the subscribers
+func (an *AccountsNotifier) PollAccounts() {
+	an.mutSubscriptions.Lock()
+	addresses := make([]string, 0, len(an.accounts))
+	for address := range an.accounts {
+		addresses = append(addresses, address)
+	}
+	an.mutSubscriptions.Unlock()
+
+	semaphore := make(chan struct{}, maxConcurrentAccountPolls)
+	wg := &sync.WaitGroup{}
+	wg.Add(len(addresses))
+	for _, address := range addresses {
+		semaphore <- struct{}{}
+		go func(address string) {
+			an.pollAccount(address)
+			<-semaphore
+			wg.Done()
+		}(address)
+	}
+	wg.Wait()
+}
+
+func (an *AccountsNotifier) pollAccount(address string) {
+	ctx, cancel := context.WithTimeout(context.Background(), accountPollTimeout)
+	defer cancel()
+
+	account, err := an.accountGetter.GetAccount(ctx, address)
+	if err != nil {
+		log.Warn(fmt.Sprintf("could not poll account %s: %s", address, err.Error()))
+		return
+	}
+
+	an.notifyIfChanged(address, account)
+}
+
+func (an *AccountsNotifier) notifyIfChanged(address string, account *data.Account) {
+	an.mutSubscriptions.Lock()
+	defer an.mutSubscriptions.Unlock()
+
+	watched, ok := an.accounts[address]
+	if !ok {
+		return
+	}
+
+	previous := watched.lastAccount
+	if previous != nil && previous.Nonce == account.Nonce && previous.Balance == account.Balance {
+		return
+	}
+	watched.lastAccount = account
+
+	change := an.newAccountChange(address, previous, account)
+	slowSubscribers := make([]*accountsSubscriber, 0)
+	for _, subscriber := range watched.subscribers {
+		select {
+		case subscriber.chanChanges <- change:
+		default:
+			slowSubscribers = append(slowSubscribers, subscriber)
+		}
+	}
+
+	for _, subscriber := range slowSubscribers {
+		log.Warn(fmt.Sprintf("dropping accounts subscriber %d as it does not consume its changes", subscriber.id))
+		an.removeSubscriber(subscriber)
+	}
+}
+
+func (an *AccountsNotifier) newAccountChange(
+	address string,
+	previous *data.Account,
+	current *data.Account,
+) *data.AccountChange {
+
+	change := &data.AccountChange{
+		Address: fromObserverAddress(an.codec, address),
+		Nonce:   current.Nonce,
+		Balance: current.Balance,
+	}
+	if previous != nil {
+		change.PreviousNonce = previous.Nonce
+		change.PreviousBalance = previous.Balance
+	}
+
+	return change
+}
+
+// NumSubscribedAddresses returns the number of distinct addresses currently polled
+func (an *AccountsNotifier) NumSubscribedAddresses() int {
+	an.mutSubscriptions.Lock()
+	defer an.mutSubscriptions.Unlock()
+
+	return len(an.accounts)
+}
+
+// Close stops the polling go routine and ends all the subscriptions
+func (an *AccountsNotifier) Close() {
+	an.closeOnce.Do(func() {
+		close(an.chanClose)
+
+		an.mutSubscriptions.Lock()
+		defer an.mutSubscriptions.Unlock()
+
+		an.isClosed = true
+		for _, subscriber := range an.subscribers {
+			an.removeSubscriber(subscriber)
+		}
+	})
+}
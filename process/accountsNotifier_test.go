@@ -0,0 +1,177 @@
+package process_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/numbatx/numbat-proxy/config"
+	"github.com/numbatx/numbat-proxy/data"
+	"github.com/numbatx/numbat-proxy/process"
+	"github.com/numbatx/numbat-proxy/process/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func createAccountsNotifierStub(balances *sync.Map, numCalls *int32) *mock.AccountGetterStub {
+	return &mock.AccountGetterStub{
+		GetAccountCalled: func(ctx context.Context, address string) (*data.Account, error) {
+			atomic.AddInt32(numCalls, 1)
+			balance, _ := balances.LoadOrStore(address, "0")
+
+			return &data.Account{Address: address, Balance: balance.(string)}, nil
+		},
+	}
+}
+
+func TestNewAccountsNotifier_NilAccountGetterShouldErr(t *testing.T) {
+	t.Parallel()
+
+	an, err := process.NewAccountsNotifier(nil, createHexAddressCodec(), config.AccountsStreamConfig{})
+
+	assert.Nil(t, an)
+	assert.Equal(t, process.ErrNilAccountGetter, err)
+}
+
+func TestAccountsNotifier_SubscribeInvalidAddressesShouldErr(t *testing.T) {
+	t.Parallel()
+
+	an, _ := process.NewAccountsNotifier(&mock.AccountGetterStub{}, createHexAddressCodec(), config.AccountsStreamConfig{
+		MaxAddressesPerSubscription: 2,
+	})
+
+	_, _, err := an.Subscribe(make([]string, 0))
+	assert.Equal(t, process.ErrEmptyAddressesList, err)
+
+	_, _, err = an.Subscribe([]string{"aa", "bb", "cc"})
+	assert.Equal(t, process.ErrTooManyAddresses, err)
+
+	_, _, err = an.Subscribe([]string{"aa", "not hex"})
+	assert.True(t, errors.Is(err, process.ErrInvalidAddress))
+}
+
+func TestAccountsNotifier_SubscribeOverTheMaxSubscriptionsShouldErr(t *testing.T) {
+	t.Parallel()
+
+	an, _ := process.NewAccountsNotifier(&mock.AccountGetterStub{}, createHexAddressCodec(), config.AccountsStreamConfig{
+		MaxSubscriptions: 1,
+	})
+
+	_, unsubscribe, err := an.Subscribe([]string{"aa"})
+	assert.Nil(t, err)
+
+	_, _, err = an.Subscribe([]string{"aa"})
+	assert.Equal(t, process.ErrTooManySubscriptions, err)
+
+	unsubscribe()
+	_, _, err = an.Subscribe([]string{"aa"})
+	assert.Nil(t, err)
+}
+
+func TestAccountsNotifier_SubscribeOverTheMaxPolledAddressesShouldErr(t *testing.T) {
+	t.Parallel()
+
+	an, _ := process.NewAccountsNotifier(&mock.AccountGetterStub{}, createHexAddressCodec(), config.AccountsStreamConfig{
+		MaxPolledAddresses: 2,
+	})
+
+	_, _, err := an.Subscribe([]string{"aa", "bb"})
+	assert.Nil(t, err)
+
+	// the already polled addresses do not count
+	_, _, err = an.Subscribe([]string{"bb", "aa"})
+	assert.Nil(t, err)
+
+	_, _, err = an.Subscribe([]string{"aa", "cc"})
+	assert.Equal(t, process.ErrTooManyPolledAddresses, err)
+}
+
+func TestAccountsNotifier_PollAccountsShouldDeduplicateAndPushChanges(t *testing.T) {
+	t.Parallel()
+
+	balances := &sync.Map{}
+	numCalls := int32(0)
+	an, _ := process.NewAccountsNotifier(createAccountsNotifierStub(balances, &numCalls), createHexAddressCodec(), config.AccountsStreamConfig{})
+
+	changes1, unsubscribe1, err := an.Subscribe([]string{"aa", "bb"})
+	assert.Nil(t, err)
+	changes2, unsubscribe2, _ := an.Subscribe([]string{"aa", "aa"})
+	assert.Equal(t, 2, an.NumSubscribedAddresses())
+
+	an.PollAccounts()
+	assert.Equal(t, int32(2), atomic.LoadInt32(&numCalls))
+	assert.Equal(t, 2, len(changes1))
+	assert.Equal(t, &data.AccountChange{Address: "aa", Balance: "0"}, <-changes2)
+
+	an.PollAccounts()
+	assert.Equal(t, 2, len(changes1))
+	assert.Equal(t, 0, len(changes2))
+
+	balances.Store("aa", "10")
+	an.PollAccounts()
+	assert.Equal(t, &data.AccountChange{Address: "aa", Balance: "10", PreviousBalance: "0"}, <-changes2)
+
+	unsubscribe1()
+	_, isOpen := <-drain(changes1)
+	assert.False(t, isOpen)
+	assert.Equal(t, 1, an.NumSubscribedAddresses())
+
+	unsubscribe2()
+	assert.Equal(t, 0, an.NumSubscribedAddresses())
+}
+
+func TestAccountsNotifier_SubscribeBothAddressFormatsShouldPollOnce(t *testing.T) {
+	t.Parallel()
+
+	codec := createBech32AddressCodec()
+	bech32Address := codec.EncodeAddress([]byte{0xaa})
+	numCalls := int32(0)
+	an, _ := process.NewAccountsNotifier(createAccountsNotifierStub(&sync.Map{}, &numCalls), codec, config.AccountsStreamConfig{})
+
+	changes, _, err := an.Subscribe([]string{"aa", bech32Address})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, an.NumSubscribedAddresses())
+
+	an.PollAccounts()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&numCalls))
+	assert.Equal(t, &data.AccountChange{Address: bech32Address, Balance: "0"}, <-changes)
+}
+
+func TestAccountsNotifier_SubscribeToPolledAddressShouldReceiveCurrentState(t *testing.T) {
+	t.Parallel()
+
+	numCalls := int32(0)
+	an, _ := process.NewAccountsNotifier(createAccountsNotifierStub(&sync.Map{}, &numCalls), createHexAddressCodec(), config.AccountsStreamConfig{})
+
+	_, unsubscribe, _ := an.Subscribe([]string{"aa"})
+	defer unsubscribe()
+	an.PollAccounts()
+
+	changes, _, _ := an.Subscribe([]string{"aa"})
+
+	assert.Equal(t, &data.AccountChange{Address: "aa", Balance: "0"}, <-changes)
+}
+
+func TestAccountsNotifier_CloseShouldEndSubscriptions(t *testing.T) {
+	t.Parallel()
+
+	an, _ := process.NewAccountsNotifier(&mock.AccountGetterStub{}, createHexAddressCodec(), config.AccountsStreamConfig{})
+	changes, unsubscribe, _ := an.Subscribe([]string{"aa"})
+
+	an.Close()
+	_, isOpen := <-changes
+	unsubscribe()
+	_, _, err := an.Subscribe([]string{"aa"})
+
+	assert.False(t, isOpen)
+	assert.Equal(t, process.ErrAccountsNotifierClosed, err)
+}
+
+func drain(changes <-chan *data.AccountChange) <-chan *data.AccountChange {
+	for len(changes) > 0 {
+		<-changes
+	}
+
+	return changes
+}
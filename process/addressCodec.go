@@ -0,0 +1,132 @@
+package process
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/numbatx/numbat-proxy/config"
+)
+
+// HexAddressFormat formats the addresses as hex strings, the format expected by the observers
+const HexAddressFormat = "hex"
+
+// Bech32AddressFormat formats the addresses as bech32 strings, human readable and covered by a checksum
+const Bech32AddressFormat = "bech32"
+
+// DefaultBech32Prefix is the human readable part of the bech32 addresses when none is configured
+const DefaultBech32Prefix = "numbat"
+
+const maxBech32PrefixLength = 83
+
+// MultiFormatAddressCodec decodes the addresses provided either as hex or as bech32 strings and encodes
+// the addresses in the configured output format
+type MultiFormatAddressCodec struct {
+	outputFormat string
+	bech32Prefix string
+}
+
+// NewAddressCodec creates a new instance of MultiFormatAddressCodec. An empty output format defaults to hex
+func NewAddressCodec(cfg config.AddressesConfig) (*MultiFormatAddressCodec, error) {
+	outputFormat := cfg.OutputFormat
+	if outputFormat == "" {
+		outputFormat = HexAddressFormat
+	}
+	if outputFormat != HexAddressFormat && outputFormat != Bech32AddressFormat {
+		return nil, fmt.Errorf("%w: unknown output format %s", ErrInvalidAddressesConfig, outputFormat)
+	}
+
+	prefix := cfg.Bech32Prefix
+	if prefix == "" {
+		prefix = DefaultBech32Prefix
+	}
+	if !isValidBech32Prefix(prefix) {
+		return nil, fmt.Errorf("%w: invalid bech32 prefix %s", ErrInvalidAddressesConfig, prefix)
+	}
+
+	return &MultiFormatAddressCodec{
+		outputFormat: outputFormat,
+		bech32Prefix: prefix,
+	}, nil
+}
+
+func isValidBech32Prefix(prefix string) bool {
+	if len(prefix) > maxBech32PrefixLength || strings.ToLower(prefix) != prefix {
+		return false
+	}
+
+	for i := 0; i < len(prefix); i++ {
+		if prefix[i] < 33 || prefix[i] > 126 || prefix[i] == bech32Separator {
+			return false
+		}
+	}
+
+	return true
+}
+
+// DecodeAddress returns the bytes of a hex or bech32 address. The returned errors wrap ErrInvalidAddress and,
+// for a bech32 address with a wrong checksum, ErrInvalidAddressChecksum as well
+func (mfac *MultiFormatAddressCodec) DecodeAddress(address string) ([]byte, error) {
+	if len(address) == 0 {
+		return nil, fmt.Errorf("%w: empty address", ErrInvalidAddress)
+	}
+
+	buff, err := hex.DecodeString(address)
+	if err == nil {
+		return buff, nil
+	}
+	if strings.IndexByte(address, bech32Separator) < 1 {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAddress, err)
+	}
+
+	prefix, buff, err := decodeBech32(address)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidAddress, err)
+	}
+	if prefix != mfac.bech32Prefix {
+		return nil, fmt.Errorf("%w: bech32 prefix %s, expected %s", ErrInvalidAddress, prefix, mfac.bech32Prefix)
+	}
+
+	return buff, nil
+}
+
+// EncodeAddress returns the address in the configured output format
+func (mfac *MultiFormatAddressCodec) EncodeAddress(addressBuff []byte) string {
+	if mfac.outputFormat == Bech32AddressFormat {
+		return encodeBech32(mfac.bech32Prefix, addressBuff)
+	}
+
+	return hex.EncodeToString(addressBuff)
+}
+
+// toObserverAddress returns the address in the hex format expected by the observers. The hex addresses are
+// returned as provided
+func toObserverAddress(codec AddressCodec, address string) (string, error) {
+	_, err := hex.DecodeString(address)
+	if err == nil && len(address) > 0 {
+		return address, nil
+	}
+
+	return normalizeAddress(codec, address)
+}
+
+// normalizeAddress returns the address as a lowercase hex string, the same for all the accepted formats
+func normalizeAddress(codec AddressCodec, address string) (string, error) {
+	addressBuff, err := codec.DecodeAddress(address)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(addressBuff), nil
+}
+
+// fromObserverAddress formats an address received from an observer. Addresses that are not hex are
+// returned unchanged
+func fromObserverAddress(codec AddressCodec, address string) string {
+	addressBuff, err := hex.DecodeString(address)
+	if err != nil || len(addressBuff) == 0 {
+		return address
+	}
+
+	return codec.EncodeAddress(addressBuff)
+}
@@ -0,0 +1,110 @@
+package process_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/numbatx/numbat-proxy/config"
+	"github.com/numbatx/numbat-proxy/process"
+	"github.com/stretchr/testify/assert"
+)
+
+// bip173Address and bip173AddressHex are a valid bech32 string from the BIP-0173 test vectors and its content
+const bip173Address = "abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw"
+const bip173AddressHex = "00443214c74254b635cf84653a56d7c675be77df"
+
+func createHexAddressCodec() process.AddressCodec {
+	codec, _ := process.NewAddressCodec(config.AddressesConfig{})
+
+	return codec
+}
+
+func createBech32AddressCodec() process.AddressCodec {
+	codec, _ := process.NewAddressCodec(config.AddressesConfig{OutputFormat: process.Bech32AddressFormat})
+
+	return codec
+}
+
+func TestNewAddressCodec_InvalidConfigShouldErr(t *testing.T) {
+	t.Parallel()
+
+	codec, err := process.NewAddressCodec(config.AddressesConfig{OutputFormat: "base64"})
+	assert.Nil(t, codec)
+	assert.True(t, errors.Is(err, process.ErrInvalidAddressesConfig))
+
+	codec, err = process.NewAddressCodec(config.AddressesConfig{Bech32Prefix: "Numbat"})
+	assert.Nil(t, codec)
+	assert.True(t, errors.Is(err, process.ErrInvalidAddressesConfig))
+}
+
+func TestMultiFormatAddressCodec_DecodeAddressShouldAcceptHexAndBech32(t *testing.T) {
+	t.Parallel()
+
+	codec, _ := process.NewAddressCodec(config.AddressesConfig{Bech32Prefix: "abcdef"})
+
+	fromHex, err := codec.DecodeAddress(bip173AddressHex)
+	assert.Nil(t, err)
+	fromBech32, err := codec.DecodeAddress(bip173Address)
+	assert.Nil(t, err)
+	fromUpperCaseBech32, err := codec.DecodeAddress(strings.ToUpper(bip173Address))
+	assert.Nil(t, err)
+
+	assert.Equal(t, fromHex, fromBech32)
+	assert.Equal(t, fromHex, fromUpperCaseBech32)
+}
+
+func TestMultiFormatAddressCodec_DecodeAddressWrongChecksumShouldErr(t *testing.T) {
+	t.Parallel()
+
+	codec, _ := process.NewAddressCodec(config.AddressesConfig{Bech32Prefix: "abcdef"})
+
+	_, err := codec.DecodeAddress(bip173Address[:len(bip173Address)-1] + "q")
+
+	assert.True(t, errors.Is(err, process.ErrInvalidAddress))
+	assert.True(t, errors.Is(err, process.ErrInvalidAddressChecksum))
+}
+
+func TestMultiFormatAddressCodec_DecodeAddressInvalidAddressShouldErr(t *testing.T) {
+	t.Parallel()
+
+	codec, _ := process.NewAddressCodec(config.AddressesConfig{})
+
+	invalidAddresses := []string{
+		"",
+		"not an address",
+		"aab",
+		bip173Address,
+		"numbat1qqqqqqbqqqqq",
+		"Numbat1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw",
+	}
+	for _, address := range invalidAddresses {
+		_, err := codec.DecodeAddress(address)
+		assert.True(t, errors.Is(err, process.ErrInvalidAddress), address)
+	}
+}
+
+func TestMultiFormatAddressCodec_EncodeAddressShouldUseOutputFormat(t *testing.T) {
+	t.Parallel()
+
+	addressBuff := []byte(strings.Repeat("a", 32))
+
+	hexCodec := createHexAddressCodec()
+	assert.Equal(t, "61616161616161616161616161616161"+"61616161616161616161616161616161",
+		hexCodec.EncodeAddress(addressBuff))
+
+	bech32Codec := createBech32AddressCodec()
+	encoded := bech32Codec.EncodeAddress(addressBuff)
+	assert.True(t, strings.HasPrefix(encoded, process.DefaultBech32Prefix+"1"))
+
+	decoded, err := bech32Codec.DecodeAddress(encoded)
+	assert.Nil(t, err)
+	assert.Equal(t, addressBuff, decoded)
+
+	bip173Codec, _ := process.NewAddressCodec(config.AddressesConfig{
+		OutputFormat: process.Bech32AddressFormat,
+		Bech32Prefix: "abcdef",
+	})
+	decoded, _ = bip173Codec.DecodeAddress(bip173AddressHex)
+	assert.Equal(t, bip173Address, bip173Codec.EncodeAddress(decoded))
+}
@@ -2,8 +2,14 @@ package process
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"io"
 	"net/http"
+	"reflect"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin/json"
 	"github.com/numbatx/gn-numbat/core/logger"
@@ -11,6 +17,7 @@ import (
 	"github.com/numbatx/gn-numbat/sharding"
 	"github.com/numbatx/numbat-proxy/config"
 	"github.com/numbatx/numbat-proxy/data"
+	"github.com/numbatx/numbat-proxy/metrics"
 )
 
 var log = logger.DefaultLogger()
@@ -23,33 +30,119 @@ type BaseProcessor struct {
 	mutState         sync.RWMutex
 	shardCoordinator sharding.Coordinator
 	observers        map[uint32][]*data.Observer
-
-	httpClient *http.Client
+	allObservers     []*data.Observer
+	observerShards   map[string]string
+	selector         ObserverSelector
+	selection        string
+	hedging          config.HedgingConfig
+
+	mutHealth          sync.RWMutex
+	unhealthyObservers map[string]struct{}
+	reachableObservers map[string]struct{}
+
+	httpClients *httpClients
+	coalescer   *requestsCoalescer
+
+	mutLatencies  sync.Mutex
+	readLatencies map[uint32]*latencyTracker
+	metrics       *observerMetrics
 }
 
-// NewBaseProcessor creates a new instance of BaseProcessor struct
-func NewBaseProcessor(addressConverter state.AddressConverter) (*BaseProcessor, error) {
+// NewBaseProcessor creates a new instance of BaseProcessor struct. The statistics of the calls made to the
+// observers are registered in the provided metrics registry
+func NewBaseProcessor(addressConverter state.AddressConverter, registry *metrics.Registry) (*BaseProcessor, error) {
 	if addressConverter == nil {
 		return nil, ErrNilAddressConverter
 	}
+	if registry == nil {
+		return nil, ErrNilMetricsRegistry
+	}
+
+	// the default settings load no file, so they can not fail
+	defaultHttpClients, _ := newHttpClients(config.HttpClientConfig{})
+
+	bp := &BaseProcessor{
+		observers:          make(map[uint32][]*data.Observer),
+		observerShards:     make(map[string]string),
+		unhealthyObservers: make(map[string]struct{}),
+		reachableObservers: make(map[string]struct{}),
+		selector:           NewOrderedSelector(),
+		selection:          OrderedSelection,
+		httpClients:        defaultHttpClients,
+		coalescer:          newRequestsCoalescer(),
+		readLatencies:      make(map[uint32]*latencyTracker),
+		addressConverter:   addressConverter,
+	}
+
+	var err error
+	bp.metrics, err = newObserverMetrics(registry)
+	if err != nil {
+		return nil, err
+	}
+
+	err = registry.NewGaugeFunc(
+		"numbat_proxy_observers",
+		"Number of configured observers, by shard",
+		[]string{"shard"},
+		bp.collectObserversCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = registry.NewGaugeFunc(
+		"numbat_proxy_healthy_observers",
+		"Number of observers that passed their last health check, by shard",
+		[]string{"shard"},
+		bp.collectHealthyObserversCount,
+	)
+	if err != nil {
+		return nil, err
+	}
 
-	return &BaseProcessor{
-		observers:        make(map[uint32][]*data.Observer),
-		httpClient:       http.DefaultClient,
-		addressConverter: addressConverter,
-	}, nil
+	return bp, nil
 }
 
-// ApplyConfig applies a config on a base processor
-func (bp *BaseProcessor) ApplyConfig(cfg *config.Config) error {
+// observersLayout holds the observers of a config, grouped by shard
+type observersLayout struct {
+	shardCoordinator sharding.Coordinator
+	observers        map[uint32][]*data.Observer
+	observerShards   map[string]string
+}
+
+// ValidateConfig returns the error ApplyConfig would return for the config, without applying it
+func (bp *BaseProcessor) ValidateConfig(cfg *config.Config) error {
+	_, err := parseObserversLayout(cfg)
+	if err != nil {
+		return err
+	}
+
+	_, err = NewObserverSelector(cfg.GeneralSettings.ObserversSelection)
+	if err != nil {
+		return err
+	}
+
+	clients, err := newHttpClients(cfg.HttpClient)
+	if err != nil {
+		return err
+	}
+	clients.closeIdleConnections()
+
+	return nil
+}
+
+func parseObserversLayout(cfg *config.Config) (*observersLayout, error) {
 	if cfg == nil {
-		return ErrNilConfig
+		return nil, ErrNilConfig
 	}
 	if len(cfg.Observers) == 0 {
-		return ErrEmptyObserversList
+		return nil, ErrEmptyObserversList
 	}
 
-	newObservers := make(map[uint32][]*data.Observer)
+	layout := &observersLayout{
+		observers:      make(map[uint32][]*data.Observer),
+		observerShards: make(map[string]string, len(cfg.Observers)),
+	}
 	maxShardId := uint32(0)
 	for _, observer := range cfg.Observers {
 		shardId := observer.ShardId
@@ -57,33 +150,286 @@ func (bp *BaseProcessor) ApplyConfig(cfg *config.Config) error {
 			maxShardId = shardId
 		}
 
-		newObservers[shardId] = append(newObservers[shardId], observer)
+		layout.observers[shardId] = append(layout.observers[shardId], observer)
+		layout.observerShards[observer.Address] = formatShardId(shardId)
+	}
+
+	var err error
+	layout.shardCoordinator, err = sharding.NewMultiShardCoordinator(maxShardId+1, 0)
+	if err != nil {
+		return nil, err
 	}
 
-	newShardCoordinator, err := sharding.NewMultiShardCoordinator(maxShardId+1, 0)
+	if cfg.Hedging.Enabled {
+		isPercentileValid := cfg.Hedging.LatencyPercentile > 0 && cfg.Hedging.LatencyPercentile < 100
+		if !isPercentileValid || cfg.Hedging.MinDelayInMs < 0 {
+			return nil, ErrInvalidHedgingConfig
+		}
+	}
+
+	return layout, nil
+}
+
+// ApplyConfig applies a config on a base processor
+func (bp *BaseProcessor) ApplyConfig(cfg *config.Config) error {
+	layout, err := parseObserversLayout(cfg)
 	if err != nil {
 		return err
 	}
 
+	newSelection := cfg.GeneralSettings.ObserversSelection
+	if newSelection == "" {
+		newSelection = OrderedSelection
+	}
+
 	bp.mutState.Lock()
-	bp.shardCoordinator = newShardCoordinator
-	bp.observers = newObservers
-	bp.mutState.Unlock()
+	defer bp.mutState.Unlock()
+
+	// the current selector is kept when the strategy does not change so its statistics are not lost
+	newSelector := bp.selector
+	if newSelection != bp.selection {
+		newSelector, err = NewObserverSelector(newSelection)
+		if err != nil {
+			return err
+		}
+	}
+
+	// the http clients are rebuilt only when their settings change so the pooled connections are reused
+	if bp.lastConfig == nil || !reflect.DeepEqual(bp.lastConfig.HttpClient, cfg.HttpClient) {
+		clients, errClients := newHttpClients(cfg.HttpClient)
+		if errClients != nil {
+			return errClients
+		}
+
+		oldHttpClients := bp.httpClients
+		bp.httpClients = clients
+		oldHttpClients.closeIdleConnections()
+	}
+
+	bp.lastConfig = cfg
+	bp.shardCoordinator = layout.shardCoordinator
+	bp.observers = layout.observers
+	bp.observerShards = layout.observerShards
+	bp.allObservers = cfg.Observers
+	bp.selector = newSelector
+	bp.selection = newSelection
+	bp.hedging = cfg.Hedging
 
 	return nil
 }
 
-// GetObservers returns the registered observers on a shard
+// GetObservers returns the registered healthy observers on a shard, in the order given by the
+// configured selection strategy. If none of the shard's observers is healthy, all of them are returned
 func (bp *BaseProcessor) GetObservers(shardId uint32) ([]*data.Observer, error) {
 	bp.mutState.RLock()
-	defer bp.mutState.RUnlock()
-
 	observers := bp.observers[shardId]
+	selector := bp.selector
+	bp.mutState.RUnlock()
+
 	if len(observers) == 0 {
 		return nil, ErrMissingObserver
 	}
 
-	return observers, nil
+	return selector.Select(shardId, bp.filterHealthyObservers(observers)), nil
+}
+
+func (bp *BaseProcessor) filterHealthyObservers(observers []*data.Observer) []*data.Observer {
+	bp.mutHealth.RLock()
+	defer bp.mutHealth.RUnlock()
+
+	healthyObservers := make([]*data.Observer, 0, len(observers))
+	for _, observer := range observers {
+		_, isUnhealthy := bp.unhealthyObservers[observer.Address]
+		if !isUnhealthy {
+			healthyObservers = append(healthyObservers, observer)
+		}
+	}
+	if len(healthyObservers) == 0 {
+		return observers
+	}
+
+	return healthyObservers
+}
+
+// GetAllObservers returns all the registered observers, regardless of their shard or health
+func (bp *BaseProcessor) GetAllObservers() []*data.Observer {
+	bp.mutState.RLock()
+	defer bp.mutState.RUnlock()
+
+	return bp.allObservers
+}
+
+// SetObserverHealth marks an observer as healthy or unhealthy. Unhealthy observers are
+// not returned by GetObservers
+func (bp *BaseProcessor) SetObserverHealth(address string, isHealthy bool) {
+	bp.mutHealth.Lock()
+	defer bp.mutHealth.Unlock()
+
+	if isHealthy {
+		delete(bp.unhealthyObservers, address)
+		return
+	}
+
+	bp.unhealthyObservers[address] = struct{}{}
+}
+
+// IsObserverReachable returns true if the observer answered its last request and is not marked as unhealthy
+func (bp *BaseProcessor) IsObserverReachable(address string) bool {
+	bp.mutHealth.RLock()
+	defer bp.mutHealth.RUnlock()
+
+	_, isReachable := bp.reachableObservers[address]
+	_, isUnhealthy := bp.unhealthyObservers[address]
+
+	return isReachable && !isUnhealthy
+}
+
+func (bp *BaseProcessor) setObserverReachable(address string, isReachable bool) {
+	bp.mutHealth.Lock()
+	defer bp.mutHealth.Unlock()
+
+	if isReachable {
+		bp.reachableObservers[address] = struct{}{}
+		return
+	}
+
+	delete(bp.reachableObservers, address)
+}
+
+// GetLoadedConfig returns the last successfully applied config or nil if none was applied yet
+func (bp *BaseProcessor) GetLoadedConfig() *config.Config {
+	bp.mutState.RLock()
+	defer bp.mutState.RUnlock()
+
+	return bp.lastConfig
+}
+
+// GetObserversMap returns a copy of the observers registered on each shard
+func (bp *BaseProcessor) GetObserversMap() map[uint32][]*data.Observer {
+	bp.mutState.RLock()
+	defer bp.mutState.RUnlock()
+
+	observers := make(map[uint32][]*data.Observer, len(bp.observers))
+	for shardId, shardObservers := range bp.observers {
+		observers[shardId] = append([]*data.Observer(nil), shardObservers...)
+	}
+
+	return observers
+}
+
+// GetNumShards returns the number of shards known by the shard coordinator or 0 if no config was applied yet
+func (bp *BaseProcessor) GetNumShards() uint32 {
+	bp.mutState.RLock()
+	defer bp.mutState.RUnlock()
+
+	if bp.shardCoordinator == nil {
+		return 0
+	}
+
+	return bp.shardCoordinator.NumberOfShards()
+}
+
+// GetShardIds returns, in ascending order, the ids of the shards that have observers
+func (bp *BaseProcessor) GetShardIds() []uint32 {
+	bp.mutState.RLock()
+	defer bp.mutState.RUnlock()
+
+	shardIds := make([]uint32, 0, len(bp.observers))
+	for shardId := range bp.observers {
+		shardIds = append(shardIds, shardId)
+	}
+	sort.Slice(shardIds, func(i, j int) bool {
+		return shardIds[i] < shardIds[j]
+	})
+
+	return shardIds
+}
+
+// GetHedgingDelay returns the time after which an unanswered read is also sent to another observer of the
+// shard. It returns 0 when hedging is disabled or when not enough read latencies were measured on the shard yet
+func (bp *BaseProcessor) GetHedgingDelay(shardId uint32) time.Duration {
+	bp.mutState.RLock()
+	hedging := bp.hedging
+	bp.mutState.RUnlock()
+
+	if !hedging.Enabled {
+		return 0
+	}
+
+	delay, ok := bp.shardReadLatencies(shardId).percentile(hedging.LatencyPercentile)
+	if !ok {
+		return 0
+	}
+
+	minDelay := time.Duration(hedging.MinDelayInMs) * time.Millisecond
+	if delay < minDelay {
+		return minDelay
+	}
+
+	return delay
+}
+
+// RecordReadLatency records the time a client read took to be answered by an observer of the shard
+func (bp *BaseProcessor) RecordReadLatency(shardId uint32, latency time.Duration) {
+	bp.shardReadLatencies(shardId).add(latency)
+}
+
+func (bp *BaseProcessor) shardReadLatencies(shardId uint32) *latencyTracker {
+	bp.mutLatencies.Lock()
+	defer bp.mutLatencies.Unlock()
+
+	tracker, ok := bp.readLatencies[shardId]
+	if !ok {
+		tracker = newLatencyTracker()
+		bp.readLatencies[shardId] = tracker
+	}
+
+	return tracker
+}
+
+// CountFailover records that a request was retried on another observer of the shard
+func (bp *BaseProcessor) CountFailover(shardId uint32) {
+	bp.metrics.countFailover(shardId)
+}
+
+func (bp *BaseProcessor) collectObserversCount() []metrics.GaugeValue {
+	bp.mutState.RLock()
+	defer bp.mutState.RUnlock()
+
+	values := make([]metrics.GaugeValue, 0, len(bp.observers))
+	for shardId, observers := range bp.observers {
+		values = append(values, metrics.GaugeValue{
+			LabelValues: []string{formatShardId(shardId)},
+			Value:       float64(len(observers)),
+		})
+	}
+
+	return values
+}
+
+func (bp *BaseProcessor) collectHealthyObserversCount() []metrics.GaugeValue {
+	bp.mutState.RLock()
+	defer bp.mutState.RUnlock()
+	bp.mutHealth.RLock()
+	defer bp.mutHealth.RUnlock()
+
+	values := make([]metrics.GaugeValue, 0, len(bp.observers))
+	for shardId, observers := range bp.observers {
+		numHealthy := 0
+		for _, observer := range observers {
+			_, isUnhealthy := bp.unhealthyObservers[observer.Address]
+			if !isUnhealthy {
+				numHealthy++
+			}
+		}
+
+		values = append(values, metrics.GaugeValue{
+			LabelValues: []string{formatShardId(shardId)},
+			Value:       float64(numHealthy),
+		})
+	}
+
+	return values
 }
 
 // ComputeShardId computes the shard id in which the account resides
@@ -99,37 +445,44 @@ func (bp *BaseProcessor) ComputeShardId(addressBuff []byte) (uint32, error) {
 	return bp.shardCoordinator.ComputeId(address), nil
 }
 
-// CallGetRestEndPoint calls an external end point (sends a request on a node)
+// CallGetRestEndPoint calls an external end point (sends a request on a node). Identical calls that are
+// in flight at the same time share a single request, which carries the request id of the first caller.
+// The call returns when the provided context is done, while the shared request is canceled only when all
+// its callers are done
 func (bp *BaseProcessor) CallGetRestEndPoint(
+	ctx context.Context,
 	address string,
 	path string,
 	value interface{},
 ) error {
 
-	req, err := http.NewRequest("GET", address+path, nil)
-	if err != nil {
-		return err
-	}
+	addContactedObserver(ctx, address)
+	requestId := GetRequestId(ctx)
 
-	userAgent := "Numbat Proxy / 1.0.0 <Requesting data from nodes>"
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("User-Agent", userAgent)
+	body, err := bp.coalescer.do(ctx, address+path, func(ctx context.Context) ([]byte, error) {
+		req, errRequest := http.NewRequestWithContext(ctx, "GET", address+path, nil)
+		if errRequest != nil {
+			return nil, errRequest
+		}
 
-	resp, err := bp.httpClient.Do(req)
+		userAgent := "Numbat Proxy / 1.0.0 <Requesting data from nodes>"
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("User-Agent", userAgent)
+		setRequestIdHeader(req, requestId)
+
+		return bp.doRequest(address, req)
+	})
 	if err != nil {
 		return err
 	}
 
-	defer func() {
-		errNotCritical := resp.Body.Close()
-		log.LogIfError(errNotCritical)
-	}()
-
-	return json.NewDecoder(resp.Body).Decode(value)
+	return json.NewDecoder(bytes.NewReader(body)).Decode(value)
 }
 
-// CallPostRestEndPoint calls an external end point (sends a request on a node)
+// CallPostRestEndPoint calls an external end point (sends a request on a node). The request is
+// canceled when the provided context is done
 func (bp *BaseProcessor) CallPostRestEndPoint(
+	ctx context.Context,
 	address string,
 	path string,
 	data interface{},
@@ -141,7 +494,7 @@ func (bp *BaseProcessor) CallPostRestEndPoint(
 		return err
 	}
 
-	req, err := http.NewRequest("POST", address+path, bytes.NewReader(buff))
+	req, err := http.NewRequestWithContext(ctx, "POST", address+path, bytes.NewReader(buff))
 	if err != nil {
 		return err
 	}
@@ -150,16 +503,76 @@ func (bp *BaseProcessor) CallPostRestEndPoint(
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", userAgent)
+	setRequestIdHeader(req, GetRequestId(ctx))
+	addContactedObserver(ctx, address)
 
-	resp, err := bp.httpClient.Do(req)
+	body, err := bp.doRequest(address, req)
 	if err != nil {
 		return err
 	}
 
+	return json.NewDecoder(bytes.NewReader(body)).Decode(response)
+}
+
+// doRequest sends the request, reads the response body and reports the call to the observer selector and
+// to the metrics. A response with a non 2xx status code is returned as an *ObserverError
+func (bp *BaseProcessor) doRequest(address string, req *http.Request) ([]byte, error) {
+	bp.mutState.RLock()
+	selector := bp.selector
+	httpClient := bp.httpClients.client(address)
+	shard, ok := bp.observerShards[address]
+	bp.mutState.RUnlock()
+	if !ok {
+		shard = unknownShard
+	}
+
+	selector.CallStarted(address)
+	start := time.Now()
+
+	body, err := sendAndRead(httpClient, address, req)
+	duration := time.Since(start)
+
+	// a request canceled by its caller says nothing about the observer
+	if req.Context().Err() != nil {
+		selector.CallCanceled(address)
+		bp.metrics.observeCanceledCall(address, shard)
+		return body, err
+	}
+
+	// the client errors reported by the observer, such as a transaction not found on its shard, are answers
+	// of a healthy observer and do not count as failures for the selection
+	selectorErr := err
+	if !IsRetryableError(err) {
+		selectorErr = nil
+	}
+	selector.CallFinished(address, duration, selectorErr)
+	bp.metrics.observeCall(address, shard, duration, err)
+	var observerErr *ObserverError
+	bp.setObserverReachable(address, err == nil || errors.As(err, &observerErr))
+
+	return body, err
+}
+
+func setRequestIdHeader(req *http.Request, requestId string) {
+	if requestId != "" {
+		req.Header.Set(RequestIdHeader, requestId)
+	}
+}
+
+func sendAndRead(httpClient *http.Client, address string, req *http.Request) ([]byte, error) {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
 	defer func() {
 		errNotCritical := resp.Body.Close()
 		log.LogIfError(errNotCritical)
 	}()
 
-	return json.NewDecoder(resp.Body).Decode(response)
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, newObserverError(address, resp)
+	}
+
+	return io.ReadAll(resp.Body)
 }
@@ -2,15 +2,21 @@ package process_test
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin/json"
 	"github.com/numbatx/gn-numbat/data/state"
 	"github.com/numbatx/numbat-proxy/config"
 	"github.com/numbatx/numbat-proxy/data"
+	"github.com/numbatx/numbat-proxy/metrics"
 	"github.com/numbatx/numbat-proxy/process"
 	"github.com/numbatx/numbat-proxy/process/mock"
 	"github.com/stretchr/testify/assert"
@@ -43,16 +49,25 @@ func createTestHttpServer(
 func TestNewBaseProcessor_WithNilAddressConverterShouldErr(t *testing.T) {
 	t.Parallel()
 
-	bp, err := process.NewBaseProcessor(nil)
+	bp, err := process.NewBaseProcessor(nil, metrics.NewRegistry())
 
 	assert.Nil(t, bp)
 	assert.Equal(t, process.ErrNilAddressConverter, err)
 }
 
+func TestNewBaseProcessor_WithNilMetricsRegistryShouldErr(t *testing.T) {
+	t.Parallel()
+
+	bp, err := process.NewBaseProcessor(&mock.AddressConverterStub{}, nil)
+
+	assert.Nil(t, bp)
+	assert.Equal(t, process.ErrNilMetricsRegistry, err)
+}
+
 func TestNewBaseProcessor_WithValidAddressConverterShouldWork(t *testing.T) {
 	t.Parallel()
 
-	bp, err := process.NewBaseProcessor(&mock.AddressConverterStub{})
+	bp, err := process.NewBaseProcessor(&mock.AddressConverterStub{}, metrics.NewRegistry())
 
 	assert.NotNil(t, bp)
 	assert.Nil(t, err)
@@ -63,7 +78,7 @@ func TestNewBaseProcessor_WithValidAddressConverterShouldWork(t *testing.T) {
 func TestBaseProcessor_ApplyConfigNilCfgShouldErr(t *testing.T) {
 	t.Parallel()
 
-	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{})
+	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{}, metrics.NewRegistry())
 	err := bp.ApplyConfig(nil)
 
 	assert.Equal(t, process.ErrNilConfig, err)
@@ -72,12 +87,33 @@ func TestBaseProcessor_ApplyConfigNilCfgShouldErr(t *testing.T) {
 func TestBaseProcessor_ApplyConfigNoObserversShouldErr(t *testing.T) {
 	t.Parallel()
 
-	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{})
+	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{}, metrics.NewRegistry())
 	err := bp.ApplyConfig(&config.Config{})
 
 	assert.Equal(t, process.ErrEmptyObserversList, err)
 }
 
+func TestBaseProcessor_ValidateConfigShouldNotApplyTheConfig(t *testing.T) {
+	t.Parallel()
+
+	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{}, metrics.NewRegistry())
+	_ = bp.ApplyConfig(&config.Config{Observers: []*data.Observer{{Address: "address0"}}})
+
+	err := bp.ValidateConfig(&config.Config{Observers: []*data.Observer{{Address: "address1"}}})
+	assert.Nil(t, err)
+	observers, _ := bp.GetObservers(0)
+	assert.Equal(t, "address0", observers[0].Address)
+
+	err = bp.ValidateConfig(&config.Config{
+		GeneralSettings: config.GeneralSettingsConfig{ObserversSelection: "unknown"},
+		Observers:       []*data.Observer{{Address: "address1"}},
+	})
+	assert.Equal(t, process.ErrUnknownObserverSelection, err)
+
+	err = bp.ValidateConfig(&config.Config{})
+	assert.Equal(t, process.ErrEmptyObserversList, err)
+}
+
 func TestBaseProcessor_ApplyConfigShouldProcessConfigAndGetShouldWork(t *testing.T) {
 	t.Parallel()
 
@@ -96,7 +132,7 @@ func TestBaseProcessor_ApplyConfigShouldProcessConfigAndGetShouldWork(t *testing
 		},
 	}
 
-	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{})
+	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{}, metrics.NewRegistry())
 	err := bp.ApplyConfig(&config.Config{
 		Observers: observersList,
 	})
@@ -119,7 +155,7 @@ func TestBaseProcessor_ApplyConfigShouldProcessConfigAndGetShouldWork(t *testing
 func TestBaseProcessor_GetObserversEmptyListShouldErr(t *testing.T) {
 	t.Parallel()
 
-	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{})
+	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{}, metrics.NewRegistry())
 	observers, err := bp.GetObservers(0)
 
 	assert.Nil(t, observers)
@@ -148,7 +184,7 @@ func TestBaseProcessor_ComputeShardId(t *testing.T) {
 				BytesField: pubKey,
 			}, nil
 		},
-	})
+	}, metrics.NewRegistry())
 	_ = bp.ApplyConfig(&config.Config{
 		Observers: observersList,
 	})
@@ -179,8 +215,8 @@ func TestBaseProcessor_CallGetRestEndPoint(t *testing.T) {
 	defer server.Close()
 
 	tsRecovered := &testStruct{}
-	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{})
-	err := bp.CallGetRestEndPoint(server.URL, "/some/path", tsRecovered)
+	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{}, metrics.NewRegistry())
+	err := bp.CallGetRestEndPoint(context.Background(), server.URL, "/some/path", tsRecovered)
 
 	assert.Nil(t, err)
 	assert.Equal(t, ts, tsRecovered)
@@ -197,9 +233,439 @@ func TestBaseProcessor_CallPostRestEndPoint(t *testing.T) {
 	fmt.Printf("Server: %s\n", server.URL)
 	defer server.Close()
 
-	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{})
-	err := bp.CallPostRestEndPoint(server.URL, "/some/path", ts, tsRecv)
+	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{}, metrics.NewRegistry())
+	err := bp.CallPostRestEndPoint(context.Background(), server.URL, "/some/path", ts, tsRecv)
+
+	assert.Nil(t, err)
+	assert.Equal(t, ts, tsRecv)
+}
+
+func createHangingHttpServer(chanRelease chan struct{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		select {
+		case <-chanRelease:
+		case <-req.Context().Done():
+		}
+	}))
+}
+
+func TestBaseProcessor_CallGetRestEndPointShouldTimeout(t *testing.T) {
+	chanRelease := make(chan struct{})
+	server := createHangingHttpServer(chanRelease)
+	defer server.Close()
+	defer close(chanRelease)
+
+	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{}, metrics.NewRegistry())
+	_ = bp.ApplyConfig(&config.Config{
+		HttpClient: config.HttpClientConfig{
+			RequestTimeoutInMs: 50,
+		},
+		Observers: []*data.Observer{{Address: server.URL}},
+	})
+
+	start := time.Now()
+	err := bp.CallGetRestEndPoint(context.Background(), server.URL, "/some/path", &testStruct{})
+
+	assert.NotNil(t, err)
+	assert.True(t, time.Since(start) < time.Second)
+}
+
+func TestBaseProcessor_CallGetRestEndPointObserverOverrideShouldApply(t *testing.T) {
+	chanRelease := make(chan struct{})
+	server := createHangingHttpServer(chanRelease)
+	defer server.Close()
+
+	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{}, metrics.NewRegistry())
+	_ = bp.ApplyConfig(&config.Config{
+		HttpClient: config.HttpClientConfig{
+			RequestTimeoutInMs: 50,
+			ObserverOverrides: []*config.ObserverHttpClientConfig{
+				{Address: server.URL, RequestTimeoutInMs: 10000},
+			},
+		},
+		Observers: []*data.Observer{{Address: server.URL}},
+	})
+
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		close(chanRelease)
+	}()
+	err := bp.CallGetRestEndPoint(context.Background(), server.URL, "/some/path", &testStruct{})
+
+	// the server answered with an empty body after the general timeout but before the overridden one
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "EOF")
+}
+
+func TestBaseProcessor_CallPostRestEndPointCanceledContextShouldErr(t *testing.T) {
+	chanRelease := make(chan struct{})
+	server := createHangingHttpServer(chanRelease)
+	defer server.Close()
+	defer close(chanRelease)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{}, metrics.NewRegistry())
+	err := bp.CallPostRestEndPoint(ctx, server.URL, "/some/path", &testStruct{}, &testStruct{})
+
+	assert.NotNil(t, err)
+	assert.Equal(t, context.Canceled, ctx.Err())
+}
+
+func TestBaseProcessor_CallGetRestEndPointErrorStatusShouldReturnObserverError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusInternalServerError)
+		_, _ = rw.Write([]byte(`{"error": "internal node error"}`))
+	}))
+	defer server.Close()
+
+	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{}, metrics.NewRegistry())
+	err := bp.CallGetRestEndPoint(context.Background(), server.URL, "/some/path", &testStruct{})
+
+	observerErr, ok := err.(*process.ObserverError)
+	assert.True(t, ok)
+	assert.Equal(t, server.URL, observerErr.Address)
+	assert.Equal(t, http.StatusInternalServerError, observerErr.StatusCode)
+	assert.Equal(t, "internal node error", observerErr.Message)
+	assert.True(t, process.IsRetryableError(err))
+	assert.Equal(t, "observer responded with status code 500: internal node error", err.Error())
+	assert.NotContains(t, err.Error(), server.URL)
+}
+
+func TestBaseProcessor_ClientErrorStatusShouldNotPenalizeTheObserver(t *testing.T) {
+	notFoundServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusNotFound)
+		_, _ = rw.Write([]byte(`{"error": "transaction was not found"}`))
+	}))
+	defer notFoundServer.Close()
+	slowServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		_, _ = rw.Write([]byte("{}"))
+	}))
+	defer slowServer.Close()
 
+	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{}, metrics.NewRegistry())
+	_ = bp.ApplyConfig(&config.Config{
+		GeneralSettings: config.GeneralSettingsConfig{ObserversSelection: process.EwmaLatencySelection},
+		Observers: []*data.Observer{
+			{Address: slowServer.URL, ShardId: 0},
+			{Address: notFoundServer.URL, ShardId: 0},
+		},
+	})
+
+	err := bp.CallGetRestEndPoint(context.Background(), notFoundServer.URL, "/transaction/aa", &testStruct{})
+	assert.NotNil(t, err)
+	err = bp.CallGetRestEndPoint(context.Background(), slowServer.URL, "/transaction/aa", &testStruct{})
 	assert.Nil(t, err)
+
+	observers, _ := bp.GetObservers(0)
+	assert.Equal(t, notFoundServer.URL, observers[0].Address)
+}
+
+func TestBaseProcessor_CallPostRestEndPointClientErrorStatusShouldNotBeRetryable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusBadRequest)
+		_, _ = rw.Write([]byte("bad request body"))
+	}))
+	defer server.Close()
+
+	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{}, metrics.NewRegistry())
+	err := bp.CallPostRestEndPoint(context.Background(), server.URL, "/some/path", &testStruct{}, &testStruct{})
+
+	observerErr, ok := err.(*process.ObserverError)
+	assert.True(t, ok)
+	assert.Equal(t, "bad request body", observerErr.Message)
+	assert.False(t, process.IsRetryableError(err))
+}
+
+//------- requests coalescing
+
+func createCountingHttpServer(numRequests *int32, chanRelease chan struct{}, response interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(numRequests, 1)
+		select {
+		case <-chanRelease:
+		case <-req.Context().Done():
+			return
+		}
+
+		responseBuff, _ := json.Marshal(response)
+		_, _ = rw.Write(responseBuff)
+	}))
+}
+
+func TestBaseProcessor_CallGetRestEndPointIdenticalCallsShouldBeCoalesced(t *testing.T) {
+	numRequests := int32(0)
+	chanRelease := make(chan struct{})
+	ts := &testStruct{Nonce: 7, Name: "a name"}
+	server := createCountingHttpServer(&numRequests, chanRelease, ts)
+	defer server.Close()
+
+	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{}, metrics.NewRegistry())
+	numCalls := 10
+	results := make([]*testStruct, numCalls)
+	wg := sync.WaitGroup{}
+	wg.Add(numCalls)
+	for i := 0; i < numCalls; i++ {
+		go func(idx int) {
+			results[idx] = &testStruct{}
+			err := bp.CallGetRestEndPoint(context.Background(), server.URL, "/some/path", results[idx])
+			assert.Nil(t, err)
+			wg.Done()
+		}(i)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	close(chanRelease)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&numRequests))
+	for i := 0; i < numCalls; i++ {
+		assert.Equal(t, ts, results[i])
+	}
+}
+
+func TestBaseProcessor_CallGetRestEndPointCanceledWaiterShouldNotAffectOthers(t *testing.T) {
+	numRequests := int32(0)
+	chanRelease := make(chan struct{})
+	ts := &testStruct{Nonce: 7, Name: "a name"}
+	server := createCountingHttpServer(&numRequests, chanRelease, ts)
+	defer server.Close()
+
+	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{}, metrics.NewRegistry())
+	ctxCanceled, cancel := context.WithCancel(context.Background())
+	chanCanceledErr := make(chan error)
+	go func() {
+		chanCanceledErr <- bp.CallGetRestEndPoint(ctxCanceled, server.URL, "/some/path", &testStruct{})
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	tsRecv := &testStruct{}
+	chanErr := make(chan error)
+	go func() {
+		chanErr <- bp.CallGetRestEndPoint(context.Background(), server.URL, "/some/path", tsRecv)
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	cancel()
+	assert.Equal(t, context.Canceled, <-chanCanceledErr)
+
+	close(chanRelease)
+	assert.Nil(t, <-chanErr)
 	assert.Equal(t, ts, tsRecv)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&numRequests))
+}
+
+func TestBaseProcessor_CallGetRestEndPointAllWaitersCanceledShouldCancelRequest(t *testing.T) {
+	numRequests := int32(0)
+	chanRelease := make(chan struct{})
+	server := createCountingHttpServer(&numRequests, chanRelease, &testStruct{})
+	defer server.Close()
+	defer close(chanRelease)
+
+	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{}, metrics.NewRegistry())
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := bp.CallGetRestEndPoint(ctx, server.URL, "/some/path", &testStruct{})
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	go func() {
+		_ = bp.CallGetRestEndPoint(context.Background(), server.URL, "/some/path", &testStruct{})
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&numRequests))
+}
+
+//------- hedging
+
+func TestBaseProcessor_ApplyConfigInvalidHedgingShouldErr(t *testing.T) {
+	t.Parallel()
+
+	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{}, metrics.NewRegistry())
+	err := bp.ApplyConfig(&config.Config{
+		Hedging: config.HedgingConfig{
+			Enabled:           true,
+			LatencyPercentile: 100,
+		},
+		Observers: []*data.Observer{{Address: "address"}},
+	})
+
+	assert.Equal(t, process.ErrInvalidHedgingConfig, err)
+}
+
+func TestBaseProcessor_GetHedgingDelayShouldUseTheShardReadLatencies(t *testing.T) {
+	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{}, metrics.NewRegistry())
+	_ = bp.ApplyConfig(&config.Config{
+		Hedging: config.HedgingConfig{
+			Enabled:           true,
+			LatencyPercentile: 90,
+			MinDelayInMs:      10,
+		},
+		Observers: []*data.Observer{{Address: "address0", ShardId: 0}, {Address: "address1", ShardId: 1}},
+	})
+	assert.Equal(t, time.Duration(0), bp.GetHedgingDelay(0))
+
+	for i := 0; i < 20; i++ {
+		bp.RecordReadLatency(0, time.Second)
+		bp.RecordReadLatency(1, time.Millisecond)
+	}
+
+	assert.Equal(t, time.Second, bp.GetHedgingDelay(0))
+	assert.Equal(t, 10*time.Millisecond, bp.GetHedgingDelay(1))
+}
+
+func TestBaseProcessor_CallGetRestEndPointShouldNotRecordReadLatencies(t *testing.T) {
+	responseBuff, _ := json.Marshal(&testStruct{})
+	server := createTestHttpServer("/some/path", responseBuff)
+	defer server.Close()
+
+	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{}, metrics.NewRegistry())
+	_ = bp.ApplyConfig(&config.Config{
+		Hedging: config.HedgingConfig{
+			Enabled:           true,
+			LatencyPercentile: 90,
+			MinDelayInMs:      1000,
+		},
+		Observers: []*data.Observer{{Address: server.URL}},
+	})
+
+	// the probes call the observers directly, only the client reads are measured
+	for i := 0; i < 20; i++ {
+		_ = bp.CallGetRestEndPoint(context.Background(), server.URL, "/some/path", &testStruct{})
+	}
+
+	assert.Equal(t, time.Duration(0), bp.GetHedgingDelay(0))
+}
+
+//------- metrics
+
+func TestBaseProcessor_CallsShouldBeRecordedInMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/some/path" {
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = rw.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	registry := metrics.NewRegistry()
+	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{}, registry)
+	_ = bp.ApplyConfig(&config.Config{
+		Observers: []*data.Observer{
+			{Address: server.URL, ShardId: 1},
+			{Address: "unhealthy address", ShardId: 1},
+		},
+	})
+	bp.SetObserverHealth("unhealthy address", false)
+
+	_ = bp.CallGetRestEndPoint(context.Background(), server.URL, "/some/path", &testStruct{})
+	_ = bp.CallGetRestEndPoint(context.Background(), server.URL, "/missing/path", &testStruct{})
+	bp.CountFailover(1)
+
+	buff := &bytes.Buffer{}
+	err := registry.Write(buff)
+	assert.Nil(t, err)
+
+	output := buff.String()
+	expectedLines := []string{
+		fmt.Sprintf(`numbat_proxy_observer_requests_total{address="%s",shard="1",result="success"} 1`, server.URL),
+		fmt.Sprintf(`numbat_proxy_observer_requests_total{address="%s",shard="1",result="observer_error"} 1`, server.URL),
+		fmt.Sprintf(`numbat_proxy_observer_request_duration_seconds_count{address="%s",shard="1"} 2`, server.URL),
+		`numbat_proxy_observer_failovers_total{shard="1"} 1`,
+		`numbat_proxy_observers{shard="1"} 2`,
+		`numbat_proxy_healthy_observers{shard="1"} 1`,
+	}
+	for _, line := range expectedLines {
+		assert.Contains(t, output, line+"\n")
+	}
+}
+
+func TestBaseProcessor_MetricsShouldRedactObserverCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write([]byte("{}"))
+	}))
+	defer server.Close()
+	address := strings.Replace(server.URL, "http://", "http://user:secret@", 1)
+
+	registry := metrics.NewRegistry()
+	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{}, registry)
+	_ = bp.ApplyConfig(&config.Config{
+		Observers: []*data.Observer{{Address: address, ShardId: 1}},
+	})
+
+	err := bp.CallGetRestEndPoint(context.Background(), address, "/some/path", &testStruct{})
+	assert.Nil(t, err)
+
+	buff := &bytes.Buffer{}
+	_ = registry.Write(buff)
+
+	output := buff.String()
+	redactedAddress := strings.Replace(address, "secret", "redacted", 1)
+	assert.Contains(t, output,
+		fmt.Sprintf(`numbat_proxy_observer_requests_total{address="%s",shard="1",result="success"} 1`, redactedAddress)+"\n")
+	assert.NotContains(t, output, "secret")
+}
+
+func TestBaseProcessor_CanceledCallsShouldNotBeTimed(t *testing.T) {
+	chanRelease := make(chan struct{})
+	server := createHangingHttpServer(chanRelease)
+	defer server.Close()
+	defer close(chanRelease)
+
+	registry := metrics.NewRegistry()
+	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{}, registry)
+	_ = bp.ApplyConfig(&config.Config{
+		Observers: []*data.Observer{{Address: server.URL, ShardId: 1}},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+	_ = bp.CallPostRestEndPoint(ctx, server.URL, "/some/path", &testStruct{}, &testStruct{})
+
+	buff := &bytes.Buffer{}
+	err := registry.Write(buff)
+	assert.Nil(t, err)
+
+	output := buff.String()
+	assert.Contains(t, output,
+		fmt.Sprintf(`numbat_proxy_observer_requests_total{address="%s",shard="1",result="canceled"} 1`, server.URL)+"\n")
+	assert.NotContains(t, output, `result="error"`)
+	assert.NotContains(t, output, fmt.Sprintf(`numbat_proxy_observer_request_duration_seconds_count{address="%s"`, server.URL))
+}
+
+//------- request trace
+
+func TestBaseProcessor_CallsShouldForwardRequestIdAndRecordObservers(t *testing.T) {
+	t.Parallel()
+
+	receivedIds := make(chan string, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		receivedIds <- req.Header.Get(process.RequestIdHeader)
+		_, _ = rw.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{}, metrics.NewRegistry())
+	ctx := process.NewRequestContext(context.Background(), "request-id")
+
+	err := bp.CallGetRestEndPoint(ctx, server.URL, "/some/path", &testStruct{})
+	assert.Nil(t, err)
+	assert.Equal(t, "request-id", <-receivedIds)
+
+	err = bp.CallPostRestEndPoint(ctx, server.URL, "/some/path", &testStruct{}, &testStruct{})
+	assert.Nil(t, err)
+	assert.Equal(t, "request-id", <-receivedIds)
+
+	assert.Equal(t, []string{server.URL, server.URL}, process.GetContactedObservers(ctx))
+	assert.Nil(t, process.GetContactedObservers(context.Background()))
 }
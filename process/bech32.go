@@ -0,0 +1,148 @@
+package process
+
+import (
+	"errors"
+	"strings"
+)
+
+// bech32Charset maps the 5 bit groups to the characters of a bech32 string, as defined by BIP-0173
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+const bech32Separator = '1'
+const bech32ChecksumLength = 6
+
+var bech32Generator = [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+var errBech32MixedCase = errors.New("bech32 string has mixed case characters")
+var errBech32MissingSeparator = errors.New("bech32 string has no separator or a too short checksum")
+var errBech32InvalidPrefix = errors.New("bech32 string has an invalid prefix character")
+var errBech32InvalidCharacter = errors.New("bech32 string has an invalid data character")
+var errBech32InvalidPadding = errors.New("bech32 string has an invalid padding")
+
+// encodeBech32 encodes the bytes as a bech32 string with the provided, lowercase, prefix
+func encodeBech32(prefix string, buff []byte) string {
+	values, _ := convertBits(buff, 8, 5, true)
+	values = append(values, bech32Checksum(prefix, values)...)
+
+	result := strings.Builder{}
+	result.Grow(len(prefix) + 1 + len(values))
+	result.WriteString(prefix)
+	result.WriteByte(bech32Separator)
+	for _, value := range values {
+		result.WriteByte(bech32Charset[value])
+	}
+
+	return result.String()
+}
+
+// decodeBech32 returns the prefix and the bytes encoded in a bech32 string. ErrInvalidAddressChecksum is
+// returned when the string is well formed but its checksum does not match
+func decodeBech32(bech string) (string, []byte, error) {
+	lower := strings.ToLower(bech)
+	if lower != bech && strings.ToUpper(bech) != bech {
+		return "", nil, errBech32MixedCase
+	}
+
+	separatorIndex := strings.LastIndexByte(lower, bech32Separator)
+	if separatorIndex < 1 || separatorIndex+bech32ChecksumLength+1 > len(lower) {
+		return "", nil, errBech32MissingSeparator
+	}
+
+	prefix := lower[:separatorIndex]
+	for i := 0; i < len(prefix); i++ {
+		if prefix[i] < 33 || prefix[i] > 126 {
+			return "", nil, errBech32InvalidPrefix
+		}
+	}
+
+	values := make([]byte, 0, len(lower)-separatorIndex-1)
+	for i := separatorIndex + 1; i < len(lower); i++ {
+		value := strings.IndexByte(bech32Charset, lower[i])
+		if value < 0 {
+			return "", nil, errBech32InvalidCharacter
+		}
+		values = append(values, byte(value))
+	}
+
+	if bech32Polymod(append(bech32PrefixValues(prefix), values...)) != 1 {
+		return "", nil, ErrInvalidAddressChecksum
+	}
+
+	buff, err := convertBits(values[:len(values)-bech32ChecksumLength], 5, 8, false)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return prefix, buff, nil
+}
+
+func bech32Polymod(values []byte) uint32 {
+	checksum := uint32(1)
+	for _, value := range values {
+		top := checksum >> 25
+		checksum = (checksum&0x1ffffff)<<5 ^ uint32(value)
+		for i, generator := range bech32Generator {
+			if (top>>uint(i))&1 == 1 {
+				checksum ^= generator
+			}
+		}
+	}
+
+	return checksum
+}
+
+// bech32PrefixValues expands the prefix so that it is covered by the checksum
+func bech32PrefixValues(prefix string) []byte {
+	values := make([]byte, 0, len(prefix)*2+1)
+	for i := 0; i < len(prefix); i++ {
+		values = append(values, prefix[i]>>5)
+	}
+	values = append(values, 0)
+	for i := 0; i < len(prefix); i++ {
+		values = append(values, prefix[i]&31)
+	}
+
+	return values
+}
+
+func bech32Checksum(prefix string, values []byte) []byte {
+	checksumValues := append(bech32PrefixValues(prefix), values...)
+	checksumValues = append(checksumValues, make([]byte, bech32ChecksumLength)...)
+	polymod := bech32Polymod(checksumValues) ^ 1
+
+	checksum := make([]byte, bech32ChecksumLength)
+	for i := range checksum {
+		checksum[i] = byte((polymod >> uint(5*(5-i))) & 31)
+	}
+
+	return checksum
+}
+
+// convertBits regroups the bits of the values from fromBits to toBits wide groups. Without padding, the
+// left over bits must be fewer than fromBits and all zero
+func convertBits(values []byte, fromBits uint, toBits uint, pad bool) ([]byte, error) {
+	accumulator := uint32(0)
+	numBits := uint(0)
+	maxValue := uint32(1)<<toBits - 1
+	result := make([]byte, 0, len(values)*int(fromBits)/int(toBits)+1)
+	for _, value := range values {
+		accumulator = accumulator<<fromBits | uint32(value)
+		numBits += fromBits
+		for numBits >= toBits {
+			numBits -= toBits
+			result = append(result, byte((accumulator>>numBits)&maxValue))
+		}
+	}
+
+	if pad {
+		if numBits > 0 {
+			result = append(result, byte((accumulator<<(toBits-numBits))&maxValue))
+		}
+		return result, nil
+	}
+	if numBits >= fromBits || (accumulator<<(toBits-numBits))&maxValue != 0 {
+		return nil, errBech32InvalidPadding
+	}
+
+	return result, nil
+}
@@ -0,0 +1,203 @@
+package process
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/numbatx/numbat-proxy/config"
+)
+
+// ConfigLoader defines the function used to load a config from a file
+type ConfigLoader func(filePath string) (*config.Config, error)
+
+// ConfigAppliers applies a config on all its components, in order. The config is applied only if all the
+// components accept it, so a reload is all-or-nothing
+type ConfigAppliers struct {
+	mutApply      sync.Mutex
+	appliers      []ConfigApplier
+	appliedConfig *config.Config
+}
+
+// NewConfigAppliers creates a new instance of ConfigAppliers. The provided config is the one the components
+// currently run with, restored on them if a later config can not be applied on all of them
+func NewConfigAppliers(appliedConfig *config.Config, appliers ...ConfigApplier) (*ConfigAppliers, error) {
+	if appliedConfig == nil {
+		return nil, ErrNilConfig
+	}
+
+	return &ConfigAppliers{
+		appliers:      appliers,
+		appliedConfig: appliedConfig,
+	}, nil
+}
+
+// ValidateConfig checks the config against all the components and returns the first error
+func (ca *ConfigAppliers) ValidateConfig(cfg *config.Config) error {
+	for _, applier := range ca.appliers {
+		err := applier.ValidateConfig(cfg)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ApplyConfig validates the config against all the components, then applies it on all of them. If a
+// component still fails to apply it, for example because a file it reads changed since the validation, the
+// components that already applied it are given back the previously applied config
+func (ca *ConfigAppliers) ApplyConfig(cfg *config.Config) error {
+	ca.mutApply.Lock()
+	defer ca.mutApply.Unlock()
+
+	err := ca.ValidateConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	for idx, applier := range ca.appliers {
+		err = applier.ApplyConfig(cfg)
+		if err != nil {
+			ca.rollBack(ca.appliers[:idx])
+			return err
+		}
+	}
+	ca.appliedConfig = cfg
+
+	return nil
+}
+
+func (ca *ConfigAppliers) rollBack(appliers []ConfigApplier) {
+	for _, applier := range appliers {
+		err := applier.ApplyConfig(ca.appliedConfig)
+		if err != nil {
+			log.Error(fmt.Sprintf("could not restore the previous config: %s", err.Error()))
+		}
+	}
+}
+
+// ConfigWatcher periodically checks the configuration file and, if its contents changed,
+// applies the newly loaded config on the processor without restarting the proxy
+type ConfigWatcher struct {
+	proc         ConfigApplier
+	filePath     string
+	loadConfig   ConfigLoader
+	readInterval time.Duration
+
+	mutWatcher   sync.Mutex
+	lastFileHash []byte
+	chanClose    chan struct{}
+	closeOnce    sync.Once
+}
+
+// NewConfigWatcher creates a new instance of ConfigWatcher. The current contents of the file are
+// considered already applied so only subsequent changes will trigger a reload
+func NewConfigWatcher(
+	proc ConfigApplier,
+	filePath string,
+	loadConfig ConfigLoader,
+	readInterval time.Duration,
+) (*ConfigWatcher, error) {
+
+	if proc == nil {
+		return nil, ErrNilCoreProcessor
+	}
+	if loadConfig == nil {
+		return nil, ErrNilConfigLoader
+	}
+	if readInterval <= 0 {
+		return nil, ErrInvalidConfigReadInterval
+	}
+
+	fileHash, err := computeFileHash(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConfigWatcher{
+		proc:         proc,
+		filePath:     filePath,
+		loadConfig:   loadConfig,
+		readInterval: readInterval,
+		lastFileHash: fileHash,
+		chanClose:    make(chan struct{}),
+	}, nil
+}
+
+// Start launches the go routine that will periodically check the config file
+func (cw *ConfigWatcher) Start() {
+	go cw.watch()
+}
+
+func (cw *ConfigWatcher) watch() {
+	ticker := time.NewTicker(cw.readInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-cw.chanClose:
+			return
+		case <-ticker.C:
+			err := cw.RefreshConfig()
+			if err != nil {
+				log.Error(fmt.Sprintf("could not apply config from %s, keeping the previous one: %s",
+					cw.filePath,
+					err.Error(),
+				))
+			}
+		}
+	}
+}
+
+// RefreshConfig reloads and applies the config if the file contents changed since the last check.
+// If the new config can not be loaded or applied, the previously applied config remains active
+func (cw *ConfigWatcher) RefreshConfig() error {
+	cw.mutWatcher.Lock()
+	defer cw.mutWatcher.Unlock()
+
+	fileHash, err := computeFileHash(cw.filePath)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(fileHash, cw.lastFileHash) {
+		return nil
+	}
+	// the hash is stored regardless of the outcome so an invalid file is reported only once
+	cw.lastFileHash = fileHash
+
+	cfg, err := cw.loadConfig(cw.filePath)
+	if err != nil {
+		return err
+	}
+
+	err = cw.proc.ApplyConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	log.Info(fmt.Sprintf("Applied new config from %s", cw.filePath))
+
+	return nil
+}
+
+// Close stops the watching go routine
+func (cw *ConfigWatcher) Close() {
+	cw.closeOnce.Do(func() {
+		close(cw.chanClose)
+	})
+}
+
+func computeFileHash(filePath string) ([]byte, error) {
+	buff, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.Sum256(buff)
+
+	return hash[:], nil
+}
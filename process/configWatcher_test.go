@@ -0,0 +1,254 @@
+package process_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/numbatx/numbat-proxy/config"
+	"github.com/numbatx/numbat-proxy/process"
+	"github.com/numbatx/numbat-proxy/process/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestConfigFile(t *testing.T, contents string) string {
+	filePath := filepath.Join(t.TempDir(), "config.toml")
+	err := os.WriteFile(filePath, []byte(contents), 0644)
+	assert.Nil(t, err)
+
+	return filePath
+}
+
+func loadConfigStub(_ string) (*config.Config, error) {
+	return &config.Config{}, nil
+}
+
+func TestNewConfigWatcher_NilProcessorShouldErr(t *testing.T) {
+	t.Parallel()
+
+	filePath := createTestConfigFile(t, "contents")
+	cw, err := process.NewConfigWatcher(nil, filePath, loadConfigStub, time.Second)
+
+	assert.Nil(t, cw)
+	assert.Equal(t, process.ErrNilCoreProcessor, err)
+}
+
+func TestNewConfigWatcher_NilLoaderShouldErr(t *testing.T) {
+	t.Parallel()
+
+	filePath := createTestConfigFile(t, "contents")
+	cw, err := process.NewConfigWatcher(&mock.ProcessorStub{}, filePath, nil, time.Second)
+
+	assert.Nil(t, cw)
+	assert.Equal(t, process.ErrNilConfigLoader, err)
+}
+
+func TestNewConfigWatcher_InvalidIntervalShouldErr(t *testing.T) {
+	t.Parallel()
+
+	filePath := createTestConfigFile(t, "contents")
+	cw, err := process.NewConfigWatcher(&mock.ProcessorStub{}, filePath, loadConfigStub, 0)
+
+	assert.Nil(t, cw)
+	assert.Equal(t, process.ErrInvalidConfigReadInterval, err)
+}
+
+func TestNewConfigWatcher_MissingFileShouldErr(t *testing.T) {
+	t.Parallel()
+
+	filePath := filepath.Join(t.TempDir(), "missing.toml")
+	cw, err := process.NewConfigWatcher(&mock.ProcessorStub{}, filePath, loadConfigStub, time.Second)
+
+	assert.Nil(t, cw)
+	assert.NotNil(t, err)
+}
+
+//------- RefreshConfig
+
+func TestConfigWatcher_RefreshConfigUnchangedFileShouldNotApply(t *testing.T) {
+	t.Parallel()
+
+	filePath := createTestConfigFile(t, "contents")
+	numApplied := 0
+	cw, _ := process.NewConfigWatcher(
+		&mock.ProcessorStub{
+			ApplyConfigCalled: func(cfg *config.Config) error {
+				numApplied++
+				return nil
+			},
+		},
+		filePath,
+		loadConfigStub,
+		time.Second,
+	)
+
+	err := cw.RefreshConfig()
+
+	assert.Nil(t, err)
+	assert.Equal(t, 0, numApplied)
+}
+
+func TestConfigWatcher_RefreshConfigChangedFileShouldApplyOnce(t *testing.T) {
+	t.Parallel()
+
+	filePath := createTestConfigFile(t, "contents")
+	numApplied := 0
+	cw, _ := process.NewConfigWatcher(
+		&mock.ProcessorStub{
+			ApplyConfigCalled: func(cfg *config.Config) error {
+				numApplied++
+				return nil
+			},
+		},
+		filePath,
+		loadConfigStub,
+		time.Second,
+	)
+
+	_ = os.WriteFile(filePath, []byte("new contents"), 0644)
+	err := cw.RefreshConfig()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, numApplied)
+
+	err = cw.RefreshConfig()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, numApplied)
+}
+
+func TestConfigWatcher_RefreshConfigApplyFailsShouldErrAndNotRetryUntilChanged(t *testing.T) {
+	t.Parallel()
+
+	filePath := createTestConfigFile(t, "contents")
+	numApplied := 0
+	cw, _ := process.NewConfigWatcher(
+		&mock.ProcessorStub{
+			ApplyConfigCalled: func(cfg *config.Config) error {
+				numApplied++
+				return process.ErrEmptyObserversList
+			},
+		},
+		filePath,
+		loadConfigStub,
+		time.Second,
+	)
+
+	_ = os.WriteFile(filePath, []byte("invalid contents"), 0644)
+	err := cw.RefreshConfig()
+	assert.Equal(t, process.ErrEmptyObserversList, err)
+
+	err = cw.RefreshConfig()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, numApplied)
+}
+
+func TestConfigWatcher_RefreshConfigLoadFailsShouldNotApply(t *testing.T) {
+	t.Parallel()
+
+	filePath := createTestConfigFile(t, "contents")
+	errExpected := errors.New("expected error")
+	numApplied := 0
+	cw, _ := process.NewConfigWatcher(
+		&mock.ProcessorStub{
+			ApplyConfigCalled: func(cfg *config.Config) error {
+				numApplied++
+				return nil
+			},
+		},
+		filePath,
+		func(_ string) (*config.Config, error) {
+			return nil, errExpected
+		},
+		time.Second,
+	)
+
+	_ = os.WriteFile(filePath, []byte("new contents"), 0644)
+	err := cw.RefreshConfig()
+
+	assert.Equal(t, errExpected, err)
+	assert.Equal(t, 0, numApplied)
+}
+
+func TestConfigAppliers_ApplyConfigInvalidForOneShouldNotApplyAny(t *testing.T) {
+	t.Parallel()
+
+	expectedErr := errors.New("expected error")
+	numApplied := 0
+	appliers, _ := process.NewConfigAppliers(&config.Config{},
+		&mock.ProcessorStub{
+			ApplyConfigCalled: func(cfg *config.Config) error {
+				numApplied++
+				return nil
+			},
+		},
+		&mock.ProcessorStub{
+			ValidateConfigCalled: func(cfg *config.Config) error {
+				return expectedErr
+			},
+			ApplyConfigCalled: func(cfg *config.Config) error {
+				numApplied++
+				return nil
+			},
+		},
+	)
+
+	err := appliers.ApplyConfig(&config.Config{})
+
+	assert.Equal(t, expectedErr, err)
+	assert.Equal(t, 0, numApplied)
+}
+
+func TestConfigAppliers_ApplyConfigValidForAllShouldApplyAll(t *testing.T) {
+	t.Parallel()
+
+	numApplied := 0
+	applier := &mock.ProcessorStub{
+		ApplyConfigCalled: func(cfg *config.Config) error {
+			numApplied++
+			return nil
+		},
+	}
+	appliers, _ := process.NewConfigAppliers(&config.Config{}, applier, applier)
+
+	err := appliers.ApplyConfig(&config.Config{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, numApplied)
+}
+
+func TestNewConfigAppliers_NilConfigShouldErr(t *testing.T) {
+	t.Parallel()
+
+	appliers, err := process.NewConfigAppliers(nil, &mock.ProcessorStub{})
+
+	assert.Nil(t, appliers)
+	assert.Equal(t, process.ErrNilConfig, err)
+}
+
+func TestConfigAppliers_ApplyConfigFailingOnOneShouldRestoreThePreviousConfig(t *testing.T) {
+	t.Parallel()
+
+	previousCfg := &config.Config{GeneralSettings: config.GeneralSettingsConfig{ServerPort: 1}}
+	newCfg := &config.Config{GeneralSettings: config.GeneralSettingsConfig{ServerPort: 2}}
+	expectedErr := errors.New("expected error")
+	var firstApplied *config.Config
+	appliers, _ := process.NewConfigAppliers(previousCfg,
+		&mock.ProcessorStub{
+			ApplyConfigCalled: func(cfg *config.Config) error {
+				firstApplied = cfg
+				return nil
+			},
+		},
+		&mock.ProcessorStub{
+			ApplyConfigCalled: func(cfg *config.Config) error {
+				return expectedErr
+			},
+		},
+	)
+
+	err := appliers.ApplyConfig(newCfg)
+
+	assert.Equal(t, expectedErr, err)
+	assert.True(t, firstApplied == previousCfg)
+}
@@ -11,7 +11,8 @@ var ErrEmptyObserversList = errors.New("empty observers list provided")
 // ErrMissingObserver signals that no observers have been provided for provided shard ID
 var ErrMissingObserver = errors.New("missing observer")
 
-// ErrSendingRequest signals that sending the request failed on all observers
+// ErrSendingRequest signals that sending the request failed on all observers. The error of the last
+// tried observer is wrapped along with it
 var ErrSendingRequest = errors.New("sending request error")
 
 // ErrNilAddressConverter signals that a nil address converter has been provided
@@ -19,3 +20,123 @@ var ErrNilAddressConverter = errors.New("nil address converter")
 
 // ErrNilCoreProcessor signals that a nil core processor has been provided
 var ErrNilCoreProcessor = errors.New("nil core processor")
+
+// ErrNilConfigLoader signals that a nil config loader has been provided
+var ErrNilConfigLoader = errors.New("nil config loader")
+
+// ErrInvalidConfigReadInterval signals that an invalid config file read interval has been provided
+var ErrInvalidConfigReadInterval = errors.New("invalid config file read interval")
+
+// ErrNilObserversHealthHandler signals that a nil observers health handler has been provided
+var ErrNilObserversHealthHandler = errors.New("nil observers health handler")
+
+// ErrInvalidHealthCheckConfig signals that an invalid health check configuration has been provided
+var ErrInvalidHealthCheckConfig = errors.New("invalid health check configuration")
+
+// ErrUnknownObserverSelection signals that an unknown observers selection strategy has been provided
+var ErrUnknownObserverSelection = errors.New("unknown observers selection strategy")
+
+// ErrInvalidAddress signals that an address could not be decoded
+var ErrInvalidAddress = errors.New("invalid address")
+
+// ErrInvalidAddressChecksum signals that the checksum of a bech32 address does not match its content
+var ErrInvalidAddressChecksum = errors.New("invalid address checksum")
+
+// ErrNilAddressCodec signals that a nil address codec has been provided
+var ErrNilAddressCodec = errors.New("nil address codec")
+
+// ErrInvalidAddressesConfig signals that an invalid addresses configuration has been provided
+var ErrInvalidAddressesConfig = errors.New("invalid addresses configuration")
+
+// ErrInvalidTransaction signals that a transaction did not pass the validation made before relaying it
+var ErrInvalidTransaction = errors.New("invalid transaction")
+
+// ErrSignatureMismatch signals that the signature of a transaction was not made by its sender
+var ErrSignatureMismatch = errors.New("signature does not match the transaction and its sender")
+
+// ErrInvalidTransactionValidationConfig signals that an invalid transaction validation configuration has been provided
+var ErrInvalidTransactionValidationConfig = errors.New("invalid transaction validation configuration")
+
+// ErrNilTransactionValidator signals that a nil transaction validator has been provided
+var ErrNilTransactionValidator = errors.New("nil transaction validator")
+
+// ErrInvalidNonce signals that a transaction was not sent as its nonce would prevent it from being executed
+var ErrInvalidNonce = errors.New("invalid nonce")
+
+// ErrNilNonceChecker signals that a nil nonce checker has been provided
+var ErrNilNonceChecker = errors.New("nil nonce checker")
+
+// ErrNonceAllocationDisabled signals that the proxy does not hand out nonces
+var ErrNonceAllocationDisabled = errors.New("nonce allocation is disabled")
+
+// ErrTooManyReservedNonces signals that the sender already has the maximum number of tracked nonces
+var ErrTooManyReservedNonces = errors.New("too many reserved nonces")
+
+// ErrTooManyTrackedSenders signals that the proxy already tracks the nonces of the maximum number of senders
+var ErrTooManyTrackedSenders = errors.New("too many senders with tracked nonces")
+
+// ErrInvalidNonceCheckConfig signals that an invalid nonce check configuration has been provided
+var ErrInvalidNonceCheckConfig = errors.New("invalid nonce check configuration")
+
+// ErrInvalidNonceAllocationConfig signals that an invalid nonce allocation configuration has been provided
+var ErrInvalidNonceAllocationConfig = errors.New("invalid nonce allocation configuration")
+
+// ErrEmptyTransactionsList signals that an empty list of transactions has been provided
+var ErrEmptyTransactionsList = errors.New("empty transactions list provided")
+
+// ErrInvalidTransactionHash signals that an invalid transaction hash has been provided
+var ErrInvalidTransactionHash = errors.New("invalid transaction hash")
+
+// ErrTransactionNotFound signals that no observer knows the requested transaction
+var ErrTransactionNotFound = errors.New("transaction not found")
+
+// ErrNotSupportedByObservers signals that the observers do not serve the path the request needs
+var ErrNotSupportedByObservers = errors.New("not supported by the observers")
+
+// ErrSimulationNotSupported signals that the observers do not serve the transaction simulation
+var ErrSimulationNotSupported = errors.New("transaction simulation is not supported by the observers")
+
+// ErrMissingSenderOrReceiver signals that the sender or the receiver of a transaction has not been provided
+var ErrMissingSenderOrReceiver = errors.New("missing sender or receiver")
+
+// ErrInvalidHedgingConfig signals that an invalid hedging configuration has been provided
+var ErrInvalidHedgingConfig = errors.New("invalid hedging configuration")
+
+// ErrNilAccountGetter signals that a nil account getter has been provided
+var ErrNilAccountGetter = errors.New("nil account getter")
+
+// ErrEmptyAddressesList signals that an empty list of addresses has been provided
+var ErrEmptyAddressesList = errors.New("empty addresses list provided")
+
+// ErrTooManyAddresses signals that more addresses than allowed have been provided
+var ErrTooManyAddresses = errors.New("too many addresses provided")
+
+// ErrTooManySubscriptions signals that the accounts notifier already holds the maximum number of subscriptions
+var ErrTooManySubscriptions = errors.New("too many account subscriptions")
+
+// ErrTooManyPolledAddresses signals that the accounts notifier already polls the maximum number of addresses
+var ErrTooManyPolledAddresses = errors.New("too many polled addresses")
+
+// ErrAccountsNotifierClosed signals that the accounts notifier no longer accepts subscriptions
+var ErrAccountsNotifierClosed = errors.New("accounts notifier is closed")
+
+// ErrInvalidAccountsCacheConfig signals that an invalid accounts cache configuration has been provided
+var ErrInvalidAccountsCacheConfig = errors.New("invalid accounts cache configuration")
+
+// ErrNilMetricsRegistry signals that a nil metrics registry has been provided
+var ErrNilMetricsRegistry = errors.New("nil metrics registry")
+
+// ErrNilProxyStatusHandler signals that a nil proxy status handler has been provided
+var ErrNilProxyStatusHandler = errors.New("nil proxy status handler")
+
+// ErrConfigNotApplied signals that no config has been successfully applied yet
+var ErrConfigNotApplied = errors.New("config not applied")
+
+// ErrShardNotReachable signals that none of the observers of a shard could be reached
+var ErrShardNotReachable = errors.New("no reachable observer on shard")
+
+// ErrShuttingDown signals that the proxy is shutting down
+var ErrShuttingDown = errors.New("proxy is shutting down")
+
+// ErrInvalidTlsConfig signals that the TLS settings are incomplete or their files can not be loaded
+var ErrInvalidTlsConfig = errors.New("invalid TLS configuration")
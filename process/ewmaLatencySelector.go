@@ -0,0 +1,72 @@
+package process
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/numbatx/numbat-proxy/data"
+)
+
+// ewmaDecay is the weight given to the newest latency sample
+const ewmaDecay = 0.3
+
+// ewmaFailurePenalty is the latency sample recorded when a call fails
+const ewmaFailurePenalty = 10 * time.Second
+
+// EwmaLatencySelector returns first the observers with the lowest exponentially weighted moving
+// average latency. Observers without any recorded call are tried first so they get measured
+type EwmaLatencySelector struct {
+	mutLatencies sync.RWMutex
+	latencies    map[string]float64
+}
+
+// NewEwmaLatencySelector creates a new instance of EwmaLatencySelector
+func NewEwmaLatencySelector() *EwmaLatencySelector {
+	return &EwmaLatencySelector{
+		latencies: make(map[string]float64),
+	}
+}
+
+// Select returns a copy of the observers sorted ascending by their average latency
+func (els *EwmaLatencySelector) Select(_ uint32, observers []*data.Observer) []*data.Observer {
+	selected := make([]*data.Observer, len(observers))
+	copy(selected, observers)
+
+	els.mutLatencies.RLock()
+	defer els.mutLatencies.RUnlock()
+
+	sort.SliceStable(selected, func(i, j int) bool {
+		return els.latencies[selected[i].Address] < els.latencies[selected[j].Address]
+	})
+
+	return selected
+}
+
+// CallStarted does nothing
+func (els *EwmaLatencySelector) CallStarted(_ string) {
+}
+
+// CallFinished records the call duration in the observer's average latency. Failed calls are
+// recorded with a penalty latency
+func (els *EwmaLatencySelector) CallFinished(address string, duration time.Duration, err error) {
+	if err != nil && duration < ewmaFailurePenalty {
+		duration = ewmaFailurePenalty
+	}
+
+	els.mutLatencies.Lock()
+	defer els.mutLatencies.Unlock()
+
+	sample := float64(duration)
+	average, ok := els.latencies[address]
+	if !ok {
+		els.latencies[address] = sample
+		return
+	}
+
+	els.latencies[address] = ewmaDecay*sample + (1-ewmaDecay)*average
+}
+
+// CallCanceled does nothing
+func (els *EwmaLatencySelector) CallCanceled(_ string) {
+}
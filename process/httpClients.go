@@ -0,0 +1,201 @@
+package process
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/numbatx/numbat-proxy/config"
+)
+
+const defaultDialTimeout = 5 * time.Second
+const defaultRequestTimeout = 30 * time.Second
+const defaultMaxIdleConnsPerHost = 10
+const defaultIdleConnTimeout = 90 * time.Second
+const defaultKeepAlive = 30 * time.Second
+
+// httpClients holds the default http client and the clients built for observers with overridden settings
+type httpClients struct {
+	defaultClient   *http.Client
+	observerClients map[string]*http.Client
+}
+
+// newHttpClients builds the http clients. It errors if a certificate file can not be loaded
+func newHttpClients(cfg config.HttpClientConfig) (*httpClients, error) {
+	defaultSettings := &config.ObserverHttpClientConfig{
+		DialTimeoutInMs:      cfg.DialTimeoutInMs,
+		RequestTimeoutInMs:   cfg.RequestTimeoutInMs,
+		MaxIdleConnsPerHost:  cfg.MaxIdleConnsPerHost,
+		IdleConnTimeoutInSec: cfg.IdleConnTimeoutInSec,
+		KeepAliveInSec:       cfg.KeepAliveInSec,
+		TlsCaCertFile:        cfg.TlsCaCertFile,
+		TlsCertFile:          cfg.TlsCertFile,
+		TlsKeyFile:           cfg.TlsKeyFile,
+	}
+
+	defaultClient, err := newHttpClient(defaultSettings)
+	if err != nil {
+		return nil, err
+	}
+
+	clients := &httpClients{
+		defaultClient:   defaultClient,
+		observerClients: make(map[string]*http.Client),
+	}
+	for _, override := range cfg.ObserverOverrides {
+		client, errClient := newHttpClient(mergeHttpClientSettings(defaultSettings, override))
+		if errClient != nil {
+			return nil, fmt.Errorf("%w for observer %s", errClient, override.Address)
+		}
+
+		clients.observerClients[override.Address] = client
+	}
+
+	return clients, nil
+}
+
+// client returns the http client to be used when calling the observer with the provided address
+func (hc *httpClients) client(address string) *http.Client {
+	client, ok := hc.observerClients[address]
+	if ok {
+		return client
+	}
+
+	return hc.defaultClient
+}
+
+// closeIdleConnections closes the idle connections of all the clients. In-flight requests are not affected
+func (hc *httpClients) closeIdleConnections() {
+	hc.defaultClient.CloseIdleConnections()
+	for _, client := range hc.observerClients {
+		client.CloseIdleConnections()
+	}
+}
+
+func mergeHttpClientSettings(
+	defaults *config.ObserverHttpClientConfig,
+	override *config.ObserverHttpClientConfig,
+) *config.ObserverHttpClientConfig {
+
+	merged := *defaults
+	merged.Address = override.Address
+	if override.DialTimeoutInMs > 0 {
+		merged.DialTimeoutInMs = override.DialTimeoutInMs
+	}
+	if override.RequestTimeoutInMs > 0 {
+		merged.RequestTimeoutInMs = override.RequestTimeoutInMs
+	}
+	if override.MaxIdleConnsPerHost > 0 {
+		merged.MaxIdleConnsPerHost = override.MaxIdleConnsPerHost
+	}
+	if override.IdleConnTimeoutInSec > 0 {
+		merged.IdleConnTimeoutInSec = override.IdleConnTimeoutInSec
+	}
+	if override.KeepAliveInSec > 0 {
+		merged.KeepAliveInSec = override.KeepAliveInSec
+	}
+	if override.TlsCaCertFile != "" {
+		merged.TlsCaCertFile = override.TlsCaCertFile
+	}
+	// the client certificate and its key are only overridden together
+	if override.TlsCertFile != "" || override.TlsKeyFile != "" {
+		merged.TlsCertFile = override.TlsCertFile
+		merged.TlsKeyFile = override.TlsKeyFile
+	}
+
+	return &merged
+}
+
+func newHttpClient(settings *config.ObserverHttpClientConfig) (*http.Client, error) {
+	tlsConfig, err := newClientTlsConfig(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   durationOrDefault(settings.DialTimeoutInMs, time.Millisecond, defaultDialTimeout),
+		KeepAlive: durationOrDefault(settings.KeepAliveInSec, time.Second, defaultKeepAlive),
+	}
+
+	maxIdleConnsPerHost := settings.MaxIdleConnsPerHost
+	if maxIdleConnsPerHost <= 0 {
+		maxIdleConnsPerHost = defaultMaxIdleConnsPerHost
+	}
+
+	// Go's default transport also brings the proxy from the environment, HTTP/2 and the TLS handshake timeout
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	transport.IdleConnTimeout = durationOrDefault(settings.IdleConnTimeoutInSec, time.Second, defaultIdleConnTimeout)
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   durationOrDefault(settings.RequestTimeoutInMs, time.Millisecond, defaultRequestTimeout),
+	}, nil
+}
+
+// newClientTlsConfig returns the TLS settings used towards the observers or nil, meaning Go's defaults,
+// when no certificate file is configured. The files are only read here, so a certificate rotated in place is
+// only used once the clients are built again, on a config reload that changes the HttpClient section, or
+// on restart
+func newClientTlsConfig(settings *config.ObserverHttpClientConfig) (*tls.Config, error) {
+	hasClientCert := settings.TlsCertFile != "" || settings.TlsKeyFile != ""
+	if settings.TlsCaCertFile == "" && !hasClientCert {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if settings.TlsCaCertFile != "" {
+		caCertPool, err := loadCertPool(settings.TlsCaCertFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = caCertPool
+	}
+
+	if hasClientCert {
+		if settings.TlsCertFile == "" || settings.TlsKeyFile == "" {
+			return nil, ErrInvalidTlsConfig
+		}
+
+		cert, err := tls.LoadX509KeyPair(settings.TlsCertFile, settings.TlsKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidTlsConfig, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+func loadCertPool(caCertFile string) (*x509.CertPool, error) {
+	caCerts, err := os.ReadFile(caCertFile)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTlsConfig, err)
+	}
+
+	caCertPool := x509.NewCertPool()
+	if !caCertPool.AppendCertsFromPEM(caCerts) {
+		return nil, fmt.Errorf("%w: no certificate found in %s", ErrInvalidTlsConfig, caCertFile)
+	}
+
+	return caCertPool, nil
+}
+
+func durationOrDefault(value int, unit time.Duration, defaultValue time.Duration) time.Duration {
+	if value <= 0 {
+		return defaultValue
+	}
+
+	return time.Duration(value) * unit
+}
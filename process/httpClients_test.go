@@ -0,0 +1,161 @@
+package process_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/numbatx/numbat-proxy/config"
+	"github.com/numbatx/numbat-proxy/data"
+	"github.com/numbatx/numbat-proxy/metrics"
+	"github.com/numbatx/numbat-proxy/process"
+	"github.com/numbatx/numbat-proxy/process/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+type testCertificate struct {
+	cert     *x509.Certificate
+	key      *ecdsa.PrivateKey
+	certFile string
+	keyFile  string
+}
+
+// createTestCertificate creates a certificate signed by the parent, or a self-signed CA if parent is nil,
+// and writes it, together with its key, in the directory
+func createTestCertificate(t *testing.T, dir string, name string, parent *testCertificate) *testCertificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	signerCert, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	} else {
+		signerCert, signerKey = parent.cert, parent.key
+	}
+
+	certDer, err := x509.CreateCertificate(rand.Reader, template, signerCert, &key.PublicKey, signerKey)
+	assert.Nil(t, err)
+	cert, err := x509.ParseCertificate(certDer)
+	assert.Nil(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+
+	tc := &testCertificate{
+		cert:     cert,
+		key:      key,
+		certFile: filepath.Join(dir, name+".crt"),
+		keyFile:  filepath.Join(dir, name+".key"),
+	}
+	err = os.WriteFile(tc.certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDer}), 0600)
+	assert.Nil(t, err)
+	err = os.WriteFile(tc.keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+	assert.Nil(t, err)
+
+	return tc
+}
+
+func startMutualTlsServer(t *testing.T, ca *testCertificate, serverCert *testCertificate) *httptest.Server {
+	cert, err := tls.LoadX509KeyPair(serverCert.certFile, serverCert.keyFile)
+	assert.Nil(t, err)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write([]byte(`{"name": "mtls"}`))
+	}))
+	// the handshakes rejected on purpose are not logged
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}
+	server.StartTLS()
+
+	return server
+}
+
+func TestBaseProcessor_MutualTlsObserverShouldUseConfiguredCertificates(t *testing.T) {
+	dir := t.TempDir()
+	ca := createTestCertificate(t, dir, "ca", nil)
+	server := startMutualTlsServer(t, ca, createTestCertificate(t, dir, "observer", ca))
+	defer server.Close()
+	clientCert := createTestCertificate(t, dir, "proxy", ca)
+
+	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{}, metrics.NewRegistry())
+	err := bp.ApplyConfig(&config.Config{
+		HttpClient: config.HttpClientConfig{
+			TlsCaCertFile: ca.certFile,
+		},
+		Observers: []*data.Observer{{Address: server.URL}},
+	})
+	assert.Nil(t, err)
+
+	response := &testStruct{}
+	err = bp.CallGetRestEndPoint(context.Background(), server.URL, "/some/path", response)
+	assert.NotNil(t, err)
+
+	err = bp.ApplyConfig(&config.Config{
+		HttpClient: config.HttpClientConfig{
+			TlsCaCertFile: ca.certFile,
+			ObserverOverrides: []*config.ObserverHttpClientConfig{
+				{Address: server.URL, TlsCertFile: clientCert.certFile, TlsKeyFile: clientCert.keyFile},
+			},
+		},
+		Observers: []*data.Observer{{Address: server.URL}},
+	})
+	assert.Nil(t, err)
+
+	err = bp.CallGetRestEndPoint(context.Background(), server.URL, "/other/path", response)
+	assert.Nil(t, err)
+	assert.Equal(t, "mtls", response.Name)
+}
+
+func TestBaseProcessor_ApplyConfigInvalidTlsFilesShouldErr(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	ca := createTestCertificate(t, dir, "ca", nil)
+	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{}, metrics.NewRegistry())
+
+	err := bp.ApplyConfig(&config.Config{
+		HttpClient: config.HttpClientConfig{TlsCaCertFile: filepath.Join(dir, "missing.crt")},
+		Observers:  []*data.Observer{{Address: "address"}},
+	})
+	assert.True(t, errors.Is(err, process.ErrInvalidTlsConfig))
+
+	err = bp.ApplyConfig(&config.Config{
+		HttpClient: config.HttpClientConfig{TlsCertFile: ca.certFile},
+		Observers:  []*data.Observer{{Address: "address"}},
+	})
+	assert.True(t, errors.Is(err, process.ErrInvalidTlsConfig))
+	assert.Nil(t, bp.GetLoadedConfig())
+}
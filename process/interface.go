@@ -1,15 +1,76 @@
 package process
 
 import (
+	"context"
+	"time"
+
 	"github.com/numbatx/numbat-proxy/config"
 	"github.com/numbatx/numbat-proxy/data"
 )
 
+// ConfigApplier defines a component able to check and apply a newly loaded config
+type ConfigApplier interface {
+	ValidateConfig(cfg *config.Config) error
+	ApplyConfig(cfg *config.Config) error
+}
+
 // Processor defines what a processor should be able to do
 type Processor interface {
+	ValidateConfig(cfg *config.Config) error
 	ApplyConfig(cfg *config.Config) error
 	GetObservers(shardId uint32) ([]*data.Observer, error)
 	ComputeShardId(addressBuff []byte) (uint32, error)
-	CallGetRestEndPoint(address string, path string, value interface{}) error
-	CallPostRestEndPoint(address string, path string, data interface{}, response interface{}) error
+	GetShardIds() []uint32
+	GetHedgingDelay(shardId uint32) time.Duration
+	RecordReadLatency(shardId uint32, latency time.Duration)
+	CountFailover(shardId uint32)
+	CallGetRestEndPoint(ctx context.Context, address string, path string, value interface{}) error
+	CallPostRestEndPoint(ctx context.Context, address string, path string, data interface{}, response interface{}) error
+}
+
+// ObserversHealthHandler defines what the observers health checker needs in order to probe observers
+type ObserversHealthHandler interface {
+	GetAllObservers() []*data.Observer
+	SetObserverHealth(address string, isHealthy bool)
+	CallGetRestEndPoint(ctx context.Context, address string, path string, value interface{}) error
+}
+
+// ObserverSelector defines the order in which the observers of a shard are tried
+type ObserverSelector interface {
+	Select(shardId uint32, observers []*data.Observer) []*data.Observer
+	CallStarted(address string)
+	CallFinished(address string, duration time.Duration, err error)
+	CallCanceled(address string)
+}
+
+// AddressCodec defines what the processors need in order to accept and format the accounts' addresses
+type AddressCodec interface {
+	DecodeAddress(address string) ([]byte, error)
+	EncodeAddress(addressBuff []byte) string
+}
+
+// TransactionValidationHandler defines what the transaction processor needs in order to reject the invalid
+// transactions before relaying them
+type TransactionValidationHandler interface {
+	ValidateTransaction(tx *data.Transaction) error
+}
+
+// NonceCheckHandler defines what the transaction processor needs in order to compare the nonce of the sent
+// transactions with their sender's nonce
+type NonceCheckHandler interface {
+	CheckNonce(ctx context.Context, tx *data.Transaction) (*data.NonceCheck, error)
+}
+
+// AccountGetter defines what the accounts notifier needs in order to poll accounts
+type AccountGetter interface {
+	GetAccount(ctx context.Context, address string) (*data.Account, error)
+}
+
+// ProxyStatusHandler defines what the proxy status processor needs in order to report the proxy's state
+type ProxyStatusHandler interface {
+	GetLoadedConfig() *config.Config
+	GetObserversMap() map[uint32][]*data.Observer
+	GetNumShards() uint32
+	IsObserverReachable(address string) bool
+	CallGetRestEndPoint(ctx context.Context, address string, path string, value interface{}) error
 }
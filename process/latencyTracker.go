@@ -0,0 +1,67 @@
+package process
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const latencyWindowSize = 1000
+const minLatencySamples = 20
+const latencySamplesBetweenSorts = 50
+
+// latencyTracker keeps the most recent latencies in a ring buffer and computes their percentiles. The
+// sorted samples are refreshed only once in a while so computing a percentile stays cheap
+type latencyTracker struct {
+	mutLatencies        sync.Mutex
+	latencies           []time.Duration
+	nextIndex           int
+	sorted              []time.Duration
+	numAddedSinceSorted int
+}
+
+func newLatencyTracker() *latencyTracker {
+	return &latencyTracker{
+		latencies: make([]time.Duration, 0, latencyWindowSize),
+	}
+}
+
+func (lt *latencyTracker) add(latency time.Duration) {
+	lt.mutLatencies.Lock()
+	defer lt.mutLatencies.Unlock()
+
+	if len(lt.latencies) < latencyWindowSize {
+		lt.latencies = append(lt.latencies, latency)
+	} else {
+		lt.latencies[lt.nextIndex] = latency
+		lt.nextIndex = (lt.nextIndex + 1) % latencyWindowSize
+	}
+	lt.numAddedSinceSorted++
+}
+
+// percentile returns the latency under which the provided percentage of the samples are. The second
+// return value is false while there are not enough samples
+func (lt *latencyTracker) percentile(percentage int) (time.Duration, bool) {
+	lt.mutLatencies.Lock()
+	defer lt.mutLatencies.Unlock()
+
+	if len(lt.latencies) < minLatencySamples {
+		return 0, false
+	}
+
+	if lt.sorted == nil || lt.numAddedSinceSorted >= latencySamplesBetweenSorts {
+		lt.sorted = make([]time.Duration, len(lt.latencies))
+		copy(lt.sorted, lt.latencies)
+		sort.Slice(lt.sorted, func(i, j int) bool {
+			return lt.sorted[i] < lt.sorted[j]
+		})
+		lt.numAddedSinceSorted = 0
+	}
+
+	index := (len(lt.sorted)*percentage + 99) / 100
+	if index > 0 {
+		index--
+	}
+
+	return lt.sorted[index], true
+}
@@ -0,0 +1,65 @@
+package process
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/numbatx/numbat-proxy/data"
+)
+
+// LeastInFlightSelector returns first the observers that have the fewest requests in progress
+type LeastInFlightSelector struct {
+	mutInFlight sync.RWMutex
+	inFlight    map[string]int
+}
+
+// NewLeastInFlightSelector creates a new instance of LeastInFlightSelector
+func NewLeastInFlightSelector() *LeastInFlightSelector {
+	return &LeastInFlightSelector{
+		inFlight: make(map[string]int),
+	}
+}
+
+// Select returns a copy of the observers sorted ascending by the number of requests in progress.
+// Observers with the same number of requests keep their configured order
+func (lifs *LeastInFlightSelector) Select(_ uint32, observers []*data.Observer) []*data.Observer {
+	selected := make([]*data.Observer, len(observers))
+	copy(selected, observers)
+
+	lifs.mutInFlight.RLock()
+	defer lifs.mutInFlight.RUnlock()
+
+	sort.SliceStable(selected, func(i, j int) bool {
+		return lifs.inFlight[selected[i].Address] < lifs.inFlight[selected[j].Address]
+	})
+
+	return selected
+}
+
+// CallStarted increments the number of requests in progress for the observer
+func (lifs *LeastInFlightSelector) CallStarted(address string) {
+	lifs.mutInFlight.Lock()
+	lifs.inFlight[address]++
+	lifs.mutInFlight.Unlock()
+}
+
+// CallFinished decrements the number of requests in progress for the observer
+func (lifs *LeastInFlightSelector) CallFinished(address string, _ time.Duration, _ error) {
+	lifs.callEnded(address)
+}
+
+// CallCanceled decrements the number of requests in progress for the observer
+func (lifs *LeastInFlightSelector) CallCanceled(address string) {
+	lifs.callEnded(address)
+}
+
+func (lifs *LeastInFlightSelector) callEnded(address string) {
+	lifs.mutInFlight.Lock()
+	defer lifs.mutInFlight.Unlock()
+
+	lifs.inFlight[address]--
+	if lifs.inFlight[address] <= 0 {
+		delete(lifs.inFlight, address)
+	}
+}
@@ -0,0 +1,19 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/numbatx/numbat-proxy/data"
+)
+
+type AccountGetterStub struct {
+	GetAccountCalled func(ctx context.Context, address string) (*data.Account, error)
+}
+
+func (ags *AccountGetterStub) GetAccount(ctx context.Context, address string) (*data.Account, error) {
+	if ags.GetAccountCalled != nil {
+		return ags.GetAccountCalled(ctx, address)
+	}
+
+	return nil, errNotImplemented
+}
@@ -0,0 +1,19 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/numbatx/numbat-proxy/data"
+)
+
+type NonceCheckerStub struct {
+	CheckNonceCalled func(ctx context.Context, tx *data.Transaction) (*data.NonceCheck, error)
+}
+
+func (ncs *NonceCheckerStub) CheckNonce(ctx context.Context, tx *data.Transaction) (*data.NonceCheck, error) {
+	if ncs.CheckNonceCalled != nil {
+		return ncs.CheckNonceCalled(ctx, tx)
+	}
+
+	return nil, nil
+}
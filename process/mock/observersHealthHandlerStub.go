@@ -0,0 +1,35 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/numbatx/numbat-proxy/data"
+)
+
+type ObserversHealthHandlerStub struct {
+	GetAllObserversCalled     func() []*data.Observer
+	SetObserverHealthCalled   func(address string, isHealthy bool)
+	CallGetRestEndPointCalled func(ctx context.Context, address string, path string, value interface{}) error
+}
+
+func (ohhs *ObserversHealthHandlerStub) GetAllObservers() []*data.Observer {
+	if ohhs.GetAllObserversCalled != nil {
+		return ohhs.GetAllObserversCalled()
+	}
+
+	return nil
+}
+
+func (ohhs *ObserversHealthHandlerStub) SetObserverHealth(address string, isHealthy bool) {
+	if ohhs.SetObserverHealthCalled != nil {
+		ohhs.SetObserverHealthCalled(address, isHealthy)
+	}
+}
+
+func (ohhs *ObserversHealthHandlerStub) CallGetRestEndPoint(ctx context.Context, address string, path string, value interface{}) error {
+	if ohhs.CallGetRestEndPointCalled != nil {
+		return ohhs.CallGetRestEndPointCalled(ctx, address, path, value)
+	}
+
+	return errNotImplemented
+}
@@ -1,6 +1,9 @@
 package mock
 
 import (
+	"context"
+	"time"
+
 	"github.com/numbatx/numbat-proxy/config"
 	"github.com/numbatx/numbat-proxy/data"
 	"github.com/pkg/errors"
@@ -9,11 +12,24 @@ import (
 var errNotImplemented = errors.New("not implemented")
 
 type ProcessorStub struct {
+	ValidateConfigCalled       func(cfg *config.Config) error
 	ApplyConfigCalled          func(cfg *config.Config) error
 	GetObserversCalled         func(shardId uint32) ([]*data.Observer, error)
 	ComputeShardIdCalled       func(addressBuff []byte) (uint32, error)
-	CallGetRestEndPointCalled  func(address string, path string, value interface{}) error
-	CallPostRestEndPointCalled func(address string, path string, data interface{}, response interface{}) error
+	GetShardIdsCalled          func() []uint32
+	GetHedgingDelayCalled      func(shardId uint32) time.Duration
+	RecordReadLatencyCalled    func(shardId uint32, latency time.Duration)
+	CountFailoverCalled        func(shardId uint32)
+	CallGetRestEndPointCalled  func(ctx context.Context, address string, path string, value interface{}) error
+	CallPostRestEndPointCalled func(ctx context.Context, address string, path string, data interface{}, response interface{}) error
+}
+
+func (ps *ProcessorStub) ValidateConfig(cfg *config.Config) error {
+	if ps.ValidateConfigCalled != nil {
+		return ps.ValidateConfigCalled(cfg)
+	}
+
+	return nil
 }
 
 func (ps *ProcessorStub) ApplyConfig(cfg *config.Config) error {
@@ -40,17 +56,45 @@ func (ps *ProcessorStub) ComputeShardId(addressBuff []byte) (uint32, error) {
 	return 0, errNotImplemented
 }
 
-func (ps *ProcessorStub) CallGetRestEndPoint(address string, path string, value interface{}) error {
+func (ps *ProcessorStub) GetShardIds() []uint32 {
+	if ps.GetShardIdsCalled != nil {
+		return ps.GetShardIdsCalled()
+	}
+
+	return nil
+}
+
+func (ps *ProcessorStub) GetHedgingDelay(shardId uint32) time.Duration {
+	if ps.GetHedgingDelayCalled != nil {
+		return ps.GetHedgingDelayCalled(shardId)
+	}
+
+	return 0
+}
+
+func (ps *ProcessorStub) RecordReadLatency(shardId uint32, latency time.Duration) {
+	if ps.RecordReadLatencyCalled != nil {
+		ps.RecordReadLatencyCalled(shardId, latency)
+	}
+}
+
+func (ps *ProcessorStub) CountFailover(shardId uint32) {
+	if ps.CountFailoverCalled != nil {
+		ps.CountFailoverCalled(shardId)
+	}
+}
+
+func (ps *ProcessorStub) CallGetRestEndPoint(ctx context.Context, address string, path string, value interface{}) error {
 	if ps.CallGetRestEndPointCalled != nil {
-		return ps.CallGetRestEndPointCalled(address, path, value)
+		return ps.CallGetRestEndPointCalled(ctx, address, path, value)
 	}
 
 	return errNotImplemented
 }
 
-func (ps *ProcessorStub) CallPostRestEndPoint(address string, path string, data interface{}, response interface{}) error {
+func (ps *ProcessorStub) CallPostRestEndPoint(ctx context.Context, address string, path string, data interface{}, response interface{}) error {
 	if ps.CallPostRestEndPointCalled != nil {
-		return ps.CallPostRestEndPointCalled(address, path, data, response)
+		return ps.CallPostRestEndPointCalled(ctx, address, path, data, response)
 	}
 
 	return errNotImplemented
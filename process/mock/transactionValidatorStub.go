@@ -0,0 +1,15 @@
+package mock
+
+import "github.com/numbatx/numbat-proxy/data"
+
+type TransactionValidatorStub struct {
+	ValidateTransactionCalled func(tx *data.Transaction) error
+}
+
+func (tvs *TransactionValidatorStub) ValidateTransaction(tx *data.Transaction) error {
+	if tvs.ValidateTransactionCalled != nil {
+		return tvs.ValidateTransactionCalled(tx)
+	}
+
+	return nil
+}
@@ -0,0 +1,230 @@
+package process
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/numbatx/numbat-proxy/config"
+	"github.com/numbatx/numbat-proxy/data"
+)
+
+const fullSendersSweepInterval = time.Second
+
+type trackedNonce struct {
+	expiresAt time.Time
+	isSent    bool
+}
+
+type senderNonces struct {
+	lastAccountNonce uint64
+	nonces           map[uint64]*trackedNonce
+}
+
+// removeStale drops the expired nonces and the nonces the sender's account already moved past
+func (sn *senderNonces) removeStale(now time.Time) {
+	for nonce, tracked := range sn.nonces {
+		if nonce < sn.lastAccountNonce || !now.Before(tracked.expiresAt) {
+			delete(sn.nonces, nonce)
+		}
+	}
+}
+
+// nextNonce returns the lowest nonce, starting from the account's nonce, that is not tracked, so the nonces
+// of the expired reservations are handed out again instead of leaving gaps
+func (sn *senderNonces) nextNonce() uint64 {
+	next := sn.lastAccountNonce
+	for {
+		_, isTracked := sn.nonces[next]
+		if !isTracked {
+			return next
+		}
+		next++
+	}
+}
+
+func (sn *senderNonces) numPending() int {
+	numPending := 0
+	for _, tracked := range sn.nonces {
+		if tracked.isSent {
+			numPending++
+		}
+	}
+
+	return numPending
+}
+
+// NonceAllocator hands out to the senders the lowest nonces, starting from their account's nonce, that are
+// neither reserved nor used by their transactions relayed by the proxy and still pending. A reservation is
+// released when it expires or when the sender's nonce moves past it, so the nonce can be handed out again.
+// At most maxSenders senders are tracked. When disabled, no nonce is handed out
+type NonceAllocator struct {
+	accountGetter      AccountGetter
+	codec              AddressCodec
+	isEnabled          bool
+	reservationTTL     time.Duration
+	pendingTTL         time.Duration
+	maxNoncesPerSender int
+	maxSenders         int
+
+	mutSenders sync.Mutex
+	senders    map[string]*senderNonces
+	lastSweep  time.Time
+}
+
+// NewNonceAllocator creates a new instance of NonceAllocator. The account getter should not be the accounts
+// cache, as a cached nonce lags behind the sender's transactions
+func NewNonceAllocator(
+	accountGetter AccountGetter,
+	codec AddressCodec,
+	cfg config.NonceAllocationConfig,
+) (*NonceAllocator, error) {
+
+	if accountGetter == nil {
+		return nil, ErrNilAccountGetter
+	}
+	if codec == nil {
+		return nil, ErrNilAddressCodec
+	}
+	if !cfg.Enabled {
+		return &NonceAllocator{
+			accountGetter: accountGetter,
+			codec:         codec,
+		}, nil
+	}
+	if cfg.ReservationTTLInMs <= 0 || cfg.PendingTTLInMs <= 0 || cfg.MaxNoncesPerSender <= 0 || cfg.MaxSenders <= 0 {
+		return nil, ErrInvalidNonceAllocationConfig
+	}
+
+	return &NonceAllocator{
+		accountGetter:      accountGetter,
+		codec:              codec,
+		isEnabled:          true,
+		reservationTTL:     time.Duration(cfg.ReservationTTLInMs) * time.Millisecond,
+		pendingTTL:         time.Duration(cfg.PendingTTLInMs) * time.Millisecond,
+		maxNoncesPerSender: cfg.MaxNoncesPerSender,
+		maxSenders:         cfg.MaxSenders,
+		senders:            make(map[string]*senderNonces),
+		lastSweep:          time.Now(),
+	}, nil
+}
+
+// ReserveNonce fetches the sender's account and reserves the sender's next nonce
+func (na *NonceAllocator) ReserveNonce(ctx context.Context, address string) (*data.NonceReservation, error) {
+	if !na.isEnabled {
+		return nil, ErrNonceAllocationDisabled
+	}
+
+	key, err := normalizeAddress(na.codec, address)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := na.accountGetter.GetAccount(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	na.mutSenders.Lock()
+	defer na.mutSenders.Unlock()
+
+	na.sweepIfNeeded(now)
+	sender, ok := na.getOrCreateSender(key, now)
+	if !ok {
+		return nil, ErrTooManyTrackedSenders
+	}
+	// concurrent requests may fetch the account in a different order than they get here, so the nonce
+	// never goes back to an older value
+	if account.Nonce > sender.lastAccountNonce {
+		sender.lastAccountNonce = account.Nonce
+	}
+	sender.removeStale(now)
+	if len(sender.nonces) >= na.maxNoncesPerSender {
+		return nil, ErrTooManyReservedNonces
+	}
+
+	nonce := sender.nextNonce()
+	expiresAt := now.Add(na.reservationTTL)
+	sender.nonces[nonce] = &trackedNonce{expiresAt: expiresAt}
+
+	return &data.NonceReservation{
+		Address:      fromObserverAddress(na.codec, key),
+		Nonce:        nonce,
+		AccountNonce: sender.lastAccountNonce,
+		NumPending:   sender.numPending(),
+		ExpiresAt:    expiresAt,
+	}, nil
+}
+
+// RecordSentTransaction tracks the nonce of a transaction relayed by the proxy as pending, whether it was
+// reserved or not
+func (na *NonceAllocator) RecordSentTransaction(sender string, nonce uint64) {
+	if !na.isEnabled {
+		return
+	}
+
+	key, err := normalizeAddress(na.codec, sender)
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+
+	na.mutSenders.Lock()
+	defer na.mutSenders.Unlock()
+
+	na.sweepIfNeeded(now)
+	senderNonces, ok := na.getOrCreateSender(key, now)
+	if !ok || nonce < senderNonces.lastAccountNonce {
+		return
+	}
+
+	senderNonces.nonces[nonce] = &trackedNonce{
+		expiresAt: now.Add(na.pendingTTL),
+		isSent:    true,
+	}
+}
+
+// getOrCreateSender returns false if the sender is not tracked and the maximum number of senders is reached,
+// even after forgetting the idle senders. The idle senders are looked for at most once per second, so a flood
+// of new senders does not scan all of them on each request
+func (na *NonceAllocator) getOrCreateSender(key string, now time.Time) (*senderNonces, bool) {
+	sender, ok := na.senders[key]
+	if ok {
+		return sender, true
+	}
+
+	if len(na.senders) >= na.maxSenders && now.Sub(na.lastSweep) >= fullSendersSweepInterval {
+		na.sweep(now)
+	}
+	if len(na.senders) >= na.maxSenders {
+		return nil, false
+	}
+	sender = &senderNonces{
+		nonces: make(map[uint64]*trackedNonce),
+	}
+	na.senders[key] = sender
+
+	return sender, true
+}
+
+// sweepIfNeeded forgets, once per pending TTL, the senders whose nonces all expired
+func (na *NonceAllocator) sweepIfNeeded(now time.Time) {
+	if now.Sub(na.lastSweep) < na.pendingTTL {
+		return
+	}
+
+	na.sweep(now)
+}
+
+func (na *NonceAllocator) sweep(now time.Time) {
+	for key, sender := range na.senders {
+		sender.removeStale(now)
+		if len(sender.nonces) == 0 {
+			delete(na.senders, key)
+		}
+	}
+	na.lastSweep = now
+}
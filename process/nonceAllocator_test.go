@@ -0,0 +1,228 @@
+package process_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/numbatx/numbat-proxy/config"
+	"github.com/numbatx/numbat-proxy/data"
+	"github.com/numbatx/numbat-proxy/process"
+	"github.com/numbatx/numbat-proxy/process/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func createNonceAllocationConfig() config.NonceAllocationConfig {
+	return config.NonceAllocationConfig{
+		Enabled:            true,
+		ReservationTTLInMs: 60000,
+		PendingTTLInMs:     60000,
+		MaxNoncesPerSender: 100,
+		MaxSenders:         100,
+	}
+}
+
+func TestNewNonceAllocator_InvalidArgumentsShouldErr(t *testing.T) {
+	t.Parallel()
+
+	na, err := process.NewNonceAllocator(nil, createHexAddressCodec(), createNonceAllocationConfig())
+	assert.Nil(t, na)
+	assert.Equal(t, process.ErrNilAccountGetter, err)
+
+	na, err = process.NewNonceAllocator(&mock.AccountGetterStub{}, nil, createNonceAllocationConfig())
+	assert.Nil(t, na)
+	assert.Equal(t, process.ErrNilAddressCodec, err)
+
+	cfg := createNonceAllocationConfig()
+	cfg.MaxNoncesPerSender = 0
+	na, err = process.NewNonceAllocator(&mock.AccountGetterStub{}, createHexAddressCodec(), cfg)
+	assert.Nil(t, na)
+	assert.Equal(t, process.ErrInvalidNonceAllocationConfig, err)
+
+	cfg = createNonceAllocationConfig()
+	cfg.MaxSenders = 0
+	na, err = process.NewNonceAllocator(&mock.AccountGetterStub{}, createHexAddressCodec(), cfg)
+	assert.Nil(t, na)
+	assert.Equal(t, process.ErrInvalidNonceAllocationConfig, err)
+}
+
+func TestNonceAllocator_DisabledShouldErr(t *testing.T) {
+	t.Parallel()
+
+	na, _ := process.NewNonceAllocator(createAccountGetterWithNonce(1), createHexAddressCodec(), config.NonceAllocationConfig{})
+
+	reservation, err := na.ReserveNonce(context.Background(), "aa")
+	assert.Nil(t, reservation)
+	assert.Equal(t, process.ErrNonceAllocationDisabled, err)
+}
+
+func TestNonceAllocator_ReserveNonceShouldHandOutIncreasingNonces(t *testing.T) {
+	t.Parallel()
+
+	na, _ := process.NewNonceAllocator(createAccountGetterWithNonce(5), createHexAddressCodec(), createNonceAllocationConfig())
+
+	for expectedNonce := uint64(5); expectedNonce < 8; expectedNonce++ {
+		reservation, err := na.ReserveNonce(context.Background(), "aa")
+		assert.Nil(t, err)
+		assert.Equal(t, expectedNonce, reservation.Nonce)
+		assert.Equal(t, uint64(5), reservation.AccountNonce)
+		assert.Equal(t, "aa", reservation.Address)
+	}
+
+	reservation, _ := na.ReserveNonce(context.Background(), "bb")
+	assert.Equal(t, uint64(5), reservation.Nonce)
+}
+
+func TestNonceAllocator_ReserveNonceConcurrentRequestsShouldGetDifferentNonces(t *testing.T) {
+	t.Parallel()
+
+	na, _ := process.NewNonceAllocator(createAccountGetterWithNonce(0), createHexAddressCodec(), createNonceAllocationConfig())
+
+	numRequests := 50
+	mutNonces := sync.Mutex{}
+	nonces := make(map[uint64]struct{})
+	wg := sync.WaitGroup{}
+	wg.Add(numRequests)
+	for i := 0; i < numRequests; i++ {
+		go func() {
+			reservation, err := na.ReserveNonce(context.Background(), "aa")
+			assert.Nil(t, err)
+
+			mutNonces.Lock()
+			nonces[reservation.Nonce] = struct{}{}
+			mutNonces.Unlock()
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, numRequests, len(nonces))
+}
+
+func TestNonceAllocator_ShouldFollowTheSentTransactions(t *testing.T) {
+	t.Parallel()
+
+	na, _ := process.NewNonceAllocator(createAccountGetterWithNonce(5), createBech32AddressCodec(), createNonceAllocationConfig())
+	bech32Address := createBech32AddressCodec().EncodeAddress([]byte{0xaa})
+
+	na.RecordSentTransaction("aa", 5)
+	na.RecordSentTransaction("aa", 6)
+	reservation, _ := na.ReserveNonce(context.Background(), bech32Address)
+
+	assert.Equal(t, uint64(7), reservation.Nonce)
+	assert.Equal(t, 2, reservation.NumPending)
+	assert.Equal(t, bech32Address, reservation.Address)
+
+	// a reserved nonce that is then sent is no longer counted as a reservation
+	na.RecordSentTransaction(bech32Address, 7)
+	reservation, _ = na.ReserveNonce(context.Background(), "aa")
+	assert.Equal(t, uint64(8), reservation.Nonce)
+	assert.Equal(t, 3, reservation.NumPending)
+}
+
+func TestNonceAllocator_ShouldResetWhenTheAccountNonceMovesPast(t *testing.T) {
+	t.Parallel()
+
+	accountNonce := uint64(5)
+	mutNonce := sync.Mutex{}
+	accountGetter := &mock.AccountGetterStub{
+		GetAccountCalled: func(ctx context.Context, address string) (*data.Account, error) {
+			mutNonce.Lock()
+			defer mutNonce.Unlock()
+
+			return &data.Account{Nonce: accountNonce}, nil
+		},
+	}
+	na, _ := process.NewNonceAllocator(accountGetter, createHexAddressCodec(), createNonceAllocationConfig())
+
+	_, _ = na.ReserveNonce(context.Background(), "aa")
+	_, _ = na.ReserveNonce(context.Background(), "aa")
+	na.RecordSentTransaction("aa", 7)
+
+	mutNonce.Lock()
+	accountNonce = 10
+	mutNonce.Unlock()
+	reservation, _ := na.ReserveNonce(context.Background(), "aa")
+	assert.Equal(t, uint64(10), reservation.Nonce)
+	assert.Equal(t, 0, reservation.NumPending)
+
+	// an older account nonce, fetched by a slower request, does not move the nonces back
+	mutNonce.Lock()
+	accountNonce = 6
+	mutNonce.Unlock()
+	reservation, _ = na.ReserveNonce(context.Background(), "aa")
+	assert.Equal(t, uint64(11), reservation.Nonce)
+	assert.Equal(t, uint64(10), reservation.AccountNonce)
+}
+
+func TestNonceAllocator_ExpiredReservationsShouldBeReleased(t *testing.T) {
+	t.Parallel()
+
+	cfg := createNonceAllocationConfig()
+	cfg.ReservationTTLInMs = 10
+	na, _ := process.NewNonceAllocator(createAccountGetterWithNonce(5), createHexAddressCodec(), cfg)
+
+	_, _ = na.ReserveNonce(context.Background(), "aa")
+	reservation, _ := na.ReserveNonce(context.Background(), "aa")
+	assert.Equal(t, uint64(6), reservation.Nonce)
+
+	time.Sleep(20 * time.Millisecond)
+	reservation, _ = na.ReserveNonce(context.Background(), "aa")
+	assert.Equal(t, uint64(5), reservation.Nonce)
+}
+
+func TestNonceAllocator_ExpiredReservationBelowALiveOneShouldBeHandedOutAgain(t *testing.T) {
+	t.Parallel()
+
+	cfg := createNonceAllocationConfig()
+	cfg.ReservationTTLInMs = 100
+	na, _ := process.NewNonceAllocator(createAccountGetterWithNonce(5), createHexAddressCodec(), cfg)
+
+	reservation, _ := na.ReserveNonce(context.Background(), "aa")
+	assert.Equal(t, uint64(5), reservation.Nonce)
+	time.Sleep(60 * time.Millisecond)
+	reservation, _ = na.ReserveNonce(context.Background(), "aa")
+	assert.Equal(t, uint64(6), reservation.Nonce)
+
+	// the reservation of 5 expired while the one of 6 is still live
+	time.Sleep(60 * time.Millisecond)
+	reservation, _ = na.ReserveNonce(context.Background(), "aa")
+	assert.Equal(t, uint64(5), reservation.Nonce)
+	reservation, _ = na.ReserveNonce(context.Background(), "aa")
+	assert.Equal(t, uint64(7), reservation.Nonce)
+}
+
+func TestNonceAllocator_TooManySendersShouldErr(t *testing.T) {
+	t.Parallel()
+
+	cfg := createNonceAllocationConfig()
+	cfg.MaxSenders = 1
+	na, _ := process.NewNonceAllocator(createAccountGetterWithNonce(0), createHexAddressCodec(), cfg)
+
+	_, err := na.ReserveNonce(context.Background(), "aa")
+	assert.Nil(t, err)
+	reservation, err := na.ReserveNonce(context.Background(), "bb")
+	assert.Nil(t, reservation)
+	assert.Equal(t, process.ErrTooManyTrackedSenders, err)
+
+	na.RecordSentTransaction("bb", 0)
+	reservation, err = na.ReserveNonce(context.Background(), "aa")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), reservation.Nonce)
+}
+
+func TestNonceAllocator_TooManyNoncesShouldErr(t *testing.T) {
+	t.Parallel()
+
+	cfg := createNonceAllocationConfig()
+	cfg.MaxNoncesPerSender = 2
+	na, _ := process.NewNonceAllocator(createAccountGetterWithNonce(0), createHexAddressCodec(), cfg)
+
+	_, _ = na.ReserveNonce(context.Background(), "aa")
+	na.RecordSentTransaction("aa", 1)
+	reservation, err := na.ReserveNonce(context.Background(), "aa")
+
+	assert.Nil(t, reservation)
+	assert.Equal(t, process.ErrTooManyReservedNonces, err)
+}
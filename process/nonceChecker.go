@@ -0,0 +1,99 @@
+package process
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/numbatx/numbat-proxy/config"
+	"github.com/numbatx/numbat-proxy/data"
+)
+
+// NonceError signals that a transaction was not sent because of its nonce. It wraps ErrInvalidNonce
+type NonceError struct {
+	Check *data.NonceCheck
+}
+
+// Error returns the error message explaining why the nonce was rejected
+func (ne *NonceError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrInvalidNonce.Error(), ne.Check.Message)
+}
+
+// Unwrap returns ErrInvalidNonce
+func (ne *NonceError) Unwrap() error {
+	return ErrInvalidNonce
+}
+
+// NonceChecker compares the nonce of the transactions with the current nonce of their sender, so the
+// transactions that would never be executed, or only after a long wait, are reported before being sent.
+// When disabled, the nonces are not checked
+type NonceChecker struct {
+	accountGetter       AccountGetter
+	isEnabled           bool
+	maxNonceGap         uint64
+	rejectInvalidNonces bool
+}
+
+// NewNonceChecker creates a new instance of NonceChecker. The senders are requested from the provided account
+// getter, which should be the accounts cache so the consecutive transactions of a sender do not reach the
+// observers each time. When enabled, the maximum nonce gap must be positive, as a zero gap would report every
+// transaction queued after the sender's next one as too high
+func NewNonceChecker(accountGetter AccountGetter, cfg config.NonceCheckConfig) (*NonceChecker, error) {
+	if accountGetter == nil {
+		return nil, ErrNilAccountGetter
+	}
+	if cfg.Enabled && cfg.MaxNonceGap == 0 {
+		return nil, ErrInvalidNonceCheckConfig
+	}
+
+	return &NonceChecker{
+		accountGetter:       accountGetter,
+		isEnabled:           cfg.Enabled,
+		maxNonceGap:         cfg.MaxNonceGap,
+		rejectInvalidNonces: cfg.RejectInvalidNonces,
+	}, nil
+}
+
+// CheckNonce returns the outcome of the nonce comparison, or nil if the nonces are not checked. If the
+// invalid nonces are rejected, a *NonceError holding the outcome is returned as well. A sender that can
+// not be fetched does not prevent the transaction from being sent
+func (nc *NonceChecker) CheckNonce(ctx context.Context, tx *data.Transaction) (*data.NonceCheck, error) {
+	if !nc.isEnabled {
+		return nil, nil
+	}
+
+	check := &data.NonceCheck{
+		TransactionNonce: tx.Nonce,
+	}
+
+	account, err := nc.accountGetter.GetAccount(ctx, tx.Sender)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		log.Warn(fmt.Sprintf("could not fetch the nonce of sender %s: %s", tx.Sender, err.Error()))
+		check.Status = data.NonceStatusUnknown
+		check.Message = "the sender's current nonce could not be fetched"
+		return check, nil
+	}
+
+	check.AccountNonce = account.Nonce
+	switch {
+	case tx.Nonce < account.Nonce:
+		check.Status = data.NonceStatusTooLow
+		check.Message = fmt.Sprintf("nonce %d is lower than the sender's current nonce %d, "+
+			"the transaction will not be executed", tx.Nonce, account.Nonce)
+	case tx.Nonce-account.Nonce > nc.maxNonceGap:
+		check.Status = data.NonceStatusTooHigh
+		check.Message = fmt.Sprintf("nonce %d is %d ahead of the sender's current nonce %d, the transaction "+
+			"will not be executed until the missing nonces are sent", tx.Nonce, tx.Nonce-account.Nonce, account.Nonce)
+	default:
+		check.Status = data.NonceStatusOk
+	}
+
+	if check.Status != data.NonceStatusOk && nc.rejectInvalidNonces {
+		return check, &NonceError{Check: check}
+	}
+
+	return check, nil
+}
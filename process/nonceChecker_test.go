@@ -0,0 +1,121 @@
+package process_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/numbatx/numbat-proxy/config"
+	"github.com/numbatx/numbat-proxy/data"
+	"github.com/numbatx/numbat-proxy/process"
+	"github.com/numbatx/numbat-proxy/process/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func createAccountGetterWithNonce(nonce uint64) *mock.AccountGetterStub {
+	return &mock.AccountGetterStub{
+		GetAccountCalled: func(ctx context.Context, address string) (*data.Account, error) {
+			return &data.Account{Address: address, Nonce: nonce}, nil
+		},
+	}
+}
+
+func TestNewNonceChecker_NilAccountGetterShouldErr(t *testing.T) {
+	t.Parallel()
+
+	nc, err := process.NewNonceChecker(nil, config.NonceCheckConfig{Enabled: true})
+
+	assert.Nil(t, nc)
+	assert.Equal(t, process.ErrNilAccountGetter, err)
+}
+
+func TestNewNonceChecker_ZeroMaxNonceGapShouldErr(t *testing.T) {
+	t.Parallel()
+
+	nc, err := process.NewNonceChecker(createAccountGetterWithNonce(10), config.NonceCheckConfig{Enabled: true})
+
+	assert.Nil(t, nc)
+	assert.Equal(t, process.ErrInvalidNonceCheckConfig, err)
+}
+
+func TestNonceChecker_DisabledShouldNotFetchTheSender(t *testing.T) {
+	t.Parallel()
+
+	nc, _ := process.NewNonceChecker(&mock.AccountGetterStub{
+		GetAccountCalled: func(ctx context.Context, address string) (*data.Account, error) {
+			assert.Fail(t, "the sender should not be fetched")
+			return nil, nil
+		},
+	}, config.NonceCheckConfig{})
+
+	check, err := nc.CheckNonce(context.Background(), &data.Transaction{Nonce: 100})
+
+	assert.Nil(t, check)
+	assert.Nil(t, err)
+}
+
+func TestNonceChecker_CheckNonceShouldCompareWithTheSendersNonce(t *testing.T) {
+	t.Parallel()
+
+	nc, _ := process.NewNonceChecker(createAccountGetterWithNonce(10), config.NonceCheckConfig{
+		Enabled:     true,
+		MaxNonceGap: 5,
+	})
+
+	testCases := []struct {
+		nonce  uint64
+		status string
+	}{
+		{nonce: 9, status: data.NonceStatusTooLow},
+		{nonce: 10, status: data.NonceStatusOk},
+		{nonce: 15, status: data.NonceStatusOk},
+		{nonce: 16, status: data.NonceStatusTooHigh},
+	}
+	for _, tc := range testCases {
+		check, err := nc.CheckNonce(context.Background(), &data.Transaction{Nonce: tc.nonce, Sender: "aa"})
+
+		assert.Nil(t, err)
+		assert.Equal(t, tc.status, check.Status, tc.nonce)
+		assert.Equal(t, uint64(10), check.AccountNonce)
+		assert.Equal(t, tc.nonce, check.TransactionNonce)
+		assert.Equal(t, tc.status == data.NonceStatusOk, check.Message == "")
+	}
+}
+
+func TestNonceChecker_RejectInvalidNoncesShouldErr(t *testing.T) {
+	t.Parallel()
+
+	nc, _ := process.NewNonceChecker(createAccountGetterWithNonce(10), config.NonceCheckConfig{
+		Enabled:             true,
+		MaxNonceGap:         5,
+		RejectInvalidNonces: true,
+	})
+
+	check, err := nc.CheckNonce(context.Background(), &data.Transaction{Nonce: 20, Sender: "aa"})
+
+	var nonceErr *process.NonceError
+	assert.True(t, errors.As(err, &nonceErr))
+	assert.True(t, errors.Is(err, process.ErrInvalidNonce))
+	assert.Equal(t, check, nonceErr.Check)
+	assert.Equal(t, data.NonceStatusTooHigh, check.Status)
+
+	check, err = nc.CheckNonce(context.Background(), &data.Transaction{Nonce: 12, Sender: "aa"})
+	assert.Nil(t, err)
+	assert.Equal(t, data.NonceStatusOk, check.Status)
+}
+
+func TestNonceChecker_SenderNotFetchedShouldReportUnknownStatus(t *testing.T) {
+	t.Parallel()
+
+	nc, _ := process.NewNonceChecker(&mock.AccountGetterStub{
+		GetAccountCalled: func(ctx context.Context, address string) (*data.Account, error) {
+			return nil, errors.New("expected error")
+		},
+	}, config.NonceCheckConfig{Enabled: true, MaxNonceGap: 5, RejectInvalidNonces: true})
+
+	check, err := nc.CheckNonce(context.Background(), &data.Transaction{Nonce: 3, Sender: "aa"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, data.NonceStatusUnknown, check.Status)
+	assert.Equal(t, uint64(3), check.TransactionNonce)
+}
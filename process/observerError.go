@@ -0,0 +1,90 @@
+package process
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxErrorBodySize is the maximum number of bytes read from an observer's error response
+const maxErrorBodySize = 4096
+
+// missingRouteMessage is the body the observers' http router answers with for the paths it does not serve
+const missingRouteMessage = "404 page not found"
+
+// ObserverError is returned when an observer responds with a non 2xx status code. The observer's address is
+// only kept for the logs, as the error message is relayed to the clients
+type ObserverError struct {
+	Address    string
+	StatusCode int
+	Message    string
+}
+
+// Error returns the error message including the observer's status code, but not its address, which may
+// carry credentials
+func (oe *ObserverError) Error() string {
+	return fmt.Sprintf("observer responded with status code %d: %s", oe.StatusCode, oe.Message)
+}
+
+// IsRetryable returns true if the same request could succeed on another observer
+func (oe *ObserverError) IsRetryable() bool {
+	switch oe.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return true
+	default:
+		return oe.StatusCode >= http.StatusInternalServerError
+	}
+}
+
+// IsMissingRoute returns true if the observer does not serve the requested path at all, as opposed to not
+// finding the requested resource
+func (oe *ObserverError) IsMissingRoute() bool {
+	return oe.StatusCode == http.StatusNotFound && oe.Message == missingRouteMessage
+}
+
+// IsRetryableError returns true if a failed observer call is worth retrying on another observer.
+// Transport and decoding errors are retryable, while the client errors reported by the observers are not
+func IsRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var observerErr *ObserverError
+	if errors.As(err, &observerErr) {
+		return observerErr.IsRetryable()
+	}
+
+	return true
+}
+
+func newObserverError(address string, resp *http.Response) *ObserverError {
+	buff, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+
+	return &ObserverError{
+		Address:    address,
+		StatusCode: resp.StatusCode,
+		Message:    extractErrorMessage(buff, resp.StatusCode),
+	}
+}
+
+// extractErrorMessage returns the "error" field if the body is a JSON object holding one, the raw
+// body otherwise, or the status text if the body is empty
+func extractErrorMessage(body []byte, statusCode int) string {
+	errorResponse := struct {
+		Error string `json:"error"`
+	}{}
+	err := json.Unmarshal(body, &errorResponse)
+	if err == nil && errorResponse.Error != "" {
+		return errorResponse.Error
+	}
+
+	message := strings.TrimSpace(string(body))
+	if message == "" {
+		return http.StatusText(statusCode)
+	}
+
+	return message
+}
@@ -0,0 +1,92 @@
+package process
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/numbatx/numbat-proxy/metrics"
+)
+
+const (
+	resultSuccess     = "success"
+	resultError       = "error"
+	resultObserverErr = "observer_error"
+	resultCanceled    = "canceled"
+	unknownShard      = "unknown"
+)
+
+// observerMetrics holds the statistics of the calls made by the proxy to its observers. The observers are
+// labeled by their address, with its password redacted
+type observerMetrics struct {
+	requests  *metrics.CounterVec
+	durations *metrics.HistogramVec
+	failovers *metrics.CounterVec
+}
+
+func newObserverMetrics(registry *metrics.Registry) (*observerMetrics, error) {
+	requests, err := registry.NewCounterVec(
+		"numbat_proxy_observer_requests_total",
+		"Number of requests sent to observers, by observer address, shard and result",
+		"address", "shard", "result",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	durations, err := registry.NewHistogramVec(
+		"numbat_proxy_observer_request_duration_seconds",
+		"Duration of the requests sent to observers, by observer address and shard",
+		metrics.DefaultLatencyBuckets,
+		"address", "shard",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	failovers, err := registry.NewCounterVec(
+		"numbat_proxy_observer_failovers_total",
+		"Number of times a request was retried on another observer of the same shard",
+		"shard",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &observerMetrics{
+		requests:  requests,
+		durations: durations,
+		failovers: failovers,
+	}, nil
+}
+
+// observeCall records a finished call. Observers answering with a non 2xx status code are counted
+// separately from the calls that did not get an answer at all
+func (om *observerMetrics) observeCall(address string, shard string, duration time.Duration, err error) {
+	result := resultSuccess
+	if err != nil {
+		result = resultError
+		var observerErr *ObserverError
+		if errors.As(err, &observerErr) {
+			result = resultObserverErr
+		}
+	}
+
+	redactedAddress := redactAddress(address)
+	om.requests.Inc(redactedAddress, shard, result)
+	om.durations.Observe(duration.Seconds(), redactedAddress, shard)
+}
+
+func (om *observerMetrics) countFailover(shardId uint32) {
+	om.failovers.Inc(formatShardId(shardId))
+}
+
+// observeCanceledCall counts a call canceled by its caller. Its duration is not recorded, as it says nothing
+// about the observer
+func (om *observerMetrics) observeCanceledCall(address string, shard string) {
+	om.requests.Inc(redactAddress(address), shard, resultCanceled)
+}
+
+func formatShardId(shardId uint32) string {
+	return strconv.FormatUint(uint64(shardId), 10)
+}
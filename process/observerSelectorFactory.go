@@ -0,0 +1,37 @@
+package process
+
+const (
+	// OrderedSelection tries the observers in the order they were configured
+	OrderedSelection = "ordered"
+	// RoundRobinSelection rotates the first tried observer on each request
+	RoundRobinSelection = "round-robin"
+	// RandomSelection tries the observers in a random order
+	RandomSelection = "random"
+	// LeastInFlightSelection tries first the observers with the fewest requests in progress
+	LeastInFlightSelection = "least-in-flight"
+	// EwmaLatencySelection tries first the observers with the lowest exponentially weighted average latency
+	EwmaLatencySelection = "ewma-latency"
+	// WeightedSelection tries the observers in a random order biased by their configured weight
+	WeightedSelection = "weighted"
+)
+
+// NewObserverSelector creates the observer selector matching the provided strategy name.
+// An empty strategy defaults to OrderedSelection
+func NewObserverSelector(strategy string) (ObserverSelector, error) {
+	switch strategy {
+	case "", OrderedSelection:
+		return NewOrderedSelector(), nil
+	case RoundRobinSelection:
+		return NewRoundRobinSelector(), nil
+	case RandomSelection:
+		return NewRandomSelector(), nil
+	case LeastInFlightSelection:
+		return NewLeastInFlightSelector(), nil
+	case EwmaLatencySelection:
+		return NewEwmaLatencySelector(), nil
+	case WeightedSelection:
+		return NewWeightedSelector(), nil
+	default:
+		return nil, ErrUnknownObserverSelection
+	}
+}
@@ -0,0 +1,189 @@
+package process_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/numbatx/numbat-proxy/config"
+	"github.com/numbatx/numbat-proxy/data"
+	"github.com/numbatx/numbat-proxy/metrics"
+	"github.com/numbatx/numbat-proxy/process"
+	"github.com/numbatx/numbat-proxy/process/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func createSelectorTestObservers() []*data.Observer {
+	return []*data.Observer{
+		{Address: "address1", ShardId: 0},
+		{Address: "address2", ShardId: 0},
+		{Address: "address3", ShardId: 0},
+	}
+}
+
+func TestNewObserverSelector_UnknownStrategyShouldErr(t *testing.T) {
+	t.Parallel()
+
+	selector, err := process.NewObserverSelector("unknown")
+
+	assert.Nil(t, selector)
+	assert.Equal(t, process.ErrUnknownObserverSelection, err)
+}
+
+func TestNewObserverSelector_KnownStrategiesShouldWork(t *testing.T) {
+	t.Parallel()
+
+	strategies := []string{
+		"",
+		process.OrderedSelection,
+		process.RoundRobinSelection,
+		process.RandomSelection,
+		process.LeastInFlightSelection,
+		process.EwmaLatencySelection,
+		process.WeightedSelection,
+	}
+	observers := createSelectorTestObservers()
+	for _, strategy := range strategies {
+		selector, err := process.NewObserverSelector(strategy)
+
+		assert.Nil(t, err)
+		assert.ElementsMatch(t, observers, selector.Select(0, observers))
+	}
+}
+
+func TestOrderedSelector_SelectShouldKeepOrder(t *testing.T) {
+	t.Parallel()
+
+	observers := createSelectorTestObservers()
+	selector := process.NewOrderedSelector()
+
+	assert.Equal(t, observers, selector.Select(0, observers))
+	assert.Equal(t, observers, selector.Select(0, observers))
+}
+
+func TestRoundRobinSelector_SelectShouldRotatePerShard(t *testing.T) {
+	t.Parallel()
+
+	observers := createSelectorTestObservers()
+	selector := process.NewRoundRobinSelector()
+
+	assert.Equal(t, observers[0], selector.Select(0, observers)[0])
+	assert.Equal(t, observers[1], selector.Select(0, observers)[0])
+	assert.Equal(t, observers[0], selector.Select(1, observers)[0])
+	selected := selector.Select(0, observers)
+	assert.Equal(t, []*data.Observer{observers[2], observers[0], observers[1]}, selected)
+	assert.Equal(t, observers[0], selector.Select(0, observers)[0])
+}
+
+func TestLeastInFlightSelector_SelectShouldPreferIdleObservers(t *testing.T) {
+	t.Parallel()
+
+	observers := createSelectorTestObservers()
+	selector := process.NewLeastInFlightSelector()
+
+	selector.CallStarted("address1")
+	selector.CallStarted("address1")
+	selector.CallStarted("address2")
+	selected := selector.Select(0, observers)
+	assert.Equal(t, []*data.Observer{observers[2], observers[1], observers[0]}, selected)
+
+	selector.CallFinished("address1", time.Millisecond, nil)
+	selector.CallFinished("address1", time.Millisecond, nil)
+	selected = selector.Select(0, observers)
+	assert.Equal(t, []*data.Observer{observers[0], observers[2], observers[1]}, selected)
+}
+
+func TestLeastInFlightSelector_CanceledCallsShouldNotBeInFlight(t *testing.T) {
+	t.Parallel()
+
+	observers := createSelectorTestObservers()
+	selector := process.NewLeastInFlightSelector()
+
+	selector.CallStarted("address1")
+	selector.CallStarted("address2")
+	selector.CallCanceled("address1")
+	selected := selector.Select(0, observers)
+
+	assert.Equal(t, []*data.Observer{observers[0], observers[2], observers[1]}, selected)
+}
+
+func TestEwmaLatencySelector_SelectShouldPreferFastObservers(t *testing.T) {
+	t.Parallel()
+
+	observers := createSelectorTestObservers()
+	selector := process.NewEwmaLatencySelector()
+
+	selector.CallFinished("address1", 100*time.Millisecond, nil)
+	selector.CallFinished("address2", 10*time.Millisecond, nil)
+	selector.CallFinished("address3", time.Millisecond, errors.New("expected error"))
+	selected := selector.Select(0, observers)
+
+	assert.Equal(t, []*data.Observer{observers[1], observers[0], observers[2]}, selected)
+}
+
+func TestEwmaLatencySelector_SelectShouldPreferUnmeasuredObservers(t *testing.T) {
+	t.Parallel()
+
+	observers := createSelectorTestObservers()
+	selector := process.NewEwmaLatencySelector()
+
+	selector.CallFinished("address1", 100*time.Millisecond, nil)
+	selector.CallFinished("address2", 10*time.Millisecond, nil)
+	selected := selector.Select(0, observers)
+
+	assert.Equal(t, observers[2], selected[0])
+}
+
+func TestWeightedSelector_SelectShouldFavorHeavyObservers(t *testing.T) {
+	t.Parallel()
+
+	observers := []*data.Observer{
+		{Address: "address1", ShardId: 0, Weight: 1},
+		{Address: "address2", ShardId: 0, Weight: 99},
+	}
+	selector := process.NewWeightedSelector()
+
+	numHeavyFirst := 0
+	numSelections := 1000
+	for i := 0; i < numSelections; i++ {
+		if selector.Select(0, observers)[0] == observers[1] {
+			numHeavyFirst++
+		}
+	}
+
+	assert.True(t, numHeavyFirst > numSelections*9/10)
+}
+
+//------- BaseProcessor integration
+
+func TestBaseProcessor_ApplyConfigUnknownSelectionShouldErr(t *testing.T) {
+	t.Parallel()
+
+	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{}, metrics.NewRegistry())
+	err := bp.ApplyConfig(&config.Config{
+		GeneralSettings: config.GeneralSettingsConfig{
+			ObserversSelection: "unknown",
+		},
+		Observers: createSelectorTestObservers(),
+	})
+
+	assert.Equal(t, process.ErrUnknownObserverSelection, err)
+}
+
+func TestBaseProcessor_GetObserversShouldUseConfiguredSelection(t *testing.T) {
+	t.Parallel()
+
+	observers := createSelectorTestObservers()
+	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{}, metrics.NewRegistry())
+	_ = bp.ApplyConfig(&config.Config{
+		GeneralSettings: config.GeneralSettingsConfig{
+			ObserversSelection: process.RoundRobinSelection,
+		},
+		Observers: observers,
+	})
+
+	selected, _ := bp.GetObservers(0)
+	assert.Equal(t, observers[0], selected[0])
+	selected, _ = bp.GetObservers(0)
+	assert.Equal(t, observers[1], selected[0])
+}
@@ -0,0 +1,179 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/numbatx/numbat-proxy/config"
+	"github.com/numbatx/numbat-proxy/data"
+)
+
+type observerHealth struct {
+	isHealthy            bool
+	consecutiveFailures  int
+	consecutiveSuccesses int
+}
+
+// ObserversHealthChecker periodically probes all the observers. An observer is marked as unhealthy
+// after a number of consecutive failed probes and is re-admitted after a number of consecutive
+// successful probes
+type ObserversHealthChecker struct {
+	handler           ObserversHealthHandler
+	path              string
+	interval          time.Duration
+	probeTimeout      time.Duration
+	failureThreshold  int
+	recoveryThreshold int
+
+	mutHealth sync.Mutex
+	health    map[string]*observerHealth
+	chanClose chan struct{}
+	closeOnce sync.Once
+}
+
+// NewObserversHealthChecker creates a new instance of ObserversHealthChecker
+func NewObserversHealthChecker(
+	handler ObserversHealthHandler,
+	cfg config.HealthCheckConfig,
+) (*ObserversHealthChecker, error) {
+
+	if handler == nil {
+		return nil, ErrNilObserversHealthHandler
+	}
+	if cfg.IntervalInSec <= 0 || cfg.ProbeTimeoutInSec <= 0 {
+		return nil, ErrInvalidHealthCheckConfig
+	}
+	if cfg.FailureThreshold <= 0 || cfg.RecoveryThreshold <= 0 {
+		return nil, ErrInvalidHealthCheckConfig
+	}
+
+	return &ObserversHealthChecker{
+		handler:           handler,
+		path:              cfg.Path,
+		interval:          time.Duration(cfg.IntervalInSec) * time.Second,
+		probeTimeout:      time.Duration(cfg.ProbeTimeoutInSec) * time.Second,
+		failureThreshold:  cfg.FailureThreshold,
+		recoveryThreshold: cfg.RecoveryThreshold,
+		health:            make(map[string]*observerHealth),
+		chanClose:         make(chan struct{}),
+	}, nil
+}
+
+// Start launches the go routine that will periodically probe the observers
+func (ohc *ObserversHealthChecker) Start() {
+	go ohc.run()
+}
+
+func (ohc *ObserversHealthChecker) run() {
+	ticker := time.NewTicker(ohc.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ohc.chanClose:
+			return
+		case <-ticker.C:
+			ohc.CheckObservers()
+		}
+	}
+}
+
+// CheckObservers probes all the observers once, in parallel, and updates their health
+func (ohc *ObserversHealthChecker) CheckObservers() {
+	observers := ohc.handler.GetAllObservers()
+
+	wg := &sync.WaitGroup{}
+	wg.Add(len(observers))
+	for _, observer := range observers {
+		go func(address string) {
+			ohc.updateHealth(address, ohc.probe(address))
+			wg.Done()
+		}(observer.Address)
+	}
+	wg.Wait()
+
+	ohc.removeStaleObservers(observers)
+}
+
+func (ohc *ObserversHealthChecker) probe(address string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), ohc.probeTimeout)
+	defer cancel()
+
+	response := make(map[string]interface{})
+
+	return ohc.handler.CallGetRestEndPoint(ctx, address, ohc.path, &response)
+}
+
+func (ohc *ObserversHealthChecker) updateHealth(address string, probeErr error) {
+	ohc.mutHealth.Lock()
+	defer ohc.mutHealth.Unlock()
+
+	oh, ok := ohc.health[address]
+	if !ok {
+		oh = &observerHealth{
+			isHealthy: true,
+		}
+		ohc.health[address] = oh
+	}
+
+	if probeErr != nil {
+		oh.consecutiveSuccesses = 0
+		oh.consecutiveFailures++
+		if oh.isHealthy && oh.consecutiveFailures >= ohc.failureThreshold {
+			oh.isHealthy = false
+			ohc.handler.SetObserverHealth(address, false)
+			log.Warn(fmt.Sprintf("observer %s marked as unhealthy: %s", address, probeErr.Error()))
+		}
+		return
+	}
+
+	oh.consecutiveFailures = 0
+	oh.consecutiveSuccesses++
+	if !oh.isHealthy && oh.consecutiveSuccesses >= ohc.recoveryThreshold {
+		oh.isHealthy = true
+		ohc.handler.SetObserverHealth(address, true)
+		log.Info(fmt.Sprintf("observer %s marked as healthy", address))
+	}
+}
+
+// removeStaleObservers forgets the observers that are no longer configured
+func (ohc *ObserversHealthChecker) removeStaleObservers(observers []*data.Observer) {
+	ohc.mutHealth.Lock()
+	defer ohc.mutHealth.Unlock()
+
+	configured := make(map[string]struct{}, len(observers))
+	for _, observer := range observers {
+		configured[observer.Address] = struct{}{}
+	}
+
+	for address := range ohc.health {
+		_, ok := configured[address]
+		if !ok {
+			delete(ohc.health, address)
+			ohc.handler.SetObserverHealth(address, true)
+		}
+	}
+}
+
+// IsHealthy returns true if the observer is considered healthy. Observers that were not probed yet
+// are considered healthy
+func (ohc *ObserversHealthChecker) IsHealthy(address string) bool {
+	ohc.mutHealth.Lock()
+	defer ohc.mutHealth.Unlock()
+
+	oh, ok := ohc.health[address]
+	if !ok {
+		return true
+	}
+
+	return oh.isHealthy
+}
+
+// Close stops the probing go routine
+func (ohc *ObserversHealthChecker) Close() {
+	ohc.closeOnce.Do(func() {
+		close(ohc.chanClose)
+	})
+}
@@ -0,0 +1,148 @@
+package process_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/numbatx/numbat-proxy/config"
+	"github.com/numbatx/numbat-proxy/data"
+	"github.com/numbatx/numbat-proxy/metrics"
+	"github.com/numbatx/numbat-proxy/process"
+	"github.com/numbatx/numbat-proxy/process/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func createHealthCheckConfig() config.HealthCheckConfig {
+	return config.HealthCheckConfig{
+		Enabled:           true,
+		Path:              "/node/status",
+		IntervalInSec:     1,
+		ProbeTimeoutInSec: 1,
+		FailureThreshold:  2,
+		RecoveryThreshold: 2,
+	}
+}
+
+func TestNewObserversHealthChecker_NilHandlerShouldErr(t *testing.T) {
+	t.Parallel()
+
+	ohc, err := process.NewObserversHealthChecker(nil, createHealthCheckConfig())
+
+	assert.Nil(t, ohc)
+	assert.Equal(t, process.ErrNilObserversHealthHandler, err)
+}
+
+func TestNewObserversHealthChecker_InvalidConfigShouldErr(t *testing.T) {
+	t.Parallel()
+
+	cfg := createHealthCheckConfig()
+	cfg.FailureThreshold = 0
+	ohc, err := process.NewObserversHealthChecker(&mock.ObserversHealthHandlerStub{}, cfg)
+
+	assert.Nil(t, ohc)
+	assert.Equal(t, process.ErrInvalidHealthCheckConfig, err)
+}
+
+func TestNewObserversHealthChecker_ShouldWork(t *testing.T) {
+	t.Parallel()
+
+	ohc, err := process.NewObserversHealthChecker(&mock.ObserversHealthHandlerStub{}, createHealthCheckConfig())
+
+	assert.NotNil(t, ohc)
+	assert.Nil(t, err)
+}
+
+//------- CheckObservers
+
+func TestObserversHealthChecker_CheckObserversShouldEvictAndReadmit(t *testing.T) {
+	t.Parallel()
+
+	addressFail := "address1"
+	mutHealth := sync.Mutex{}
+	health := make(map[string]bool)
+	shouldFail := true
+	handler := &mock.ObserversHealthHandlerStub{
+		GetAllObserversCalled: func() []*data.Observer {
+			return []*data.Observer{
+				{Address: addressFail, ShardId: 0},
+				{Address: "address2", ShardId: 0},
+			}
+		},
+		SetObserverHealthCalled: func(address string, isHealthy bool) {
+			mutHealth.Lock()
+			health[address] = isHealthy
+			mutHealth.Unlock()
+		},
+		CallGetRestEndPointCalled: func(ctx context.Context, address string, path string, value interface{}) error {
+			if address == addressFail && shouldFail {
+				return errors.New("expected error")
+			}
+
+			return nil
+		},
+	}
+	ohc, _ := process.NewObserversHealthChecker(handler, createHealthCheckConfig())
+
+	ohc.CheckObservers()
+	assert.True(t, ohc.IsHealthy(addressFail))
+	assert.Equal(t, 0, len(health))
+
+	ohc.CheckObservers()
+	assert.False(t, ohc.IsHealthy(addressFail))
+	assert.True(t, ohc.IsHealthy("address2"))
+	assert.Equal(t, map[string]bool{addressFail: false}, health)
+
+	shouldFail = false
+	ohc.CheckObservers()
+	assert.False(t, ohc.IsHealthy(addressFail))
+
+	ohc.CheckObservers()
+	assert.True(t, ohc.IsHealthy(addressFail))
+	assert.Equal(t, map[string]bool{addressFail: true}, health)
+}
+
+//------- BaseProcessor integration
+
+func TestBaseProcessor_GetObserversShouldSkipUnhealthy(t *testing.T) {
+	t.Parallel()
+
+	observersList := []*data.Observer{
+		{Address: "address1", ShardId: 0},
+		{Address: "address2", ShardId: 0},
+	}
+	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{}, metrics.NewRegistry())
+	_ = bp.ApplyConfig(&config.Config{
+		Observers: observersList,
+	})
+
+	bp.SetObserverHealth("address1", false)
+	observers, err := bp.GetObservers(0)
+	assert.Nil(t, err)
+	assert.Equal(t, []*data.Observer{observersList[1]}, observers)
+
+	bp.SetObserverHealth("address1", true)
+	observers, _ = bp.GetObservers(0)
+	assert.Equal(t, observersList, observers)
+}
+
+func TestBaseProcessor_GetObserversAllUnhealthyShouldReturnAll(t *testing.T) {
+	t.Parallel()
+
+	observersList := []*data.Observer{
+		{Address: "address1", ShardId: 0},
+		{Address: "address2", ShardId: 0},
+	}
+	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{}, metrics.NewRegistry())
+	_ = bp.ApplyConfig(&config.Config{
+		Observers: observersList,
+	})
+
+	bp.SetObserverHealth("address1", false)
+	bp.SetObserverHealth("address2", false)
+	observers, err := bp.GetObservers(0)
+
+	assert.Nil(t, err)
+	assert.Equal(t, observersList, observers)
+}
@@ -0,0 +1,170 @@
+package process
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/numbatx/numbat-proxy/data"
+)
+
+type readResult struct {
+	response interface{}
+	address  string
+	err      error
+}
+
+type observersReadFunc func(
+	ctx context.Context,
+	proc Processor,
+	observers []*data.Observer,
+	path string,
+	createResponse func() interface{},
+) (interface{}, string, error)
+
+// readFromObservers requests the path from the observers, in order, until one of them answers. An observer
+// answering with a non retryable error stops the failover. When the processor provides a hedging delay and
+// the first observer did not answer within it, the next observer is requested as well, the first answer is
+// used and the other request is canceled. It must only be used for idempotent reads. createResponse creates the
+// value each request decodes its answer into. The latencies of the answered client requests are recorded, as
+// the hedging delay of the shard is computed from them
+func readFromObservers(
+	ctx context.Context,
+	proc Processor,
+	observers []*data.Observer,
+	path string,
+	createResponse func() interface{},
+) (interface{}, string, error) {
+
+	isClientRequest := GetRequestId(ctx) != ""
+	call := func(ctx context.Context, address string, response interface{}) error {
+		start := time.Now()
+		err := proc.CallGetRestEndPoint(ctx, address, path, response)
+		if err == nil && isClientRequest {
+			proc.RecordReadLatency(observers[0].ShardId, time.Since(start))
+		}
+
+		return err
+	}
+
+	return callObservers(ctx, proc, observers, path, call, createResponse)
+}
+
+// lookupFromObservers reads the path like readFromObservers, without recording the latencies. It is used for
+// the lookups sent to several shards, as the shards not holding the looked up data answer faster than a read
+func lookupFromObservers(
+	ctx context.Context,
+	proc Processor,
+	observers []*data.Observer,
+	path string,
+	createResponse func() interface{},
+) (interface{}, string, error) {
+
+	call := func(ctx context.Context, address string, response interface{}) error {
+		return proc.CallGetRestEndPoint(ctx, address, path, response)
+	}
+
+	return callObservers(ctx, proc, observers, path, call, createResponse)
+}
+
+// postToObservers posts the payload to the observers with the same failover and hedging as readFromObservers.
+// It must only be used for the POST requests that do not change the observers' state
+func postToObservers(
+	ctx context.Context,
+	proc Processor,
+	observers []*data.Observer,
+	path string,
+	payload interface{},
+	createResponse func() interface{},
+) (interface{}, string, error) {
+
+	call := func(ctx context.Context, address string, response interface{}) error {
+		return proc.CallPostRestEndPoint(ctx, address, path, payload, response)
+	}
+
+	return callObservers(ctx, proc, observers, path, call, createResponse)
+}
+
+func callObservers(
+	ctx context.Context,
+	proc Processor,
+	observers []*data.Observer,
+	path string,
+	call func(ctx context.Context, address string, response interface{}) error,
+	createResponse func() interface{},
+) (interface{}, string, error) {
+
+	if len(observers) == 0 {
+		return nil, "", ErrMissingObserver
+	}
+
+	ctxRead, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan *readResult, len(observers))
+	nextObserver := 0
+	numInFlight := 0
+	launch := func() {
+		address := observers[nextObserver].Address
+		nextObserver++
+		numInFlight++
+
+		go func() {
+			response := createResponse()
+			err := call(ctxRead, address, response)
+			results <- &readResult{response: response, address: address, err: err}
+		}()
+	}
+
+	launch()
+
+	var chanHedge <-chan time.Time
+	hedgingDelay := proc.GetHedgingDelay(observers[0].ShardId)
+	if hedgingDelay > 0 && len(observers) > 1 {
+		timer := time.NewTimer(hedgingDelay)
+		defer timer.Stop()
+		chanHedge = timer.C
+	}
+
+	var lastErr error
+	for numInFlight > 0 {
+		select {
+		case <-chanHedge:
+			chanHedge = nil
+			if nextObserver < len(observers) {
+				log.Info(fmt.Sprintf("hedging request %s to observer %s", path, observers[nextObserver].Address))
+				launch()
+			}
+		case result := <-results:
+			numInFlight--
+			if result.err == nil {
+				return result.response, result.address, nil
+			}
+
+			log.LogIfError(result.err)
+			lastErr = result.err
+			if ctx.Err() != nil {
+				return nil, "", ctx.Err()
+			}
+			if isMissingRouteError(result.err) {
+				return nil, "", fmt.Errorf("%w: %w", ErrNotSupportedByObservers, result.err)
+			}
+			if !IsRetryableError(result.err) {
+				return nil, "", result.err
+			}
+			if numInFlight == 0 && nextObserver < len(observers) {
+				proc.CountFailover(observers[nextObserver].ShardId)
+				launch()
+			}
+		}
+	}
+
+	return nil, "", fmt.Errorf("%w: %w", ErrSendingRequest, lastErr)
+}
+
+func isMissingRouteError(err error) bool {
+	var observerErr *ObserverError
+
+	return errors.As(err, &observerErr) && observerErr.IsMissingRoute()
+}
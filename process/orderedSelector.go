@@ -0,0 +1,33 @@
+package process
+
+import (
+	"time"
+
+	"github.com/numbatx/numbat-proxy/data"
+)
+
+// OrderedSelector returns the observers in the order they were configured
+type OrderedSelector struct {
+}
+
+// NewOrderedSelector creates a new instance of OrderedSelector
+func NewOrderedSelector() *OrderedSelector {
+	return &OrderedSelector{}
+}
+
+// Select returns the observers unchanged
+func (ors *OrderedSelector) Select(_ uint32, observers []*data.Observer) []*data.Observer {
+	return observers
+}
+
+// CallStarted does nothing
+func (ors *OrderedSelector) CallStarted(_ string) {
+}
+
+// CallFinished does nothing
+func (ors *OrderedSelector) CallFinished(_ string, _ time.Duration, _ error) {
+}
+
+// CallCanceled does nothing
+func (ors *OrderedSelector) CallCanceled(_ string) {
+}
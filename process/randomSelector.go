@@ -0,0 +1,48 @@
+package process
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/numbatx/numbat-proxy/data"
+)
+
+// RandomSelector returns the observers in a random order
+type RandomSelector struct {
+	mutRand sync.Mutex
+	rnd     *rand.Rand
+}
+
+// NewRandomSelector creates a new instance of RandomSelector
+func NewRandomSelector() *RandomSelector {
+	return &RandomSelector{
+		rnd: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Select returns a shuffled copy of the observers
+func (rs *RandomSelector) Select(_ uint32, observers []*data.Observer) []*data.Observer {
+	rs.mutRand.Lock()
+	perm := rs.rnd.Perm(len(observers))
+	rs.mutRand.Unlock()
+
+	selected := make([]*data.Observer, len(observers))
+	for i, j := range perm {
+		selected[i] = observers[j]
+	}
+
+	return selected
+}
+
+// CallStarted does nothing
+func (rs *RandomSelector) CallStarted(_ string) {
+}
+
+// CallFinished does nothing
+func (rs *RandomSelector) CallFinished(_ string, _ time.Duration, _ error) {
+}
+
+// CallCanceled does nothing
+func (rs *RandomSelector) CallCanceled(_ string) {
+}
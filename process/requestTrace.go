@@ -0,0 +1,61 @@
+package process
+
+import (
+	"context"
+	"sync"
+)
+
+// RequestIdHeader is the header carrying the id of the client request an observer call is made for
+const RequestIdHeader = "X-Request-ID"
+
+type requestTraceKey struct{}
+
+// requestTrace follows a client request through the processors: its id is forwarded to the observers and
+// the contacted observers are collected for the access log
+type requestTrace struct {
+	id string
+
+	mutObservers sync.Mutex
+	observers    []string
+}
+
+// NewRequestContext returns a context carrying the request id. The observers called with the returned
+// context, or with one derived from it, are then available through GetContactedObservers
+func NewRequestContext(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestTraceKey{}, &requestTrace{id: requestId})
+}
+
+// GetRequestId returns the request id carried by the context or an empty string if there is none
+func GetRequestId(ctx context.Context) string {
+	trace, ok := ctx.Value(requestTraceKey{}).(*requestTrace)
+	if !ok {
+		return ""
+	}
+
+	return trace.id
+}
+
+// GetContactedObservers returns, in call order, the addresses of the observers called for the request. The
+// passwords the addresses may carry are redacted
+func GetContactedObservers(ctx context.Context) []string {
+	trace, ok := ctx.Value(requestTraceKey{}).(*requestTrace)
+	if !ok {
+		return nil
+	}
+
+	trace.mutObservers.Lock()
+	defer trace.mutObservers.Unlock()
+
+	return append([]string(nil), trace.observers...)
+}
+
+func addContactedObserver(ctx context.Context, address string) {
+	trace, ok := ctx.Value(requestTraceKey{}).(*requestTrace)
+	if !ok {
+		return
+	}
+
+	trace.mutObservers.Lock()
+	trace.observers = append(trace.observers, redactAddress(address))
+	trace.mutObservers.Unlock()
+}
@@ -0,0 +1,96 @@
+package process
+
+import (
+	"context"
+	"sync"
+)
+
+type inFlightRequest struct {
+	done       chan struct{}
+	body       []byte
+	err        error
+	numWaiters int
+	cancel     context.CancelFunc
+}
+
+// requestsCoalescer merges the identical requests that are in flight at the same time into a single
+// upstream request whose response is handed to all the waiters. The upstream request does not depend on
+// the context of any waiter and is canceled only when all of them gave up
+type requestsCoalescer struct {
+	mutRequests sync.Mutex
+	requests    map[string]*inFlightRequest
+}
+
+func newRequestsCoalescer() *requestsCoalescer {
+	return &requestsCoalescer{
+		requests: make(map[string]*inFlightRequest),
+	}
+}
+
+// do returns the response of the in-flight request with the same key or launches fetch if there is none
+func (rc *requestsCoalescer) do(
+	ctx context.Context,
+	key string,
+	fetch func(ctx context.Context) ([]byte, error),
+) ([]byte, error) {
+
+	rc.mutRequests.Lock()
+	request, ok := rc.requests[key]
+	if !ok {
+		request = rc.launch(key, fetch)
+	}
+	request.numWaiters++
+	rc.mutRequests.Unlock()
+
+	select {
+	case <-request.done:
+		return request.body, request.err
+	case <-ctx.Done():
+		rc.leave(key, request)
+		return nil, ctx.Err()
+	}
+}
+
+// launch should be called under mutRequests
+func (rc *requestsCoalescer) launch(key string, fetch func(ctx context.Context) ([]byte, error)) *inFlightRequest {
+	ctx, cancel := context.WithCancel(context.Background())
+	request := &inFlightRequest{
+		done:   make(chan struct{}),
+		cancel: cancel,
+	}
+	rc.requests[key] = request
+
+	go func() {
+		request.body, request.err = fetch(ctx)
+		cancel()
+
+		rc.mutRequests.Lock()
+		rc.forget(key, request)
+		rc.mutRequests.Unlock()
+
+		close(request.done)
+	}()
+
+	return request
+}
+
+func (rc *requestsCoalescer) leave(key string, request *inFlightRequest) {
+	rc.mutRequests.Lock()
+	defer rc.mutRequests.Unlock()
+
+	request.numWaiters--
+	if request.numWaiters > 0 {
+		return
+	}
+
+	// nobody waits for the response anymore, so the next identical request will start a new upstream request
+	request.cancel()
+	rc.forget(key, request)
+}
+
+// forget should be called under mutRequests
+func (rc *requestsCoalescer) forget(key string, request *inFlightRequest) {
+	if rc.requests[key] == request {
+		delete(rc.requests, key)
+	}
+}
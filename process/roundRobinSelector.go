@@ -0,0 +1,51 @@
+package process
+
+import (
+	"sync"
+	"time"
+
+	"github.com/numbatx/numbat-proxy/data"
+)
+
+// RoundRobinSelector rotates the observers of each shard so that every request starts with the next observer
+type RoundRobinSelector struct {
+	mutCounters sync.Mutex
+	counters    map[uint32]int
+}
+
+// NewRoundRobinSelector creates a new instance of RoundRobinSelector
+func NewRoundRobinSelector() *RoundRobinSelector {
+	return &RoundRobinSelector{
+		counters: make(map[uint32]int),
+	}
+}
+
+// Select returns the observers rotated by one position relative to the previous call for the same shard
+func (rrs *RoundRobinSelector) Select(shardId uint32, observers []*data.Observer) []*data.Observer {
+	if len(observers) == 0 {
+		return observers
+	}
+
+	rrs.mutCounters.Lock()
+	start := rrs.counters[shardId] % len(observers)
+	rrs.counters[shardId] = start + 1
+	rrs.mutCounters.Unlock()
+
+	selected := make([]*data.Observer, 0, len(observers))
+	selected = append(selected, observers[start:]...)
+	selected = append(selected, observers[:start]...)
+
+	return selected
+}
+
+// CallStarted does nothing
+func (rrs *RoundRobinSelector) CallStarted(_ string) {
+}
+
+// CallFinished does nothing
+func (rrs *RoundRobinSelector) CallFinished(_ string, _ time.Duration, _ error) {
+}
+
+// CallCanceled does nothing
+func (rrs *RoundRobinSelector) CallCanceled(_ string) {
+}
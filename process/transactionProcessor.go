@@ -1,9 +1,13 @@
 package process
 
 import (
+	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
-	"math/big"
+	"net/http"
+	"sync"
+	"time"
 
 	"github.com/numbatx/numbat-proxy/data"
 )
@@ -11,62 +15,587 @@ import (
 // TransactionPath defines the address path at which the nodes answer
 const TransactionPath = "/transaction/send"
 
+// TransactionSimulatePath defines the address path at which the nodes execute a transaction without broadcasting it
+const TransactionSimulatePath = "/transaction/simulate"
+
+// TransactionDetailsPath defines the path, followed by the transaction hash, at which the nodes answer
+// transaction lookups
+const TransactionDetailsPath = "/transaction/"
+
+// MaxTransactionFinalityWait defines the maximum time a finality wait request is held
+const MaxTransactionFinalityWait = 60 * time.Second
+
+// transactionFinalityPollInterval defines how often the shards are queried while waiting for finality
+const transactionFinalityPollInterval = 500 * time.Millisecond
+
 // TransactionProcessor is able to process transaction requests
 type TransactionProcessor struct {
-	proc Processor
+	proc         Processor
+	codec        AddressCodec
+	validator    TransactionValidationHandler
+	nonceChecker NonceCheckHandler
+
+	chanClose chan struct{}
+	closeOnce sync.Once
 }
 
-// NewTransactionProcessor creates a new instance of TransactionProcessor
-func NewTransactionProcessor(proc Processor) (*TransactionProcessor, error) {
+// NewTransactionProcessor creates a new instance of TransactionProcessor. The transactions are relayed only if
+// they pass the validator's checks and, when the invalid nonces are rejected, the nonce checker's
+func NewTransactionProcessor(
+	proc Processor,
+	codec AddressCodec,
+	validator TransactionValidationHandler,
+	nonceChecker NonceCheckHandler,
+) (*TransactionProcessor, error) {
+
 	if proc == nil {
 		return nil, ErrNilCoreProcessor
 	}
+	if codec == nil {
+		return nil, ErrNilAddressCodec
+	}
+	if validator == nil {
+		return nil, ErrNilTransactionValidator
+	}
+	if nonceChecker == nil {
+		return nil, ErrNilNonceChecker
+	}
 
 	return &TransactionProcessor{
-		proc: proc,
+		proc:         proc,
+		codec:        codec,
+		validator:    validator,
+		nonceChecker: nonceChecker,
+		chanClose:    make(chan struct{}),
 	}, nil
 }
 
-// SendTransaction relay the post request by sending the request to the right observer and replies back the answer
-func (ap *TransactionProcessor) SendTransaction(nonce uint64, sender string, receiver string, value *big.Int, code string, signature []byte) (string, error) {
-	senderBuff, err := hex.DecodeString(sender)
+// SendTransaction relay the post request by sending the request to the right observer and replies back the answer.
+// Apart from the sender and receiver, converted to hex, the transaction is forwarded unchanged so all the fields
+// covered by the signature reach the observer. The result holds the outcome of the nonce check, if any
+func (ap *TransactionProcessor) SendTransaction(ctx context.Context, tx *data.Transaction) (*data.TransactionSendResult, error) {
+	observerTx, shardId, err := ap.prepareTransaction(tx)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	shardId, err := ap.proc.ComputeShardId(senderBuff)
+	nonceCheck, err := ap.nonceChecker.CheckNonce(ctx, observerTx)
+	if err != nil {
+		return nil, err
+	}
+
+	observers, err := ap.proc.GetObservers(shardId)
+	if err != nil {
+		return nil, err
+	}
+
+	txHash, err := ap.sendToObservers(ctx, shardId, observers, observerTx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &data.TransactionSendResult{TxHash: txHash, NonceCheck: nonceCheck}, nil
+}
+
+// SimulateTransaction executes the transaction on an observer of the sender's shard, without broadcasting it.
+// The transaction goes through the same checks as a sent one, apart from the nonce check. As the simulation
+// does not change the observers' state, the request is hedged and retried across the observers like a read.
+// If the observers do not serve the simulation, ErrSimulationNotSupported is returned
+func (ap *TransactionProcessor) SimulateTransaction(
+	ctx context.Context,
+	tx *data.Transaction,
+) (*data.TransactionSimulationResult, error) {
+
+	observerTx, shardId, err := ap.prepareTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	observers, err := ap.proc.GetObservers(shardId)
+	if err != nil {
+		return nil, err
+	}
+
+	response, observerAddress, err := postToObservers(ctx, ap.proc, observers, TransactionSimulatePath, observerTx, func() interface{} {
+		return &data.ResponseTransactionSimulation{}
+	})
+	if errors.Is(err, ErrNotSupportedByObservers) {
+		return nil, ErrSimulationNotSupported
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info(fmt.Sprintf("Transaction simulated on observer %v from shard %v, request id %s",
+		observerAddress,
+		shardId,
+		GetRequestId(ctx),
+	))
+
+	return &response.(*data.ResponseTransactionSimulation).Simulation, nil
+}
+
+// SendMultipleTransactions groups the transactions by their sender's shard and sends the groups in parallel.
+// The returned results are in the same order as the provided transactions
+func (ap *TransactionProcessor) SendMultipleTransactions(
+	ctx context.Context,
+	txs []*data.Transaction,
+) ([]*data.TransactionSendResult, error) {
+
+	if len(txs) == 0 {
+		return nil, ErrEmptyTransactionsList
+	}
+
+	results := make([]*data.TransactionSendResult, len(txs))
+	observerTxs := make([]*data.Transaction, len(txs))
+	txIndexesByShard := make(map[uint32][]int)
+	for i, tx := range txs {
+		observerTx, shardId, err := ap.prepareTransaction(tx)
+		if err != nil {
+			results[i] = newRejectedTransactionResult(err)
+			continue
+		}
+
+		observerTxs[i] = observerTx
+		txIndexesByShard[shardId] = append(txIndexesByShard[shardId], i)
+	}
+
+	wg := &sync.WaitGroup{}
+	wg.Add(len(txIndexesByShard))
+	for shardId, txIndexes := range txIndexesByShard {
+		go func(shardId uint32, txIndexes []int) {
+			ap.sendShardTransactions(ctx, shardId, observerTxs, txIndexes, results)
+			wg.Done()
+		}(shardId, txIndexes)
+	}
+	wg.Wait()
+
+	return results, nil
+}
+
+func newRejectedTransactionResult(err error) *data.TransactionSendResult {
+	result := &data.TransactionSendResult{Error: err.Error()}
+
+	var fieldErr *TransactionFieldError
+	if errors.As(err, &fieldErr) {
+		result.Field = fieldErr.Field
+	}
+
+	var nonceErr *NonceError
+	if errors.As(err, &nonceErr) {
+		result.NonceCheck = nonceErr.Check
+	}
+
+	return result
+}
+
+// sendShardTransactions sends, one after the other, the transactions of a shard so that transactions
+// from the same sender reach the observer in the order their nonces were provided
+func (ap *TransactionProcessor) sendShardTransactions(
+	ctx context.Context,
+	shardId uint32,
+	txs []*data.Transaction,
+	txIndexes []int,
+	results []*data.TransactionSendResult,
+) {
+
+	observers, err := ap.proc.GetObservers(shardId)
+	for _, idx := range txIndexes {
+		if err != nil {
+			results[idx] = &data.TransactionSendResult{Error: err.Error()}
+			continue
+		}
+
+		nonceCheck, errCheck := ap.nonceChecker.CheckNonce(ctx, txs[idx])
+		if errCheck != nil {
+			results[idx] = newRejectedTransactionResult(errCheck)
+			continue
+		}
+
+		txHash, errSend := ap.sendToObservers(ctx, shardId, observers, txs[idx])
+		if errSend != nil {
+			results[idx] = &data.TransactionSendResult{Error: errSend.Error(), NonceCheck: nonceCheck}
+			continue
+		}
+
+		results[idx] = &data.TransactionSendResult{TxHash: txHash, NonceCheck: nonceCheck}
+	}
+}
+
+// GetTransaction returns the transaction with the provided hash. The sender and receiver are optional
+// hints restricting the lookup to their shards. Without hints, all the shards are queried concurrently
+func (ap *TransactionProcessor) GetTransaction(
+	ctx context.Context,
+	txHash string,
+	sender string,
+	receiver string,
+) (*data.TransactionDetails, error) {
+
+	path := TransactionDetailsPath + txHash
+	response, err := ap.queryShards(ctx, txHash, sender, receiver, path, func() interface{} {
+		return &data.ResponseTransactionDetails{}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tx := &response.(*data.ResponseTransactionDetails).Transaction
+	tx.Sender = fromObserverAddress(ap.codec, tx.Sender)
+	tx.Receiver = fromObserverAddress(ap.codec, tx.Receiver)
+
+	return tx, nil
+}
+
+// GetTransactionStatus returns the status of the transaction with the provided hash, pending, executed or
+// failed, as in the cross-shard status. The sender and receiver are optional hints restricting the lookup to
+// their shards. The status is derived from the observers' transaction lookup: the observers that do not
+// report the execution outcome only return the transactions they executed, so a transaction still waiting
+// in their pool is ErrTransactionNotFound here, while the cross-shard status reports it as pending
+func (ap *TransactionProcessor) GetTransactionStatus(
+	ctx context.Context,
+	txHash string,
+	sender string,
+	receiver string,
+) (string, error) {
+
+	tx, err := ap.GetTransaction(ctx, txHash, sender, receiver)
 	if err != nil {
 		return "", err
 	}
 
+	return shardTransactionStatus(tx), nil
+}
+
+func (ap *TransactionProcessor) queryShards(
+	ctx context.Context,
+	txHash string,
+	sender string,
+	receiver string,
+	path string,
+	createResponse func() interface{},
+) (interface{}, error) {
+
+	_, err := hex.DecodeString(txHash)
+	if err != nil || len(txHash) == 0 {
+		return nil, ErrInvalidTransactionHash
+	}
+
+	shardIds, err := ap.computeHintedShardIds(sender, receiver)
+	if err != nil {
+		return nil, err
+	}
+
+	return ap.getFromFirstShard(ctx, shardIds, path, createResponse)
+}
+
+// computeHintedShardIds returns the shards of the sender and receiver or, if none of them is
+// provided, all the shards
+func (ap *TransactionProcessor) computeHintedShardIds(sender string, receiver string) ([]uint32, error) {
+	shardIds := make([]uint32, 0, 2)
+	for _, address := range []string{sender, receiver} {
+		if len(address) == 0 {
+			continue
+		}
+
+		shardId, err := ap.computeShardId(address)
+		if err != nil {
+			return nil, err
+		}
+		if len(shardIds) == 0 || shardIds[0] != shardId {
+			shardIds = append(shardIds, shardId)
+		}
+	}
+
+	if len(shardIds) == 0 {
+		return ap.proc.GetShardIds(), nil
+	}
+
+	return shardIds, nil
+}
+
+// getFromFirstShard queries the shards concurrently and returns the first successful response. A shard
+// answering "not found" is not a definitive answer, so the other shards are still waited for
+func (ap *TransactionProcessor) getFromFirstShard(
+	ctx context.Context,
+	shardIds []uint32,
+	path string,
+	createResponse func() interface{},
+) (interface{}, error) {
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type shardResult struct {
+		response interface{}
+		err      error
+	}
+	chanResults := make(chan shardResult, len(shardIds))
+	for _, shardId := range shardIds {
+		go func(shardId uint32) {
+			response, err := ap.getFromShard(ctx, shardId, path, lookupFromObservers, createResponse)
+			chanResults <- shardResult{response: response, err: err}
+		}(shardId)
+	}
+
+	var lastErr error = ErrTransactionNotFound
+	for range shardIds {
+		result := <-chanResults
+		if result.err == nil {
+			return result.response, nil
+		}
+		if !isNotFoundError(result.err) {
+			lastErr = result.err
+		}
+	}
+
+	return nil, lastErr
+}
+
+func (ap *TransactionProcessor) getFromShard(
+	ctx context.Context,
+	shardId uint32,
+	path string,
+	read observersReadFunc,
+	createResponse func() interface{},
+) (interface{}, error) {
+
 	observers, err := ap.proc.GetObservers(shardId)
+	if err != nil {
+		return nil, err
+	}
+
+	response, _, err := read(ctx, ap.proc, observers, path, createResponse)
+
+	return response, err
+}
+
+// GetCrossShardTransactionStatus queries the sender's and the receiver's shards and combines the
+// transaction's status on both of them
+func (ap *TransactionProcessor) GetCrossShardTransactionStatus(
+	ctx context.Context,
+	txHash string,
+	sender string,
+	receiver string,
+) (*data.CrossShardTransactionStatus, error) {
+
+	_, err := hex.DecodeString(txHash)
+	if err != nil || len(txHash) == 0 {
+		return nil, ErrInvalidTransactionHash
+	}
+	if len(sender) == 0 || len(receiver) == 0 {
+		return nil, ErrMissingSenderOrReceiver
+	}
+
+	sourceShardId, err := ap.computeShardId(sender)
+	if err != nil {
+		return nil, err
+	}
+	destinationShardId, err := ap.computeShardId(receiver)
+	if err != nil {
+		return nil, err
+	}
+
+	path := TransactionDetailsPath + txHash
+	sourceStatus, err := ap.getShardTransactionStatus(ctx, sourceShardId, path)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &data.CrossShardTransactionStatus{
+		SourceShard:       sourceShardId,
+		SourceStatus:      sourceStatus,
+		DestinationShard:  destinationShardId,
+		DestinationStatus: data.TxStatusPending,
+	}
+	switch {
+	case sourceShardId == destinationShardId:
+		status.DestinationStatus = sourceStatus
+	case sourceStatus == data.TxStatusExecuted:
+		// the destination shard is queried only after the source shard executed the transaction
+		status.DestinationStatus, err = ap.getShardTransactionStatus(ctx, destinationShardId, path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	status.Status = combineTransactionStatuses(status.SourceStatus, status.DestinationStatus)
+
+	return status, nil
+}
+
+// WaitForTransactionFinality polls the sender's and receiver's shards until the transaction is final on
+// both of them, failed, the provided wait time elapses or the processor is closed. In the latter cases, the
+// last known status is returned
+func (ap *TransactionProcessor) WaitForTransactionFinality(
+	ctx context.Context,
+	txHash string,
+	sender string,
+	receiver string,
+	maxWait time.Duration,
+) (*data.CrossShardTransactionStatus, error) {
+
+	if maxWait <= 0 || maxWait > MaxTransactionFinalityWait {
+		maxWait = MaxTransactionFinalityWait
+	}
+
+	ctxWait, cancel := context.WithTimeout(ctx, maxWait)
+	defer cancel()
+
+	var lastStatus *data.CrossShardTransactionStatus
+	for {
+		status, err := ap.GetCrossShardTransactionStatus(ctxWait, txHash, sender, receiver)
+		if err != nil {
+			if ctx.Err() == nil && ctxWait.Err() != nil && lastStatus != nil {
+				return lastStatus, nil
+			}
+			return nil, err
+		}
+		if status.IsFinal() {
+			return status, nil
+		}
+		lastStatus = status
+
+		select {
+		case <-ctxWait.Done():
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return lastStatus, nil
+		case <-ap.chanClose:
+			return lastStatus, nil
+		case <-time.After(transactionFinalityPollInterval):
+		}
+	}
+}
+
+// Close ends the ongoing and the future finality waits, so the requests holding them finish while the
+// in-flight requests are drained on shutdown
+func (ap *TransactionProcessor) Close() {
+	ap.closeOnce.Do(func() {
+		close(ap.chanClose)
+	})
+}
+
+// getShardTransactionStatus returns the status of a transaction on a shard, derived from the shard's transaction
+// lookup. A shard that does not know the transaction yet reports it as pending, while a shard that does not
+// serve the lookup is an error
+func (ap *TransactionProcessor) getShardTransactionStatus(ctx context.Context, shardId uint32, path string) (string, error) {
+	response, err := ap.getFromShard(ctx, shardId, path, readFromObservers, func() interface{} {
+		return &data.ResponseTransactionDetails{}
+	})
+	if isNotFoundError(err) {
+		return data.TxStatusPending, nil
+	}
 	if err != nil {
 		return "", err
 	}
 
-	for _, observer := range observers {
-		tx := &data.Transaction{
-			Nonce:     nonce,
-			Sender:    sender,
-			Receiver:  receiver,
-			Value:     value,
-			Data:      code,
-			Signature: hex.EncodeToString(signature),
+	return shardTransactionStatus(&response.(*data.ResponseTransactionDetails).Transaction), nil
+}
+
+// shardTransactionStatus converts the execution outcome reported by an observer. The observers that do not
+// report it only return the transactions they executed
+func shardTransactionStatus(tx *data.TransactionDetails) string {
+	switch tx.Status {
+	case data.ObserverTxStatusPending:
+		return data.TxStatusPending
+	case data.ObserverTxStatusFail, data.ObserverTxStatusInvalid:
+		return data.TxStatusFailed
+	default:
+		return data.TxStatusExecuted
+	}
+}
+
+func combineTransactionStatuses(sourceStatus string, destinationStatus string) string {
+	switch {
+	case sourceStatus == data.TxStatusFailed || destinationStatus == data.TxStatusFailed:
+		return data.TxStatusFailed
+	case sourceStatus != data.TxStatusExecuted:
+		return data.TxStatusPending
+	case destinationStatus != data.TxStatusExecuted:
+		return data.TxStatusExecutedOnSource
+	default:
+		return data.TxStatusFinal
+	}
+}
+
+func isNotFoundError(err error) bool {
+	var observerErr *ObserverError
+
+	return errors.As(err, &observerErr) && observerErr.StatusCode == http.StatusNotFound && !observerErr.IsMissingRoute()
+}
+
+func (ap *TransactionProcessor) computeShardId(address string) (uint32, error) {
+	addressBuff, err := ap.codec.DecodeAddress(address)
+	if err != nil {
+		return 0, err
+	}
+
+	return ap.proc.ComputeShardId(addressBuff)
+}
+
+// prepareTransaction validates the transaction and returns a copy of it with the sender and receiver in the
+// hex format expected by the observers, along with the sender's shard
+func (ap *TransactionProcessor) prepareTransaction(tx *data.Transaction) (*data.Transaction, uint32, error) {
+	err := ap.validator.ValidateTransaction(tx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	observerTx := *tx
+	observerTx.Sender, err = toObserverAddress(ap.codec, tx.Sender)
+	if err != nil {
+		return nil, 0, fmt.Errorf("sender: %w", err)
+	}
+	if len(tx.Receiver) > 0 {
+		observerTx.Receiver, err = toObserverAddress(ap.codec, tx.Receiver)
+		if err != nil {
+			return nil, 0, fmt.Errorf("receiver: %w", err)
+		}
+	}
+
+	shardId, err := ap.computeShardId(observerTx.Sender)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return &observerTx, shardId, nil
+}
+
+func (ap *TransactionProcessor) sendToObservers(
+	ctx context.Context,
+	shardId uint32,
+	observers []*data.Observer,
+	tx *data.Transaction,
+) (string, error) {
+
+	var err error
+	for i, observer := range observers {
+		if i > 0 {
+			ap.proc.CountFailover(shardId)
 		}
+
 		txResponse := &data.ResponseTransaction{}
 
-		err = ap.proc.CallPostRestEndPoint(observer.Address, TransactionPath, tx, txResponse)
+		err = ap.proc.CallPostRestEndPoint(ctx, observer.Address, TransactionPath, tx, txResponse)
 		if err == nil {
-			log.Info(fmt.Sprintf("Transaction sent successfully to observer %v from shard %v, received tx hash %s",
+			log.Info(fmt.Sprintf("Transaction sent successfully to observer %v from shard %v, received tx hash %s, request id %s",
 				observer.Address,
 				shardId,
 				txResponse.TxHash,
+				GetRequestId(ctx),
 			))
 			return txResponse.TxHash, nil
 		}
 
 		log.LogIfError(err)
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		if !IsRetryableError(err) {
+			return "", err
+		}
 	}
 
-	return "", ErrSendingRequest
+	return "", fmt.Errorf("%w: %w", ErrSendingRequest, err)
 }
@@ -1,20 +1,33 @@
 package process_test
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/numbatx/numbat-proxy/data"
+	"github.com/numbatx/numbat-proxy/metrics"
 	"github.com/numbatx/numbat-proxy/process"
 	"github.com/numbatx/numbat-proxy/process/mock"
 	"github.com/stretchr/testify/assert"
 )
 
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
 func TestNewTransaction_NilCoreProcessorShouldErr(t *testing.T) {
 	t.Parallel()
 
-	tp, err := process.NewTransactionProcessor(nil)
+	tp, err := process.NewTransactionProcessor(nil, createHexAddressCodec(), &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{})
 
 	assert.Nil(t, tp)
 	assert.Equal(t, process.ErrNilCoreProcessor, err)
@@ -23,7 +36,7 @@ func TestNewTransaction_NilCoreProcessorShouldErr(t *testing.T) {
 func TestNewTransactionProcessor_WithCoreProcessorShouldWork(t *testing.T) {
 	t.Parallel()
 
-	tp, err := process.NewTransactionProcessor(&mock.ProcessorStub{})
+	tp, err := process.NewTransactionProcessor(&mock.ProcessorStub{}, createHexAddressCodec(), &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{})
 
 	assert.NotNil(t, tp)
 	assert.Nil(t, err)
@@ -34,11 +47,14 @@ func TestNewTransactionProcessor_WithCoreProcessorShouldWork(t *testing.T) {
 func TestNewTransactionProcessor_SendTransactionInvalidHexAdressShouldErr(t *testing.T) {
 	t.Parallel()
 
-	tp, _ := process.NewTransactionProcessor(&mock.ProcessorStub{})
-	sig := make([]byte, 0)
-	txHash, err := tp.SendTransaction(0, "invalid hex number", "FF", big.NewInt(0), "", sig)
+	tp, _ := process.NewTransactionProcessor(&mock.ProcessorStub{}, createHexAddressCodec(), &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{})
+	result, err := tp.SendTransaction(context.Background(), &data.Transaction{
+		Sender:   "invalid hex number",
+		Receiver: "FF",
+		Value:    big.NewInt(0),
+	})
 
-	assert.Empty(t, txHash)
+	assert.Nil(t, result)
 	assert.NotNil(t, err)
 	assert.Contains(t, err.Error(), "invalid byte")
 }
@@ -51,12 +67,15 @@ func TestNewTransactionProcessor_SendTransactionComputeShardIdFailsShouldErr(t *
 		ComputeShardIdCalled: func(addressBuff []byte) (u uint32, e error) {
 			return 0, errExpected
 		},
-	})
+	}, createHexAddressCodec(), &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{})
 	address := "DEADBEEF"
-	sig := make([]byte, 0)
-	txHash, err := tp.SendTransaction(0, address, address, big.NewInt(0), "", sig)
+	result, err := tp.SendTransaction(context.Background(), &data.Transaction{
+		Sender:   address,
+		Receiver: address,
+		Value:    big.NewInt(0),
+	})
 
-	assert.Empty(t, txHash)
+	assert.Nil(t, result)
 	assert.Equal(t, errExpected, err)
 }
 
@@ -71,12 +90,15 @@ func TestNewTransactionProcessor_SendTransactionGetObserversFailsShouldErr(t *te
 		GetObserversCalled: func(shardId uint32) (observers []*data.Observer, e error) {
 			return nil, errExpected
 		},
-	})
+	}, createHexAddressCodec(), &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{})
 	address := "DEADBEEF"
-	sig := make([]byte, 0)
-	txHash, err := tp.SendTransaction(0, address, address, big.NewInt(0), "", sig)
+	result, err := tp.SendTransaction(context.Background(), &data.Transaction{
+		Sender:   address,
+		Receiver: address,
+		Value:    big.NewInt(0),
+	})
 
-	assert.Empty(t, txHash)
+	assert.Nil(t, result)
 	assert.Equal(t, errExpected, err)
 }
 
@@ -94,16 +116,55 @@ func TestNewTransactionProcessor_SendTransactionSendingFailsOnAllObserversShould
 				{Address: "adress2", ShardId: 0},
 			}, nil
 		},
-		CallGetRestEndPointCalled: func(address string, path string, value interface{}) error {
+		CallGetRestEndPointCalled: func(ctx context.Context, address string, path string, value interface{}) error {
 			return errExpected
 		},
+	}, createHexAddressCodec(), &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{})
+	address := "DEADBEEF"
+	result, err := tp.SendTransaction(context.Background(), &data.Transaction{
+		Sender:   address,
+		Receiver: address,
+		Value:    big.NewInt(0),
 	})
+
+	assert.Nil(t, result)
+	assert.True(t, errors.Is(err, process.ErrSendingRequest))
+}
+
+func TestNewTransactionProcessor_SendTransactionNotRetryableErrorShouldNotTryOtherObservers(t *testing.T) {
+	t.Parallel()
+
+	errExpected := &process.ObserverError{
+		Address:    "address1",
+		StatusCode: http.StatusBadRequest,
+		Message:    "invalid transaction",
+	}
+	numCalls := 0
+	tp, _ := process.NewTransactionProcessor(&mock.ProcessorStub{
+		ComputeShardIdCalled: func(addressBuff []byte) (u uint32, e error) {
+			return 0, nil
+		},
+		GetObserversCalled: func(shardId uint32) (observers []*data.Observer, e error) {
+			return []*data.Observer{
+				{Address: "address1", ShardId: 0},
+				{Address: "address2", ShardId: 0},
+			}, nil
+		},
+		CallPostRestEndPointCalled: func(ctx context.Context, address string, path string, value interface{}, response interface{}) error {
+			numCalls++
+			return errExpected
+		},
+	}, createHexAddressCodec(), &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{})
 	address := "DEADBEEF"
-	sig := make([]byte, 0)
-	txHash, err := tp.SendTransaction(0, address, address, big.NewInt(0), "", sig)
+	result, err := tp.SendTransaction(context.Background(), &data.Transaction{
+		Sender:   address,
+		Receiver: address,
+		Value:    big.NewInt(0),
+	})
 
-	assert.Empty(t, txHash)
-	assert.Equal(t, process.ErrSendingRequest, err)
+	assert.Nil(t, result)
+	assert.Equal(t, errExpected, err)
+	assert.Equal(t, 1, numCalls)
 }
 
 func TestNewTransactionProcessor_SendTransactionSendingFailsOnFirstObserverShouldStillSend(t *testing.T) {
@@ -121,16 +182,840 @@ func TestNewTransactionProcessor_SendTransactionSendingFailsOnFirstObserverShoul
 				{Address: "adress2", ShardId: 0},
 			}, nil
 		},
-		CallPostRestEndPointCalled: func(address string, path string, value interface{}, response interface{}) error {
+		CallPostRestEndPointCalled: func(ctx context.Context, address string, path string, value interface{}, response interface{}) error {
 			txResponse := response.(*data.ResponseTransaction)
 			txResponse.TxHash = txHash
 			return nil
 		},
-	})
+	}, createHexAddressCodec(), &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{})
 	address := "DEADBEEF"
-	sig := make([]byte, 0)
-	resultedTxHash, err := tp.SendTransaction(0, address, address, big.NewInt(0), "", sig)
+	result, err := tp.SendTransaction(context.Background(), &data.Transaction{
+		Sender:   address,
+		Receiver: address,
+		Value:    big.NewInt(0),
+	})
+
+	assert.Equal(t, txHash, result.TxHash)
+	assert.Nil(t, err)
+}
+
+func TestNewTransactionProcessor_SendTransactionShouldForwardAllFields(t *testing.T) {
+	t.Parallel()
+
+	tx := &data.Transaction{
+		Nonce:     37,
+		Value:     big.NewInt(10),
+		Receiver:  "DEADBEEF",
+		Sender:    "DEADBEEF",
+		GasPrice:  big.NewInt(100),
+		GasLimit:  big.NewInt(1000),
+		Data:      "data",
+		Signature: "aabbccdd",
+		Challenge: "challenge",
+	}
+	var sentTx *data.Transaction
+	tp, _ := process.NewTransactionProcessor(&mock.ProcessorStub{
+		ComputeShardIdCalled: func(addressBuff []byte) (u uint32, e error) {
+			return 0, nil
+		},
+		GetObserversCalled: func(shardId uint32) (observers []*data.Observer, e error) {
+			return []*data.Observer{
+				{Address: "address1", ShardId: 0},
+			}, nil
+		},
+		CallPostRestEndPointCalled: func(ctx context.Context, address string, path string, value interface{}, response interface{}) error {
+			sentTx = value.(*data.Transaction)
+			return nil
+		},
+	}, createHexAddressCodec(), &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{})
+	_, err := tp.SendTransaction(context.Background(), tx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, tx, sentTx)
+}
+
+func TestNewTransactionProcessor_SendTransactionBech32AddressesShouldForwardHex(t *testing.T) {
+	t.Parallel()
+
+	codec := createBech32AddressCodec()
+	tx := &data.Transaction{
+		Receiver:  codec.EncodeAddress([]byte{0xbe, 0xef}),
+		Sender:    codec.EncodeAddress([]byte{0xde, 0xad}),
+		Value:     big.NewInt(10),
+		Signature: "aabbccdd",
+	}
+	var sentTx *data.Transaction
+	tp, _ := process.NewTransactionProcessor(&mock.ProcessorStub{
+		ComputeShardIdCalled: func(addressBuff []byte) (u uint32, e error) {
+			return 0, nil
+		},
+		GetObserversCalled: func(shardId uint32) (observers []*data.Observer, e error) {
+			return []*data.Observer{
+				{Address: "address1", ShardId: 0},
+			}, nil
+		},
+		CallPostRestEndPointCalled: func(ctx context.Context, address string, path string, value interface{}, response interface{}) error {
+			sentTx = value.(*data.Transaction)
+			return nil
+		},
+	}, codec, &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{})
+	_, err := tp.SendTransaction(context.Background(), tx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "dead", sentTx.Sender)
+	assert.Equal(t, "beef", sentTx.Receiver)
+	assert.Equal(t, tx.Signature, sentTx.Signature)
+	assert.Equal(t, codec.EncodeAddress([]byte{0xde, 0xad}), tx.Sender)
+}
+
+func TestNewTransactionProcessor_SendTransactionWrongChecksumShouldErr(t *testing.T) {
+	t.Parallel()
+
+	codec := createBech32AddressCodec()
+	receiver := codec.EncodeAddress([]byte{0xbe, 0xef})
+	tp, _ := process.NewTransactionProcessor(&mock.ProcessorStub{}, codec, &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{})
+	_, err := tp.SendTransaction(context.Background(), &data.Transaction{
+		Sender:   "dead",
+		Receiver: receiver[:len(receiver)-1] + "x",
+	})
+
+	assert.True(t, errors.Is(err, process.ErrInvalidAddressChecksum))
+	assert.Contains(t, err.Error(), "receiver")
+}
+
+func TestNewTransactionProcessor_SendTransactionInvalidTransactionShouldNotRelay(t *testing.T) {
+	t.Parallel()
+
+	errInvalid := &process.TransactionFieldError{Field: "value", Err: errors.New("negative value")}
+	tp, _ := process.NewTransactionProcessor(&mock.ProcessorStub{
+		CallPostRestEndPointCalled: func(ctx context.Context, address string, path string, value interface{}, response interface{}) error {
+			assert.Fail(t, "invalid transactions should not be relayed")
+			return nil
+		},
+	}, createHexAddressCodec(), &mock.TransactionValidatorStub{
+		ValidateTransactionCalled: func(tx *data.Transaction) error {
+			return errInvalid
+		},
+	}, &mock.NonceCheckerStub{})
+	_, err := tp.SendTransaction(context.Background(), &data.Transaction{Sender: "aa", Receiver: "bb"})
+
+	assert.Equal(t, errInvalid, err)
+}
+
+func TestNewTransactionProcessor_SendTransactionShouldReturnTheNonceCheck(t *testing.T) {
+	t.Parallel()
+
+	nonceCheck := &data.NonceCheck{Status: data.NonceStatusTooHigh, AccountNonce: 1, TransactionNonce: 1000}
+	tp, _ := process.NewTransactionProcessor(&mock.ProcessorStub{
+		ComputeShardIdCalled: func(addressBuff []byte) (u uint32, e error) {
+			return 0, nil
+		},
+		GetObserversCalled: func(shardId uint32) (observers []*data.Observer, e error) {
+			return []*data.Observer{{Address: "address0", ShardId: 0}}, nil
+		},
+		CallPostRestEndPointCalled: func(ctx context.Context, address string, path string, value interface{}, response interface{}) error {
+			response.(*data.ResponseTransaction).TxHash = "hash"
+			return nil
+		},
+	}, createHexAddressCodec(), &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{
+		CheckNonceCalled: func(ctx context.Context, tx *data.Transaction) (*data.NonceCheck, error) {
+			assert.Equal(t, "aa", tx.Sender)
+			return nonceCheck, nil
+		},
+	})
+	result, err := tp.SendTransaction(context.Background(), &data.Transaction{Nonce: 1000, Sender: "aa", Receiver: "bb"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, &data.TransactionSendResult{TxHash: "hash", NonceCheck: nonceCheck}, result)
+}
+
+func TestNewTransactionProcessor_SendTransactionRejectedNonceShouldNotRelay(t *testing.T) {
+	t.Parallel()
+
+	errNonce := &process.NonceError{Check: &data.NonceCheck{Status: data.NonceStatusTooLow}}
+	tp, _ := process.NewTransactionProcessor(&mock.ProcessorStub{
+		ComputeShardIdCalled: func(addressBuff []byte) (u uint32, e error) {
+			return 0, nil
+		},
+		CallPostRestEndPointCalled: func(ctx context.Context, address string, path string, value interface{}, response interface{}) error {
+			assert.Fail(t, "transactions with rejected nonces should not be relayed")
+			return nil
+		},
+	}, createHexAddressCodec(), &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{
+		CheckNonceCalled: func(ctx context.Context, tx *data.Transaction) (*data.NonceCheck, error) {
+			return errNonce.Check, errNonce
+		},
+	})
+	result, err := tp.SendTransaction(context.Background(), &data.Transaction{Sender: "aa", Receiver: "bb"})
+
+	assert.Nil(t, result)
+	assert.Equal(t, errNonce, err)
+}
+
+//------- SendMultipleTransactions
+
+func TestTransactionProcessor_SendMultipleTransactionsEmptyListShouldErr(t *testing.T) {
+	t.Parallel()
+
+	tp, _ := process.NewTransactionProcessor(&mock.ProcessorStub{}, createHexAddressCodec(), &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{})
+	results, err := tp.SendMultipleTransactions(context.Background(), nil)
+
+	assert.Nil(t, results)
+	assert.Equal(t, process.ErrEmptyTransactionsList, err)
+}
+
+func TestTransactionProcessor_SendMultipleTransactionsShouldFanOutPerShardAndKeepOrder(t *testing.T) {
+	t.Parallel()
+
+	errExpected := errors.New("expected error")
+	mutSent := sync.Mutex{}
+	sentToAddress := make(map[string][]uint64)
+	tp, _ := process.NewTransactionProcessor(&mock.ProcessorStub{
+		ComputeShardIdCalled: func(addressBuff []byte) (u uint32, e error) {
+			return uint32(addressBuff[0]), nil
+		},
+		GetObserversCalled: func(shardId uint32) (observers []*data.Observer, e error) {
+			if shardId == 2 {
+				return nil, errExpected
+			}
+
+			return []*data.Observer{
+				{Address: fmt.Sprintf("address%d", shardId), ShardId: shardId},
+			}, nil
+		},
+		CallPostRestEndPointCalled: func(ctx context.Context, address string, path string, value interface{}, response interface{}) error {
+			tx := value.(*data.Transaction)
+			mutSent.Lock()
+			sentToAddress[address] = append(sentToAddress[address], tx.Nonce)
+			mutSent.Unlock()
+
+			response.(*data.ResponseTransaction).TxHash = fmt.Sprintf("hash%d", tx.Nonce)
+			return nil
+		},
+	}, createHexAddressCodec(), &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{})
+
+	txs := []*data.Transaction{
+		{Nonce: 0, Sender: "00"},
+		{Nonce: 1, Sender: "01"},
+		{Nonce: 2, Sender: "invalid hex"},
+		{Nonce: 3, Sender: "00"},
+		{Nonce: 4, Sender: "02"},
+		{Nonce: 5, Sender: "01"},
+	}
+	results, err := tp.SendMultipleTransactions(context.Background(), txs)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 6, len(results))
+	assert.Equal(t, &data.TransactionSendResult{TxHash: "hash0"}, results[0])
+	assert.Equal(t, &data.TransactionSendResult{TxHash: "hash1"}, results[1])
+	assert.Contains(t, results[2].Error, process.ErrInvalidAddress.Error())
+	assert.Equal(t, &data.TransactionSendResult{TxHash: "hash3"}, results[3])
+	assert.Equal(t, &data.TransactionSendResult{Error: errExpected.Error()}, results[4])
+	assert.Equal(t, &data.TransactionSendResult{TxHash: "hash5"}, results[5])
+	assert.Equal(t, []uint64{0, 3}, sentToAddress["address0"])
+	assert.Equal(t, []uint64{1, 5}, sentToAddress["address1"])
+}
+
+func TestTransactionProcessor_SendMultipleTransactionsInvalidTransactionShouldReportField(t *testing.T) {
+	t.Parallel()
+
+	tp, _ := process.NewTransactionProcessor(&mock.ProcessorStub{
+		ComputeShardIdCalled: func(addressBuff []byte) (u uint32, e error) {
+			return 0, nil
+		},
+		GetObserversCalled: func(shardId uint32) (observers []*data.Observer, e error) {
+			return []*data.Observer{{Address: "address0", ShardId: 0}}, nil
+		},
+		CallPostRestEndPointCalled: func(ctx context.Context, address string, path string, value interface{}, response interface{}) error {
+			response.(*data.ResponseTransaction).TxHash = "hash"
+			return nil
+		},
+	}, createHexAddressCodec(), &mock.TransactionValidatorStub{
+		ValidateTransactionCalled: func(tx *data.Transaction) error {
+			if tx.Nonce == 1 {
+				return &process.TransactionFieldError{Field: "gasPrice", Err: errors.New("missing value")}
+			}
+			return nil
+		},
+	}, &mock.NonceCheckerStub{})
+
+	results, err := tp.SendMultipleTransactions(context.Background(), []*data.Transaction{
+		{Nonce: 0, Sender: "00"},
+		{Nonce: 1, Sender: "00"},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, &data.TransactionSendResult{TxHash: "hash"}, results[0])
+	assert.Equal(t, "gasPrice", results[1].Field)
+	assert.Equal(t, "invalid transaction: gasPrice: missing value", results[1].Error)
+}
+
+func TestTransactionProcessor_SendMultipleTransactionsShouldReportNonceChecks(t *testing.T) {
+	t.Parallel()
+
+	numSent := 0
+	tp, _ := process.NewTransactionProcessor(&mock.ProcessorStub{
+		ComputeShardIdCalled: func(addressBuff []byte) (u uint32, e error) {
+			return 0, nil
+		},
+		GetObserversCalled: func(shardId uint32) (observers []*data.Observer, e error) {
+			return []*data.Observer{{Address: "address0", ShardId: 0}}, nil
+		},
+		CallPostRestEndPointCalled: func(ctx context.Context, address string, path string, value interface{}, response interface{}) error {
+			numSent++
+			response.(*data.ResponseTransaction).TxHash = "hash"
+			return nil
+		},
+	}, createHexAddressCodec(), &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{
+		CheckNonceCalled: func(ctx context.Context, tx *data.Transaction) (*data.NonceCheck, error) {
+			check := &data.NonceCheck{Status: data.NonceStatusOk, AccountNonce: 1, TransactionNonce: tx.Nonce}
+			if tx.Nonce == 0 {
+				check.Status = data.NonceStatusTooLow
+				return check, &process.NonceError{Check: check}
+			}
+			return check, nil
+		},
+	})
+
+	results, err := tp.SendMultipleTransactions(context.Background(), []*data.Transaction{
+		{Nonce: 0, Sender: "00"},
+		{Nonce: 1, Sender: "00"},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, numSent)
+	assert.Empty(t, results[0].TxHash)
+	assert.True(t, strings.HasPrefix(results[0].Error, process.ErrInvalidNonce.Error()))
+	assert.Equal(t, data.NonceStatusTooLow, results[0].NonceCheck.Status)
+	assert.Equal(t, "hash", results[1].TxHash)
+	assert.Equal(t, data.NonceStatusOk, results[1].NonceCheck.Status)
+}
+
+//------- SimulateTransaction
+
+func TestTransactionProcessor_SimulateTransactionShouldPostToTheSendersShard(t *testing.T) {
+	t.Parallel()
+
+	codec := createBech32AddressCodec()
+	var simulatedTx *data.Transaction
+	tp, _ := process.NewTransactionProcessor(&mock.ProcessorStub{
+		ComputeShardIdCalled: func(addressBuff []byte) (u uint32, e error) {
+			return uint32(addressBuff[0]), nil
+		},
+		GetObserversCalled: func(shardId uint32) (observers []*data.Observer, e error) {
+			assert.Equal(t, uint32(1), shardId)
+			return []*data.Observer{{Address: "address1", ShardId: shardId}}, nil
+		},
+		CallPostRestEndPointCalled: func(ctx context.Context, address string, path string, value interface{}, response interface{}) error {
+			assert.Equal(t, process.TransactionSimulatePath, path)
+			simulatedTx = value.(*data.Transaction)
+			simulation := &response.(*data.ResponseTransactionSimulation).Simulation
+			simulation.Status = "success"
+			simulation.GasUsed = 1500
+			simulation.ReturnData = []byte(`["aa"]`)
+			return nil
+		},
+	}, codec, &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{
+		CheckNonceCalled: func(ctx context.Context, tx *data.Transaction) (*data.NonceCheck, error) {
+			assert.Fail(t, "the simulated transactions should not have their nonce checked")
+			return nil, nil
+		},
+	})
+
+	simulation, err := tp.SimulateTransaction(context.Background(), &data.Transaction{
+		Nonce:    3,
+		Sender:   codec.EncodeAddress([]byte{0x01, 0x02}),
+		Receiver: "beef",
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, &data.TransactionSimulationResult{Status: "success", GasUsed: 1500, ReturnData: []byte(`["aa"]`)}, simulation)
+	assert.Equal(t, "0102", simulatedTx.Sender)
+	assert.Equal(t, uint64(3), simulatedTx.Nonce)
+}
+
+func TestTransactionProcessor_SimulateTransactionShouldHedgeAcrossObservers(t *testing.T) {
+	t.Parallel()
+
+	isSlowObserverCanceled := make(chan struct{})
+	tp, _ := process.NewTransactionProcessor(&mock.ProcessorStub{
+		ComputeShardIdCalled: func(addressBuff []byte) (u uint32, e error) {
+			return 0, nil
+		},
+		GetObserversCalled: func(shardId uint32) (observers []*data.Observer, e error) {
+			return []*data.Observer{{Address: "slow"}, {Address: "fast"}}, nil
+		},
+		GetHedgingDelayCalled: func(_ uint32) time.Duration {
+			return 10 * time.Millisecond
+		},
+		CallPostRestEndPointCalled: func(ctx context.Context, address string, path string, value interface{}, response interface{}) error {
+			if address == "slow" {
+				<-ctx.Done()
+				close(isSlowObserverCanceled)
+				return ctx.Err()
+			}
+
+			response.(*data.ResponseTransactionSimulation).Simulation.Status = "success"
+			return nil
+		},
+	}, createHexAddressCodec(), &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{})
+
+	simulation, err := tp.SimulateTransaction(context.Background(), &data.Transaction{Sender: "aa", Receiver: "bb"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "success", simulation.Status)
+	select {
+	case <-isSlowObserverCanceled:
+	case <-time.After(time.Second):
+		assert.Fail(t, "the slow observer's request should have been canceled")
+	}
+}
+
+func TestTransactionProcessor_SimulateTransactionWithNodeRoutesShouldErrNotSupported(t *testing.T) {
+	t.Parallel()
+
+	proc, closeServer := createNodeRoutesProcessor()
+	defer closeServer()
+	tp, _ := process.NewTransactionProcessor(proc, createHexAddressCodec(), &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{})
+
+	simulation, err := tp.SimulateTransaction(context.Background(), &data.Transaction{Sender: "0102", Receiver: "beef"})
+
+	assert.Nil(t, simulation)
+	assert.Equal(t, process.ErrSimulationNotSupported, err)
+}
+
+func TestTransactionProcessor_SimulateTransactionInvalidTransactionShouldErr(t *testing.T) {
+	t.Parallel()
+
+	errInvalid := &process.TransactionFieldError{Field: "gasLimit", Err: errors.New("missing value")}
+	tp, _ := process.NewTransactionProcessor(&mock.ProcessorStub{
+		CallPostRestEndPointCalled: func(ctx context.Context, address string, path string, value interface{}, response interface{}) error {
+			assert.Fail(t, "invalid transactions should not be simulated")
+			return nil
+		},
+	}, createHexAddressCodec(), &mock.TransactionValidatorStub{
+		ValidateTransactionCalled: func(tx *data.Transaction) error {
+			return errInvalid
+		},
+	}, &mock.NonceCheckerStub{})
+
+	simulation, err := tp.SimulateTransaction(context.Background(), &data.Transaction{Sender: "aa", Receiver: "bb"})
+
+	assert.Nil(t, simulation)
+	assert.Equal(t, errInvalid, err)
+}
+
+//------- GetTransaction
+
+func createShardedTxLookupStub(
+	txShardId uint32,
+	queriedShards *sync.Map,
+) *mock.ProcessorStub {
+	return &mock.ProcessorStub{
+		ComputeShardIdCalled: func(addressBuff []byte) (u uint32, e error) {
+			return uint32(addressBuff[0]), nil
+		},
+		GetShardIdsCalled: func() []uint32 {
+			return []uint32{0, 1, 2}
+		},
+		GetObserversCalled: func(shardId uint32) (observers []*data.Observer, e error) {
+			return []*data.Observer{
+				{Address: fmt.Sprintf("address%d", shardId), ShardId: shardId},
+			}, nil
+		},
+		CallGetRestEndPointCalled: func(ctx context.Context, address string, path string, value interface{}) error {
+			queriedShards.Store(address, path)
+			if address != fmt.Sprintf("address%d", txShardId) {
+				return &process.ObserverError{Address: address, StatusCode: http.StatusNotFound}
+			}
+
+			response := value.(*data.ResponseTransactionDetails)
+			response.Transaction.Hash = "aabb"
+			response.Transaction.ShardId = txShardId
+			return nil
+		},
+	}
+}
+
+func TestTransactionProcessor_GetTransactionLookupShouldNotRecordReadLatencies(t *testing.T) {
+	t.Parallel()
+
+	proc := createShardedTxLookupStub(2, &sync.Map{})
+	proc.RecordReadLatencyCalled = func(shardId uint32, latency time.Duration) {
+		assert.Fail(t, "the lookups in all the shards should not be measured")
+	}
+	tp, _ := process.NewTransactionProcessor(proc, createHexAddressCodec(), &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{})
+
+	_, err := tp.GetTransaction(process.NewRequestContext(context.Background(), "request"), "aabb", "", "")
+
+	assert.Nil(t, err)
+}
+
+func TestTransactionProcessor_GetTransactionInvalidHashShouldErr(t *testing.T) {
+	t.Parallel()
+
+	tp, _ := process.NewTransactionProcessor(&mock.ProcessorStub{}, createHexAddressCodec(), &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{})
+	tx, err := tp.GetTransaction(context.Background(), "not a hash", "", "")
+
+	assert.Nil(t, tx)
+	assert.Equal(t, process.ErrInvalidTransactionHash, err)
+}
+
+func TestTransactionProcessor_GetTransactionWithoutHintsShouldQueryAllShards(t *testing.T) {
+	t.Parallel()
+
+	queriedShards := &sync.Map{}
+	tp, _ := process.NewTransactionProcessor(createShardedTxLookupStub(2, queriedShards), createHexAddressCodec(), &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{})
+	tx, err := tp.GetTransaction(context.Background(), "aabb", "", "")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "aabb", tx.Hash)
+	assert.Equal(t, uint32(2), tx.ShardId)
+	path, _ := queriedShards.Load("address2")
+	assert.Equal(t, "/transaction/aabb", path)
+}
+
+func TestTransactionProcessor_GetTransactionWithSenderHintShouldQueryOnlySenderShard(t *testing.T) {
+	t.Parallel()
+
+	queriedShards := &sync.Map{}
+	tp, _ := process.NewTransactionProcessor(createShardedTxLookupStub(1, queriedShards), createHexAddressCodec(), &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{})
+	tx, err := tp.GetTransaction(context.Background(), "aabb", "01", "")
+
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(1), tx.ShardId)
+	numQueried := 0
+	queriedShards.Range(func(_, _ interface{}) bool {
+		numQueried++
+		return true
+	})
+	assert.Equal(t, 1, numQueried)
+}
+
+func TestTransactionProcessor_GetTransactionNotFoundInHintedShardsShouldErr(t *testing.T) {
+	t.Parallel()
+
+	tp, _ := process.NewTransactionProcessor(createShardedTxLookupStub(2, &sync.Map{}), createHexAddressCodec(), &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{})
+	tx, err := tp.GetTransaction(context.Background(), "aabb", "00", "01")
+
+	assert.Nil(t, tx)
+	assert.Equal(t, process.ErrTransactionNotFound, err)
+}
+
+func TestTransactionProcessor_GetTransactionStatusShouldWork(t *testing.T) {
+	t.Parallel()
+
+	queriedShards := &sync.Map{}
+	tp, _ := process.NewTransactionProcessor(createShardedTxLookupStub(1, queriedShards), createHexAddressCodec(), &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{})
+	status, err := tp.GetTransactionStatus(context.Background(), "aabb", "", "01")
+
+	assert.Nil(t, err)
+	assert.Equal(t, data.TxStatusExecuted, status)
+	path, _ := queriedShards.Load("address1")
+	assert.Equal(t, "/transaction/aabb", path)
+}
+
+func TestTransactionProcessor_GetTransactionStatusPendingShouldAgreeWithCrossShardStatus(t *testing.T) {
+	t.Parallel()
+
+	executedOnShards := &sync.Map{}
+	executedOnShards.Store("address0", data.ObserverTxStatusPending)
+	tp, _ := process.NewTransactionProcessor(createCrossShardStatusStub(executedOnShards), createHexAddressCodec(), &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{})
+
+	status, err := tp.GetTransactionStatus(context.Background(), "aabb", "00", "01")
+	assert.Nil(t, err)
+	assert.Equal(t, data.TxStatusPending, status)
+
+	crossShardStatus, _ := tp.GetCrossShardTransactionStatus(context.Background(), "aabb", "00", "01")
+	assert.Equal(t, data.TxStatusPending, crossShardStatus.Status)
+
+	executedOnShards.Store("address0", data.ObserverTxStatusFail)
+	status, _ = tp.GetTransactionStatus(context.Background(), "aabb", "00", "01")
+	assert.Equal(t, data.TxStatusFailed, status)
+}
+
+// createNodeRoutesProcessor returns a processor whose only observer serves the transaction routes the same
+// way the nodes do, knowing only the provided transactions
+func createNodeRoutesProcessor(knownTxHashes ...string) (*mock.ProcessorStub, func()) {
+	router := gin.New()
+	router.GET("/transaction/:txhash", func(c *gin.Context) {
+		for _, txHash := range knownTxHashes {
+			if c.Param("txhash") == txHash {
+				c.JSON(http.StatusOK, gin.H{"transaction": data.TransactionDetails{Hash: txHash}})
+				return
+			}
+		}
+
+		c.JSON(http.StatusNotFound, gin.H{"error": "transaction was not found"})
+	})
+	router.POST("/transaction/send", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"txHash": "aabb"})
+	})
+	server := httptest.NewServer(router)
+
+	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{}, metrics.NewRegistry())
+	stub := &mock.ProcessorStub{
+		ComputeShardIdCalled: func(addressBuff []byte) (u uint32, e error) {
+			return uint32(addressBuff[0]), nil
+		},
+		GetShardIdsCalled: func() []uint32 {
+			return []uint32{0}
+		},
+		GetObserversCalled: func(shardId uint32) (observers []*data.Observer, e error) {
+			return []*data.Observer{{Address: server.URL, ShardId: shardId}}, nil
+		},
+		CallGetRestEndPointCalled:  bp.CallGetRestEndPoint,
+		CallPostRestEndPointCalled: bp.CallPostRestEndPoint,
+	}
+
+	return stub, server.Close
+}
+
+func TestTransactionProcessor_GetTransactionStatusWithNodeRoutesShouldWork(t *testing.T) {
+	t.Parallel()
+
+	proc, closeServer := createNodeRoutesProcessor("aabb")
+	defer closeServer()
+	tp, _ := process.NewTransactionProcessor(proc, createHexAddressCodec(), &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{})
+
+	status, err := tp.GetTransactionStatus(context.Background(), "aabb", "", "")
+	assert.Nil(t, err)
+	assert.Equal(t, data.TxStatusExecuted, status)
+
+	status, err = tp.GetTransactionStatus(context.Background(), "ccdd", "", "")
+	assert.Empty(t, status)
+	assert.Equal(t, process.ErrTransactionNotFound, err)
+}
+
+func TestTransactionProcessor_GetTransactionStatusMissingRouteShouldErr(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(gin.New())
+	defer server.Close()
+	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{}, metrics.NewRegistry())
+	tp, _ := process.NewTransactionProcessor(&mock.ProcessorStub{
+		GetShardIdsCalled: func() []uint32 {
+			return []uint32{0}
+		},
+		GetObserversCalled: func(shardId uint32) (observers []*data.Observer, e error) {
+			return []*data.Observer{{Address: server.URL}}, nil
+		},
+		CallGetRestEndPointCalled: bp.CallGetRestEndPoint,
+	}, createHexAddressCodec(), &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{})
+
+	status, err := tp.GetTransactionStatus(context.Background(), "aabb", "", "")
+
+	assert.Empty(t, status)
+	assert.True(t, errors.Is(err, process.ErrNotSupportedByObservers))
+}
+
+//------- cross shard status
+
+// createCrossShardStatusStub returns a processor whose observers know the transaction once their address is
+// stored in executedOnShards, along with the status they report
+func createCrossShardStatusStub(executedOnShards *sync.Map) *mock.ProcessorStub {
+	return &mock.ProcessorStub{
+		ComputeShardIdCalled: func(addressBuff []byte) (u uint32, e error) {
+			return uint32(addressBuff[0]), nil
+		},
+		GetObserversCalled: func(shardId uint32) (observers []*data.Observer, e error) {
+			return []*data.Observer{
+				{Address: fmt.Sprintf("address%d", shardId), ShardId: shardId},
+			}, nil
+		},
+		CallGetRestEndPointCalled: func(ctx context.Context, address string, path string, value interface{}) error {
+			status, ok := executedOnShards.Load(address)
+			if !ok {
+				return &process.ObserverError{Address: address, StatusCode: http.StatusNotFound}
+			}
+
+			value.(*data.ResponseTransactionDetails).Transaction.Hash = "aabb"
+			value.(*data.ResponseTransactionDetails).Transaction.Status = status.(string)
+			return nil
+		},
+	}
+}
+
+func TestTransactionProcessor_GetCrossShardTransactionStatusMissingReceiverShouldErr(t *testing.T) {
+	t.Parallel()
+
+	tp, _ := process.NewTransactionProcessor(createCrossShardStatusStub(&sync.Map{}), createHexAddressCodec(), &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{})
+	status, err := tp.GetCrossShardTransactionStatus(context.Background(), "aabb", "00", "")
+
+	assert.Nil(t, status)
+	assert.Equal(t, process.ErrMissingSenderOrReceiver, err)
+}
+
+func TestTransactionProcessor_GetCrossShardTransactionStatusShouldCombineShardStatuses(t *testing.T) {
+	t.Parallel()
+
+	executedOnShards := &sync.Map{}
+	tp, _ := process.NewTransactionProcessor(createCrossShardStatusStub(executedOnShards), createHexAddressCodec(), &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{})
+
+	status, err := tp.GetCrossShardTransactionStatus(context.Background(), "aabb", "00", "01")
+	assert.Nil(t, err)
+	assert.Equal(t, data.TxStatusPending, status.Status)
+
+	executedOnShards.Store("address0", data.ObserverTxStatusSuccess)
+	status, _ = tp.GetCrossShardTransactionStatus(context.Background(), "aabb", "00", "01")
+	assert.Equal(t, data.TxStatusExecutedOnSource, status.Status)
+	assert.Equal(t, data.TxStatusExecuted, status.SourceStatus)
+	assert.Equal(t, data.TxStatusPending, status.DestinationStatus)
+
+	executedOnShards.Store("address1", data.ObserverTxStatusSuccess)
+	status, _ = tp.GetCrossShardTransactionStatus(context.Background(), "aabb", "00", "01")
+	assert.Equal(t, &data.CrossShardTransactionStatus{
+		Status:            data.TxStatusFinal,
+		SourceShard:       0,
+		SourceStatus:      data.TxStatusExecuted,
+		DestinationShard:  1,
+		DestinationStatus: data.TxStatusExecuted,
+	}, status)
+}
+
+func TestTransactionProcessor_GetCrossShardTransactionStatusFailedExecutionShouldBeFailed(t *testing.T) {
+	t.Parallel()
+
+	executedOnShards := &sync.Map{}
+	tp, _ := process.NewTransactionProcessor(createCrossShardStatusStub(executedOnShards), createHexAddressCodec(), &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{})
+
+	executedOnShards.Store("address0", data.ObserverTxStatusSuccess)
+	executedOnShards.Store("address1", data.ObserverTxStatusFail)
+	status, err := tp.GetCrossShardTransactionStatus(context.Background(), "aabb", "00", "01")
+	assert.Nil(t, err)
+	assert.Equal(t, &data.CrossShardTransactionStatus{
+		Status:            data.TxStatusFailed,
+		SourceShard:       0,
+		SourceStatus:      data.TxStatusExecuted,
+		DestinationShard:  1,
+		DestinationStatus: data.TxStatusFailed,
+	}, status)
+
+	executedOnShards.Store("address0", data.ObserverTxStatusInvalid)
+	executedOnShards.Delete("address1")
+	status, _ = tp.GetCrossShardTransactionStatus(context.Background(), "aabb", "00", "01")
+	assert.Equal(t, data.TxStatusFailed, status.Status)
+	assert.Equal(t, data.TxStatusPending, status.DestinationStatus)
+
+	// a transaction the observer reports as pending is not executed yet
+	executedOnShards.Store("address0", data.ObserverTxStatusPending)
+	status, _ = tp.GetCrossShardTransactionStatus(context.Background(), "aabb", "00", "01")
+	assert.Equal(t, data.TxStatusPending, status.Status)
+}
+
+func TestTransactionProcessor_WaitForTransactionFinalityShouldReturnWhenFailed(t *testing.T) {
+	t.Parallel()
+
+	executedOnShards := &sync.Map{}
+	executedOnShards.Store("address0", data.ObserverTxStatusSuccess)
+	executedOnShards.Store("address1", data.ObserverTxStatusFail)
+	tp, _ := process.NewTransactionProcessor(createCrossShardStatusStub(executedOnShards), createHexAddressCodec(), &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{})
+
+	start := time.Now()
+	status, err := tp.WaitForTransactionFinality(context.Background(), "aabb", "00", "01", 10*time.Second)
+
+	assert.Nil(t, err)
+	assert.Equal(t, data.TxStatusFailed, status.Status)
+	assert.True(t, time.Since(start) < time.Second)
+}
+
+func TestTransactionProcessor_WaitForTransactionFinalityShouldReturnWhenClosed(t *testing.T) {
+	t.Parallel()
+
+	executedOnShards := &sync.Map{}
+	executedOnShards.Store("address0", data.ObserverTxStatusSuccess)
+	tp, _ := process.NewTransactionProcessor(createCrossShardStatusStub(executedOnShards), createHexAddressCodec(), &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{})
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		tp.Close()
+	}()
+	start := time.Now()
+	status, err := tp.WaitForTransactionFinality(context.Background(), "aabb", "00", "01", 10*time.Second)
+
+	assert.Nil(t, err)
+	assert.Equal(t, data.TxStatusExecutedOnSource, status.Status)
+	assert.True(t, time.Since(start) < time.Second)
+}
+
+func TestTransactionProcessor_GetCrossShardTransactionStatusWithNodeRoutesShouldWork(t *testing.T) {
+	t.Parallel()
+
+	proc, closeServer := createNodeRoutesProcessor("aabb")
+	defer closeServer()
+	tp, _ := process.NewTransactionProcessor(proc, createHexAddressCodec(), &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{})
+
+	status, err := tp.GetCrossShardTransactionStatus(context.Background(), "aabb", "00", "01")
+	assert.Nil(t, err)
+	assert.Equal(t, data.TxStatusFinal, status.Status)
+
+	status, err = tp.GetCrossShardTransactionStatus(context.Background(), "ccdd", "00", "01")
+	assert.Nil(t, err)
+	assert.Equal(t, data.TxStatusPending, status.Status)
+}
+
+func TestTransactionProcessor_GetCrossShardTransactionStatusMissingRouteShouldErr(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(gin.New())
+	defer server.Close()
+	bp, _ := process.NewBaseProcessor(&mock.AddressConverterStub{}, metrics.NewRegistry())
+	tp, _ := process.NewTransactionProcessor(&mock.ProcessorStub{
+		ComputeShardIdCalled: func(addressBuff []byte) (u uint32, e error) {
+			return 0, nil
+		},
+		GetObserversCalled: func(shardId uint32) (observers []*data.Observer, e error) {
+			return []*data.Observer{{Address: server.URL}}, nil
+		},
+		CallGetRestEndPointCalled: bp.CallGetRestEndPoint,
+	}, createHexAddressCodec(), &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{})
+
+	status, err := tp.WaitForTransactionFinality(context.Background(), "aabb", "00", "01", time.Second)
+
+	assert.Nil(t, status)
+	assert.True(t, errors.Is(err, process.ErrNotSupportedByObservers))
+}
+
+func TestTransactionProcessor_GetCrossShardTransactionStatusSameShardShouldQueryOnce(t *testing.T) {
+	t.Parallel()
+
+	executedOnShards := &sync.Map{}
+	executedOnShards.Store("address1", data.ObserverTxStatusSuccess)
+	tp, _ := process.NewTransactionProcessor(createCrossShardStatusStub(executedOnShards), createHexAddressCodec(), &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{})
+
+	status, err := tp.GetCrossShardTransactionStatus(context.Background(), "aabb", "01", "01")
+
+	assert.Nil(t, err)
+	assert.Equal(t, data.TxStatusFinal, status.Status)
+	assert.Equal(t, data.TxStatusExecuted, status.DestinationStatus)
+}
+
+func TestTransactionProcessor_WaitForTransactionFinalityShouldReturnWhenFinal(t *testing.T) {
+	t.Parallel()
+
+	executedOnShards := &sync.Map{}
+	executedOnShards.Store("address0", data.ObserverTxStatusSuccess)
+	tp, _ := process.NewTransactionProcessor(createCrossShardStatusStub(executedOnShards), createHexAddressCodec(), &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{})
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		executedOnShards.Store("address1", data.ObserverTxStatusSuccess)
+	}()
+	status, err := tp.WaitForTransactionFinality(context.Background(), "aabb", "00", "01", 5*time.Second)
+
+	assert.Nil(t, err)
+	assert.Equal(t, data.TxStatusFinal, status.Status)
+}
+
+func TestTransactionProcessor_WaitForTransactionFinalityTimeoutShouldReturnLastStatus(t *testing.T) {
+	t.Parallel()
+
+	executedOnShards := &sync.Map{}
+	executedOnShards.Store("address0", data.ObserverTxStatusSuccess)
+	tp, _ := process.NewTransactionProcessor(createCrossShardStatusStub(executedOnShards), createHexAddressCodec(), &mock.TransactionValidatorStub{}, &mock.NonceCheckerStub{})
+
+	status, err := tp.WaitForTransactionFinality(context.Background(), "aabb", "00", "01", 100*time.Millisecond)
 
-	assert.Equal(t, resultedTxHash, txHash)
 	assert.Nil(t, err)
+	assert.Equal(t, data.TxStatusExecutedOnSource, status.Status)
 }
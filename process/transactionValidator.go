@@ -0,0 +1,192 @@
+package process
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/numbatx/numbat-proxy/config"
+	"github.com/numbatx/numbat-proxy/data"
+)
+
+// TransactionSignatureLength is the length, in bytes, of a transaction's signature
+const TransactionSignatureLength = ed25519.SignatureSize
+
+// TransactionFieldError signals that a field of a transaction did not pass the validation. It wraps both
+// ErrInvalidTransaction and the reason the field was rejected
+type TransactionFieldError struct {
+	Field string
+	Err   error
+}
+
+// Error returns the error message including the rejected field
+func (tfe *TransactionFieldError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", ErrInvalidTransaction.Error(), tfe.Field, tfe.Err.Error())
+}
+
+// Unwrap returns ErrInvalidTransaction and the reason the field was rejected
+func (tfe *TransactionFieldError) Unwrap() []error {
+	return []error{ErrInvalidTransaction, tfe.Err}
+}
+
+func newTransactionFieldError(field string, format string, args ...interface{}) *TransactionFieldError {
+	return &TransactionFieldError{
+		Field: field,
+		Err:   fmt.Errorf(format, args...),
+	}
+}
+
+// signedTransaction mirrors the transaction structure of the observers. Its JSON encoding, with no signature,
+// is the message the sender signs. The observers do not set the gas fields and the challenge when relaying
+// a transaction, so they are not covered by the signature either
+type signedTransaction struct {
+	Nonce     uint64
+	Value     *big.Int
+	RcvAddr   []byte
+	SndAddr   []byte
+	GasPrice  uint64
+	GasLimit  uint64
+	Data      []byte
+	Signature []byte
+	Challenge []byte
+}
+
+// TransactionValidator rejects, before they are relayed, the transactions the observers would not accept.
+// When disabled, all the transactions are accepted
+type TransactionValidator struct {
+	codec         AddressCodec
+	addressLength int
+	isEnabled     bool
+	cfg           config.TransactionValidationConfig
+}
+
+// NewTransactionValidator creates a new instance of TransactionValidator. The addresses must have
+// addressLength bytes, the length of the observers' addresses
+func NewTransactionValidator(
+	codec AddressCodec,
+	addressLength int,
+	cfg config.TransactionValidationConfig,
+) (*TransactionValidator, error) {
+
+	if codec == nil {
+		return nil, ErrNilAddressCodec
+	}
+	if addressLength <= 0 {
+		return nil, ErrInvalidTransactionValidationConfig
+	}
+
+	isGasPriceRangeValid := cfg.MaxGasPrice == 0 || cfg.MinGasPrice <= cfg.MaxGasPrice
+	isGasLimitRangeValid := cfg.MaxGasLimit == 0 || cfg.MinGasLimit <= cfg.MaxGasLimit
+	if !isGasPriceRangeValid || !isGasLimitRangeValid || cfg.MaxDataLength < 0 {
+		return nil, ErrInvalidTransactionValidationConfig
+	}
+
+	return &TransactionValidator{
+		codec:         codec,
+		addressLength: addressLength,
+		isEnabled:     cfg.Enabled,
+		cfg:           cfg,
+	}, nil
+}
+
+// ValidateTransaction returns a *TransactionFieldError for the first field of the transaction that the
+// observers would reject
+func (tv *TransactionValidator) ValidateTransaction(tx *data.Transaction) error {
+	if !tv.isEnabled {
+		return nil
+	}
+
+	sender, err := tv.decodeAddress("sender", tx.Sender)
+	if err != nil {
+		return err
+	}
+	receiver, err := tv.decodeAddress("receiver", tx.Receiver)
+	if err != nil {
+		return err
+	}
+
+	if tx.Value == nil {
+		return newTransactionFieldError("value", "missing value")
+	}
+	if tx.Value.Sign() < 0 {
+		return newTransactionFieldError("value", "negative value")
+	}
+
+	err = checkGasField("gasPrice", tx.GasPrice, tv.cfg.MinGasPrice, tv.cfg.MaxGasPrice)
+	if err != nil {
+		return err
+	}
+	err = checkGasField("gasLimit", tx.GasLimit, tv.cfg.MinGasLimit, tv.cfg.MaxGasLimit)
+	if err != nil {
+		return err
+	}
+
+	if tv.cfg.MaxDataLength > 0 && len(tx.Data) > tv.cfg.MaxDataLength {
+		return newTransactionFieldError("data", "%d bytes, more than the maximum of %d", len(tx.Data), tv.cfg.MaxDataLength)
+	}
+
+	signature, err := hex.DecodeString(tx.Signature)
+	if err != nil {
+		return &TransactionFieldError{Field: "signature", Err: err}
+	}
+	if len(signature) != TransactionSignatureLength {
+		return newTransactionFieldError("signature", "%d bytes, expected %d", len(signature), TransactionSignatureLength)
+	}
+
+	if tv.cfg.VerifySignature {
+		return verifySignature(tx, sender, receiver, signature)
+	}
+
+	return nil
+}
+
+func (tv *TransactionValidator) decodeAddress(field string, address string) ([]byte, error) {
+	addressBuff, err := tv.codec.DecodeAddress(address)
+	if err != nil {
+		return nil, &TransactionFieldError{Field: field, Err: err}
+	}
+	if len(addressBuff) != tv.addressLength {
+		return nil, newTransactionFieldError(field, "address of %d bytes, expected %d", len(addressBuff), tv.addressLength)
+	}
+
+	return addressBuff, nil
+}
+
+// checkGasField checks that the gas field is provided and, for the non zero bounds, that it is within them
+func checkGasField(field string, value *big.Int, minValue uint64, maxValue uint64) error {
+	switch {
+	case value == nil:
+		return newTransactionFieldError(field, "missing value")
+	case value.Sign() < 0:
+		return newTransactionFieldError(field, "negative value")
+	case value.Cmp(new(big.Int).SetUint64(minValue)) < 0:
+		return newTransactionFieldError(field, "%s, less than the minimum of %d", value.String(), minValue)
+	case maxValue > 0 && value.Cmp(new(big.Int).SetUint64(maxValue)) > 0:
+		return newTransactionFieldError(field, "%s, more than the maximum of %d", value.String(), maxValue)
+	default:
+		return nil
+	}
+}
+
+// verifySignature checks the signature the same way the observers do: the sender's address is its public key
+// and the signature is an Ed25519 compatible Schnorr signature of the JSON encoded transaction
+func verifySignature(tx *data.Transaction, sender []byte, receiver []byte, signature []byte) error {
+	message, err := json.Marshal(&signedTransaction{
+		Nonce:   tx.Nonce,
+		Value:   tx.Value,
+		RcvAddr: receiver,
+		SndAddr: sender,
+		Data:    []byte(tx.Data),
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(sender) != ed25519.PublicKeySize || !ed25519.Verify(sender, message, signature) {
+		return &TransactionFieldError{Field: "signature", Err: ErrSignatureMismatch}
+	}
+
+	return nil
+}
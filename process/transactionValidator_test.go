@@ -0,0 +1,157 @@
+package process_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/numbatx/numbat-proxy/config"
+	"github.com/numbatx/numbat-proxy/data"
+	"github.com/numbatx/numbat-proxy/process"
+	"github.com/stretchr/testify/assert"
+)
+
+const testAddressLength = 32
+
+func createTransactionValidationConfig() config.TransactionValidationConfig {
+	return config.TransactionValidationConfig{
+		Enabled:       true,
+		MinGasPrice:   10,
+		MaxGasPrice:   100,
+		MinGasLimit:   1000,
+		MaxGasLimit:   2000,
+		MaxDataLength: 8,
+	}
+}
+
+func createValidTransaction() *data.Transaction {
+	return &data.Transaction{
+		Nonce:     3,
+		Value:     big.NewInt(10),
+		Receiver:  strings.Repeat("bb", testAddressLength),
+		Sender:    strings.Repeat("aa", testAddressLength),
+		GasPrice:  big.NewInt(10),
+		GasLimit:  big.NewInt(1000),
+		Data:      "data",
+		Signature: strings.Repeat("cc", process.TransactionSignatureLength),
+	}
+}
+
+func TestNewTransactionValidator_InvalidArgumentsShouldErr(t *testing.T) {
+	t.Parallel()
+
+	tv, err := process.NewTransactionValidator(nil, testAddressLength, createTransactionValidationConfig())
+	assert.Nil(t, tv)
+	assert.Equal(t, process.ErrNilAddressCodec, err)
+
+	cfg := createTransactionValidationConfig()
+	cfg.MinGasLimit = cfg.MaxGasLimit + 1
+	tv, err = process.NewTransactionValidator(createHexAddressCodec(), testAddressLength, cfg)
+	assert.Nil(t, tv)
+	assert.Equal(t, process.ErrInvalidTransactionValidationConfig, err)
+}
+
+func TestTransactionValidator_DisabledShouldAcceptAnyTransaction(t *testing.T) {
+	t.Parallel()
+
+	tv, _ := process.NewTransactionValidator(createHexAddressCodec(), testAddressLength, config.TransactionValidationConfig{})
+
+	assert.Nil(t, tv.ValidateTransaction(&data.Transaction{}))
+}
+
+func TestTransactionValidator_ValidTransactionShouldWork(t *testing.T) {
+	t.Parallel()
+
+	tv, _ := process.NewTransactionValidator(createHexAddressCodec(), testAddressLength, createTransactionValidationConfig())
+
+	assert.Nil(t, tv.ValidateTransaction(createValidTransaction()))
+}
+
+func TestTransactionValidator_InvalidFieldsShouldErr(t *testing.T) {
+	t.Parallel()
+
+	tv, _ := process.NewTransactionValidator(createHexAddressCodec(), testAddressLength, createTransactionValidationConfig())
+	testCases := []struct {
+		field  string
+		modify func(tx *data.Transaction)
+	}{
+		{field: "sender", modify: func(tx *data.Transaction) { tx.Sender = "not an address" }},
+		{field: "sender", modify: func(tx *data.Transaction) { tx.Sender = "aabb" }},
+		{field: "receiver", modify: func(tx *data.Transaction) { tx.Receiver = "" }},
+		{field: "value", modify: func(tx *data.Transaction) { tx.Value = nil }},
+		{field: "value", modify: func(tx *data.Transaction) { tx.Value = big.NewInt(-1) }},
+		{field: "gasPrice", modify: func(tx *data.Transaction) { tx.GasPrice = nil }},
+		{field: "gasPrice", modify: func(tx *data.Transaction) { tx.GasPrice = big.NewInt(9) }},
+		{field: "gasPrice", modify: func(tx *data.Transaction) { tx.GasPrice = big.NewInt(101) }},
+		{field: "gasLimit", modify: func(tx *data.Transaction) { tx.GasLimit = big.NewInt(-1000) }},
+		{field: "gasLimit", modify: func(tx *data.Transaction) { tx.GasLimit = big.NewInt(2001) }},
+		{field: "data", modify: func(tx *data.Transaction) { tx.Data = "too much data" }},
+		{field: "signature", modify: func(tx *data.Transaction) { tx.Signature = "not hex" }},
+		{field: "signature", modify: func(tx *data.Transaction) { tx.Signature = "aabbccdd" }},
+	}
+
+	for _, tc := range testCases {
+		tx := createValidTransaction()
+		tc.modify(tx)
+
+		err := tv.ValidateTransaction(tx)
+
+		var fieldErr *process.TransactionFieldError
+		assert.True(t, errors.As(err, &fieldErr), tc.field)
+		assert.Equal(t, tc.field, fieldErr.Field)
+		assert.True(t, errors.Is(err, process.ErrInvalidTransaction))
+	}
+}
+
+func TestTransactionValidator_WrongAddressChecksumShouldErr(t *testing.T) {
+	t.Parallel()
+
+	codec := createBech32AddressCodec()
+	tv, _ := process.NewTransactionValidator(codec, testAddressLength, createTransactionValidationConfig())
+	tx := createValidTransaction()
+	senderBuff, _ := hex.DecodeString(tx.Sender)
+	tx.Sender = codec.EncodeAddress(senderBuff)
+	assert.Nil(t, tv.ValidateTransaction(tx))
+
+	tx.Sender = tx.Sender[:len(tx.Sender)-1] + "x"
+	err := tv.ValidateTransaction(tx)
+	assert.True(t, errors.Is(err, process.ErrInvalidTransaction))
+	assert.True(t, errors.Is(err, process.ErrInvalidAddressChecksum))
+}
+
+func TestTransactionValidator_VerifySignatureShouldCheckTheSignedMessage(t *testing.T) {
+	t.Parallel()
+
+	publicKey, privateKey, _ := ed25519.GenerateKey(rand.Reader)
+	receiver := []byte(strings.Repeat("b", testAddressLength))
+	tx := createValidTransaction()
+	tx.Sender = hex.EncodeToString(publicKey)
+	tx.Receiver = hex.EncodeToString(receiver)
+
+	// the observers sign the JSON encoding of their own transaction structure, with no gas and signature
+	message := fmt.Sprintf(
+		`{"Nonce":3,"Value":10,"RcvAddr":"%s","SndAddr":"%s","GasPrice":0,"GasLimit":0,"Data":"%s","Signature":null,"Challenge":null}`,
+		base64.StdEncoding.EncodeToString(receiver),
+		base64.StdEncoding.EncodeToString(publicKey),
+		base64.StdEncoding.EncodeToString([]byte(tx.Data)),
+	)
+	tx.Signature = hex.EncodeToString(ed25519.Sign(privateKey, []byte(message)))
+
+	cfg := createTransactionValidationConfig()
+	cfg.VerifySignature = true
+	tv, _ := process.NewTransactionValidator(createHexAddressCodec(), testAddressLength, cfg)
+	assert.Nil(t, tv.ValidateTransaction(tx))
+
+	tx.Nonce++
+	err := tv.ValidateTransaction(tx)
+	var fieldErr *process.TransactionFieldError
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "signature", fieldErr.Field)
+	assert.True(t, errors.Is(err, process.ErrSignatureMismatch))
+}
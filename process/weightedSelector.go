@@ -0,0 +1,61 @@
+package process
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/numbatx/numbat-proxy/data"
+)
+
+// WeightedSelector returns the observers in a random order in which an observer's chance of being
+// tried first is proportional to its configured weight. A weight of 0 is treated as 1
+type WeightedSelector struct {
+	mutRand sync.Mutex
+	rnd     *rand.Rand
+}
+
+// NewWeightedSelector creates a new instance of WeightedSelector
+func NewWeightedSelector() *WeightedSelector {
+	return &WeightedSelector{
+		rnd: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Select returns a weighted shuffle of the observers. Each observer gets the key u^(1/weight), where u
+// is uniformly distributed in (0, 1), and the observers are sorted descending by key
+func (ws *WeightedSelector) Select(_ uint32, observers []*data.Observer) []*data.Observer {
+	keys := make(map[*data.Observer]float64, len(observers))
+
+	ws.mutRand.Lock()
+	for _, observer := range observers {
+		weight := float64(observer.Weight)
+		if weight == 0 {
+			weight = 1
+		}
+		keys[observer] = math.Pow(ws.rnd.Float64(), 1/weight)
+	}
+	ws.mutRand.Unlock()
+
+	selected := make([]*data.Observer, len(observers))
+	copy(selected, observers)
+	sort.SliceStable(selected, func(i, j int) bool {
+		return keys[selected[i]] > keys[selected[j]]
+	})
+
+	return selected
+}
+
+// CallStarted does nothing
+func (ws *WeightedSelector) CallStarted(_ string) {
+}
+
+// CallFinished does nothing
+func (ws *WeightedSelector) CallFinished(_ string, _ time.Duration, _ error) {
+}
+
+// CallCanceled does nothing
+func (ws *WeightedSelector) CallCanceled(_ string) {
+}
@@ -43,6 +43,11 @@ func (ths *TestHttpServer) processRequest(rw http.ResponseWriter, req *http.Requ
 		return
 	}
 
+	if strings.Contains(req.URL.Path, "node") {
+		ths.processRequestNode(rw, req)
+		return
+	}
+
 	fmt.Printf("Can not serve request: %v\n", req.URL)
 }
 
@@ -65,6 +70,15 @@ func (ths *TestHttpServer) processRequestAddress(rw http.ResponseWriter, req *ht
 }
 
 func (ths *TestHttpServer) processRequestTransaction(rw http.ResponseWriter, req *http.Request) {
+	if req.Method == http.MethodGet {
+		ths.processRequestGetTransaction(rw, req)
+		return
+	}
+	if strings.HasSuffix(req.URL.Path, "/simulate") {
+		ths.processRequestSimulateTransaction(rw)
+		return
+	}
+
 	buf := new(bytes.Buffer)
 	_, _ = buf.ReadFrom(req.Body)
 	newStr := buf.String()
@@ -82,6 +96,40 @@ func (ths *TestHttpServer) processRequestTransaction(rw http.ResponseWriter, req
 	log.LogIfError(err)
 }
 
+func (ths *TestHttpServer) processRequestSimulateTransaction(rw http.ResponseWriter) {
+	response := data.ResponseTransactionSimulation{
+		Simulation: data.TransactionSimulationResult{
+			Status:  "success",
+			GasUsed: 1000,
+		},
+	}
+
+	responseBuff, _ := json.Marshal(response)
+	_, err := rw.Write(responseBuff)
+	log.LogIfError(err)
+}
+
+func (ths *TestHttpServer) processRequestGetTransaction(rw http.ResponseWriter, req *http.Request) {
+	_, txHash := path.Split(req.URL.Path)
+	response := data.ResponseTransactionDetails{
+		Transaction: data.TransactionDetails{
+			Hash:        txHash,
+			BlockNumber: 45,
+		},
+	}
+
+	responseBuff, _ := json.Marshal(response)
+	_, err := rw.Write(responseBuff)
+	log.LogIfError(err)
+}
+
+func (ths *TestHttpServer) processRequestNode(rw http.ResponseWriter, _ *http.Request) {
+	responseBuff, _ := json.Marshal(map[string]interface{}{"running": true})
+
+	_, err := rw.Write(responseBuff)
+	log.LogIfError(err)
+}
+
 // Close closes the test http server
 func (ths *TestHttpServer) Close() {
 	ths.httpServer.Close()